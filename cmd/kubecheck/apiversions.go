@@ -0,0 +1,230 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// defaultKubeVersion is the target Kubernetes version when --kube-version is not set
+const defaultKubeVersion = "1.32"
+
+// KubeVersion is a Kubernetes minor release, e.g. 1.29
+type KubeVersion struct {
+	Major int
+	Minor int
+}
+
+// ParseKubeVersion parses versions such as "1.29", "v1.29", or "1.29.3"
+func ParseKubeVersion(version string) (KubeVersion, error) {
+	parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return KubeVersion{}, fmt.Errorf("invalid Kubernetes version %q: expected MAJOR.MINOR", version)
+	}
+
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return KubeVersion{}, fmt.Errorf("invalid Kubernetes version %q: %w", version, err)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return KubeVersion{}, fmt.Errorf("invalid Kubernetes version %q: %w", version, err)
+	}
+
+	return KubeVersion{Major: major, Minor: minor}, nil
+}
+
+// AtLeast reports whether v is the same release as other or newer
+func (v KubeVersion) AtLeast(other KubeVersion) bool {
+	if v.Major != other.Major {
+		return v.Major > other.Major
+	}
+	return v.Minor >= other.Minor
+}
+
+func (v KubeVersion) String() string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// apiDeprecation describes when an apiVersion stopped being served for a kind
+type apiDeprecation struct {
+	APIVersion   string
+	Kinds        []string // empty means every kind in the group version
+	DeprecatedIn KubeVersion
+	RemovedIn    KubeVersion
+	Replacement  string // replacement apiVersion, or a short remedy when there is none
+}
+
+// ingressV1Replacement points at the backend fields networking.k8s.io/v1 renamed,
+// which a plain apiVersion bump leaves invalid
+const ingressV1Replacement = "networking.k8s.io/v1, where backend.serviceName and backend.servicePort become backend.service.name and backend.service.port.number (or .name)"
+
+// apiDeprecations follows the Kubernetes deprecated API migration guide
+var apiDeprecations = []apiDeprecation{
+	{APIVersion: "extensions/v1beta1", Kinds: []string{"Deployment", "DaemonSet", "ReplicaSet"}, DeprecatedIn: KubeVersion{1, 8}, RemovedIn: KubeVersion{1, 16}, Replacement: "apps/v1"},
+	{APIVersion: "extensions/v1beta1", Kinds: []string{"NetworkPolicy"}, DeprecatedIn: KubeVersion{1, 9}, RemovedIn: KubeVersion{1, 16}, Replacement: "networking.k8s.io/v1"},
+	{APIVersion: "extensions/v1beta1", Kinds: []string{"PodSecurityPolicy"}, DeprecatedIn: KubeVersion{1, 10}, RemovedIn: KubeVersion{1, 16}, Replacement: "policy/v1beta1"},
+	{APIVersion: "extensions/v1beta1", Kinds: []string{"Ingress"}, DeprecatedIn: KubeVersion{1, 14}, RemovedIn: KubeVersion{1, 22}, Replacement: ingressV1Replacement},
+	{APIVersion: "apps/v1beta1", DeprecatedIn: KubeVersion{1, 9}, RemovedIn: KubeVersion{1, 16}, Replacement: "apps/v1"},
+	{APIVersion: "apps/v1beta2", DeprecatedIn: KubeVersion{1, 9}, RemovedIn: KubeVersion{1, 16}, Replacement: "apps/v1"},
+	{APIVersion: "networking.k8s.io/v1beta1", Kinds: []string{"Ingress"}, DeprecatedIn: KubeVersion{1, 19}, RemovedIn: KubeVersion{1, 22}, Replacement: ingressV1Replacement},
+	{APIVersion: "networking.k8s.io/v1beta1", Kinds: []string{"IngressClass"}, DeprecatedIn: KubeVersion{1, 19}, RemovedIn: KubeVersion{1, 22}, Replacement: "networking.k8s.io/v1"},
+	{APIVersion: "rbac.authorization.k8s.io/v1beta1", DeprecatedIn: KubeVersion{1, 17}, RemovedIn: KubeVersion{1, 22}, Replacement: "rbac.authorization.k8s.io/v1"},
+	{APIVersion: "apiextensions.k8s.io/v1beta1", DeprecatedIn: KubeVersion{1, 16}, RemovedIn: KubeVersion{1, 22}, Replacement: "apiextensions.k8s.io/v1"},
+	{APIVersion: "apiregistration.k8s.io/v1beta1", DeprecatedIn: KubeVersion{1, 19}, RemovedIn: KubeVersion{1, 22}, Replacement: "apiregistration.k8s.io/v1"},
+	{APIVersion: "admissionregistration.k8s.io/v1beta1", DeprecatedIn: KubeVersion{1, 16}, RemovedIn: KubeVersion{1, 22}, Replacement: "admissionregistration.k8s.io/v1"},
+	{APIVersion: "scheduling.k8s.io/v1beta1", DeprecatedIn: KubeVersion{1, 14}, RemovedIn: KubeVersion{1, 22}, Replacement: "scheduling.k8s.io/v1"},
+	{APIVersion: "coordination.k8s.io/v1beta1", DeprecatedIn: KubeVersion{1, 14}, RemovedIn: KubeVersion{1, 22}, Replacement: "coordination.k8s.io/v1"},
+	{APIVersion: "certificates.k8s.io/v1beta1", DeprecatedIn: KubeVersion{1, 19}, RemovedIn: KubeVersion{1, 22}, Replacement: "certificates.k8s.io/v1"},
+	{APIVersion: "storage.k8s.io/v1beta1", Kinds: []string{"CSIDriver", "CSINode", "StorageClass", "VolumeAttachment"}, DeprecatedIn: KubeVersion{1, 19}, RemovedIn: KubeVersion{1, 22}, Replacement: "storage.k8s.io/v1"},
+	{APIVersion: "storage.k8s.io/v1beta1", Kinds: []string{"CSIStorageCapacity"}, DeprecatedIn: KubeVersion{1, 24}, RemovedIn: KubeVersion{1, 27}, Replacement: "storage.k8s.io/v1"},
+	{APIVersion: "batch/v1beta1", Kinds: []string{"CronJob"}, DeprecatedIn: KubeVersion{1, 21}, RemovedIn: KubeVersion{1, 25}, Replacement: "batch/v1"},
+	{APIVersion: "discovery.k8s.io/v1beta1", DeprecatedIn: KubeVersion{1, 21}, RemovedIn: KubeVersion{1, 25}, Replacement: "discovery.k8s.io/v1"},
+	{APIVersion: "events.k8s.io/v1beta1", DeprecatedIn: KubeVersion{1, 19}, RemovedIn: KubeVersion{1, 25}, Replacement: "events.k8s.io/v1"},
+	{APIVersion: "node.k8s.io/v1beta1", DeprecatedIn: KubeVersion{1, 20}, RemovedIn: KubeVersion{1, 25}, Replacement: "node.k8s.io/v1"},
+	{APIVersion: "policy/v1beta1", Kinds: []string{"PodDisruptionBudget"}, DeprecatedIn: KubeVersion{1, 21}, RemovedIn: KubeVersion{1, 25}, Replacement: "policy/v1"},
+	{APIVersion: "policy/v1beta1", Kinds: []string{"PodSecurityPolicy"}, DeprecatedIn: KubeVersion{1, 21}, RemovedIn: KubeVersion{1, 25}, Replacement: "Pod Security Admission (no replacement API)"},
+	{APIVersion: "autoscaling/v2beta1", DeprecatedIn: KubeVersion{1, 22}, RemovedIn: KubeVersion{1, 25}, Replacement: "autoscaling/v2"},
+	{APIVersion: "autoscaling/v2beta2", DeprecatedIn: KubeVersion{1, 23}, RemovedIn: KubeVersion{1, 26}, Replacement: "autoscaling/v2"},
+	{APIVersion: "flowcontrol.apiserver.k8s.io/v1beta1", DeprecatedIn: KubeVersion{1, 23}, RemovedIn: KubeVersion{1, 26}, Replacement: "flowcontrol.apiserver.k8s.io/v1"},
+	{APIVersion: "flowcontrol.apiserver.k8s.io/v1beta2", DeprecatedIn: KubeVersion{1, 26}, RemovedIn: KubeVersion{1, 29}, Replacement: "flowcontrol.apiserver.k8s.io/v1"},
+	{APIVersion: "flowcontrol.apiserver.k8s.io/v1beta3", DeprecatedIn: KubeVersion{1, 29}, RemovedIn: KubeVersion{1, 32}, Replacement: "flowcontrol.apiserver.k8s.io/v1"},
+}
+
+// findAPIDeprecation returns the deprecation entry for a resource's apiVersion and kind, if any
+func findAPIDeprecation(apiVersion, kind string) (apiDeprecation, bool) {
+	for _, d := range apiDeprecations {
+		if d.APIVersion != apiVersion {
+			continue
+		}
+		if len(d.Kinds) == 0 || containsString(d.Kinds, kind) {
+			return d, true
+		}
+	}
+	return apiDeprecation{}, false
+}
+
+// apiVersionRemoved flags resources whose apiVersion is no longer served by the target version
+func apiVersionRemoved(resource K8sResource, target KubeVersion) (bool, string) {
+	d, ok := findAPIDeprecation(resource.APIVersion, resource.Kind)
+	if !ok || !target.AtLeast(d.RemovedIn) {
+		return false, ""
+	}
+	return true, fmt.Sprintf("%s, removed in v%s; use %s", d.APIVersion, d.RemovedIn, d.Replacement)
+}
+
+// apiVersionDeprecated flags resources whose apiVersion is deprecated but still served by the target version
+func apiVersionDeprecated(resource K8sResource, target KubeVersion) (bool, string) {
+	d, ok := findAPIDeprecation(resource.APIVersion, resource.Kind)
+	if !ok || !target.AtLeast(d.DeprecatedIn) || target.AtLeast(d.RemovedIn) {
+		return false, ""
+	}
+	return true, fmt.Sprintf("%s, deprecated in v%s and removed in v%s; use %s", d.APIVersion, d.DeprecatedIn, d.RemovedIn, d.Replacement)
+}
+
+// securityAnnotation is a pod annotation that a securityContext field replaced
+type securityAnnotation struct {
+	Key          string // the annotation key, or its prefix before a container name
+	PerContainer bool
+	Field        string // the securityContext field that replaces it
+	DeprecatedIn KubeVersion
+	RemovedIn    KubeVersion // zero while the kubelet still honors the annotation
+}
+
+// securityAnnotations lists the seccomp and AppArmor annotations in the order they are checked
+var securityAnnotations = []securityAnnotation{
+	{Key: "seccomp.security.alpha.kubernetes.io/pod", Field: "seccompProfile", DeprecatedIn: KubeVersion{1, 19}, RemovedIn: KubeVersion{1, 27}},
+	{Key: "container.seccomp.security.alpha.kubernetes.io/", PerContainer: true, Field: "seccompProfile", DeprecatedIn: KubeVersion{1, 19}, RemovedIn: KubeVersion{1, 27}},
+	{Key: "container.apparmor.security.beta.kubernetes.io/", PerContainer: true, Field: "appArmorProfile", DeprecatedIn: KubeVersion{1, 30}},
+}
+
+// findSecurityAnnotation returns the entry for an annotation key, and the
+// container it applies to for per-container annotations
+func findSecurityAnnotation(key string) (securityAnnotation, string, bool) {
+	for _, a := range securityAnnotations {
+		if !a.PerContainer && key == a.Key {
+			return a, "", true
+		}
+		if container, ok := strings.CutPrefix(key, a.Key); a.PerContainer && ok && container != "" {
+			return a, container, true
+		}
+	}
+	return securityAnnotation{}, "", false
+}
+
+// securityProfileReplacement spells out the profile fields that replace an
+// annotation value: runtime/default, unconfined, or localhost/<profile>
+func securityProfileReplacement(value string) string {
+	switch {
+	case value == "runtime/default" || value == "docker/default":
+		return "type: RuntimeDefault"
+	case value == "unconfined":
+		return "type: Unconfined"
+	case strings.HasPrefix(value, "localhost/"):
+		return fmt.Sprintf("type: Localhost, localhostProfile: %s", strings.TrimPrefix(value, "localhost/"))
+	default:
+		return fmt.Sprintf("a type matching %q", value)
+	}
+}
+
+// securityAnnotationsIn flags the seccomp and AppArmor annotations of a pod or
+// pod template that the target version has removed (removed true) or only
+// deprecated (removed false). Deprecated annotations are only reported once
+// their replacement field exists
+// The details give each annotation, its status, and the field to set instead
+func securityAnnotationsIn(resource K8sResource, target KubeVersion, removed bool, specPaths map[string]string) (bool, string) {
+	path, ok := podSpecPath(resource, specPaths)
+	if !ok {
+		return false, ""
+	}
+	metadata := resource.Metadata
+	if path != "spec" {
+		metadata = lookupSpecPath(resource, strings.TrimSuffix(path, ".spec")+".metadata")
+	}
+	annotations := getStringMap(metadata, "annotations")
+	podSpecMap := lookupSpecPath(resource, path)
+
+	keys := make([]string, 0, len(annotations))
+	for key := range annotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var findings []string
+	for _, key := range keys {
+		a, container, ok := findSecurityAnnotation(key)
+		if !ok {
+			continue
+		}
+		isRemoved := a.RemovedIn != (KubeVersion{}) && target.AtLeast(a.RemovedIn)
+		if removed != isRemoved || (!removed && !target.AtLeast(a.DeprecatedIn)) {
+			continue
+		}
+
+		field := path + ".securityContext." + a.Field
+		if a.PerContainer {
+			field = fmt.Sprintf("%s.%s.securityContext.%s", path, containerFieldPath(podSpecMap, container), a.Field)
+		}
+		status := fmt.Sprintf("deprecated in v%s", a.DeprecatedIn)
+		if isRemoved {
+			status = fmt.Sprintf("ignored since v%s", a.RemovedIn)
+		}
+		findings = append(findings, fmt.Sprintf("%s: %s (%s); set %s to {%s}", key, annotations[key], status, field, securityProfileReplacement(annotations[key])))
+	}
+	return len(findings) > 0, strings.Join(findings, "; ")
+}
+
+// containerFieldPath returns the path of a named container within a pod spec,
+// e.g. containers[1] or initContainers[0]
+func containerFieldPath(podSpecMap map[string]interface{}, name string) string {
+	for _, list := range []string{"containers", "initContainers", "ephemeralContainers"} {
+		containers, _ := podSpecMap[list].([]interface{})
+		for i, c := range containers {
+			if containerMap, ok := c.(map[string]interface{}); ok && getStringValue(containerMap, "name") == name {
+				return fmt.Sprintf("%s[%d]", list, i)
+			}
+		}
+	}
+	return fmt.Sprintf("containers[name=%s]", name)
+}
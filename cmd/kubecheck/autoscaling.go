@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// HPAMetric represents an entry of an autoscaling/v2 metrics list
+type HPAMetric struct {
+	Type string // Resource, ContainerResource, Pods, Object, or External
+	// Name is the resource name (cpu, memory) for Resource and ContainerResource
+	// metrics, or the metric name for the others
+	Name string
+	// TargetType is Utilization, AverageValue, or Value
+	TargetType string
+	// AverageUtilization is the target percentage, nil unless TargetType is Utilization
+	AverageUtilization *int
+}
+
+// HPASpec represents the fields of a HorizontalPodAutoscaler spec
+type HPASpec struct {
+	MinReplicas int // 1 when unset
+	MaxReplicas int
+	Metrics     []HPAMetric
+	// ScaleTargetRef names the workload the HPA scales
+	ScaleTargetRef ObjectReference
+}
+
+// ObjectReference names an object in the same namespace
+type ObjectReference struct {
+	APIVersion string
+	Kind       string
+	Name       string
+}
+
+// parseHPASpec parses a HorizontalPodAutoscaler, returning nil for other kinds
+// autoscaling/v1's targetCPUUtilizationPercentage is read as a cpu Resource metric
+func parseHPASpec(resource K8sResource) *HPASpec {
+	if resource.Kind != "HorizontalPodAutoscaler" {
+		return nil
+	}
+
+	hpa := &HPASpec{MinReplicas: 1}
+	if minReplicas, ok := getIntValue(resource.Spec, "minReplicas"); ok {
+		hpa.MinReplicas = minReplicas
+	}
+	hpa.MaxReplicas, _ = getIntValue(resource.Spec, "maxReplicas")
+
+	if ref, ok := resource.Spec["scaleTargetRef"].(map[string]interface{}); ok {
+		hpa.ScaleTargetRef = ObjectReference{
+			APIVersion: getStringValue(ref, "apiVersion"),
+			Kind:       getStringValue(ref, "kind"),
+			Name:       getStringValue(ref, "name"),
+		}
+	}
+
+	if metrics, ok := resource.Spec["metrics"].([]interface{}); ok {
+		for _, m := range metrics {
+			if metricMap, ok := m.(map[string]interface{}); ok {
+				hpa.Metrics = append(hpa.Metrics, parseHPAMetric(metricMap))
+			}
+		}
+	}
+	if target, ok := getIntValue(resource.Spec, "targetCPUUtilizationPercentage"); ok {
+		hpa.Metrics = append(hpa.Metrics, HPAMetric{Type: "Resource", Name: "cpu", TargetType: "Utilization", AverageUtilization: &target})
+	}
+
+	return hpa
+}
+
+// hpaMetricSourceKeys maps a metric type to the field holding its source
+var hpaMetricSourceKeys = map[string]string{
+	"Resource":          "resource",
+	"ContainerResource": "containerResource",
+	"Pods":              "pods",
+	"Object":            "object",
+	"External":          "external",
+}
+
+// parseHPAMetric parses one member of the metrics union type
+// The source field depends on the type, and the metric name sits either directly
+// on the source (resources) or under source.metric (custom metrics)
+func parseHPAMetric(metricMap map[string]interface{}) HPAMetric {
+	metric := HPAMetric{Type: getStringValue(metricMap, "type")}
+
+	source, ok := metricMap[hpaMetricSourceKeys[metric.Type]].(map[string]interface{})
+	if !ok {
+		return metric
+	}
+
+	metric.Name = getStringValue(source, "name")
+	if identifier, ok := source["metric"].(map[string]interface{}); ok {
+		metric.Name = getStringValue(identifier, "name")
+	}
+
+	if target, ok := source["target"].(map[string]interface{}); ok {
+		metric.TargetType = getStringValue(target, "type")
+		if utilization, ok := getIntValue(target, "averageUtilization"); ok {
+			metric.AverageUtilization = &utilization
+		}
+	} else if utilization, ok := getIntValue(source, "targetAverageUtilization"); ok {
+		// autoscaling/v2beta1 kept the target inline
+		metric.TargetType = "Utilization"
+		metric.AverageUtilization = &utilization
+	}
+
+	return metric
+}
+
+// hpaReplicaRangeInvalid flags HPAs that can never scale because minReplicas is not below maxReplicas
+func hpaReplicaRangeInvalid(resource K8sResource) (bool, string) {
+	hpa := parseHPASpec(resource)
+	if hpa == nil || hpa.MinReplicas < hpa.MaxReplicas {
+		return false, ""
+	}
+	return true, fmt.Sprintf("minReplicas %d, maxReplicas %d", hpa.MinReplicas, hpa.MaxReplicas)
+}
+
+// hpaMinReplicasOne flags HPAs that may scale down to a single pod in one of the
+// given namespaces, or in any namespace when none are given
+func hpaMinReplicasOne(resource K8sResource, namespaces []string) (bool, string) {
+	hpa := parseHPASpec(resource)
+	if hpa == nil || hpa.MinReplicas != 1 {
+		return false, ""
+	}
+	namespace := getResourceNamespace(resource)
+	if len(namespaces) > 0 && !containsString(namespaces, namespace) {
+		return false, ""
+	}
+	return true, fmt.Sprintf("minReplicas 1 in namespace '%s'", namespace)
+}
+
+// hpaCPUTargetOutOfRange flags cpu utilization targets outside [minimum, maximum] percent
+func hpaCPUTargetOutOfRange(resource K8sResource, minimum, maximum int) (bool, string) {
+	hpa := parseHPASpec(resource)
+	if hpa == nil {
+		return false, ""
+	}
+
+	for _, metric := range hpa.Metrics {
+		if metric.Type != "Resource" && metric.Type != "ContainerResource" {
+			continue
+		}
+		if metric.Name != "cpu" || metric.AverageUtilization == nil {
+			continue
+		}
+		if target := *metric.AverageUtilization; target < minimum || target > maximum {
+			return true, fmt.Sprintf("averageUtilization %d%% (outside %d-%d%%)", target, minimum, maximum)
+		}
+	}
+	return false, ""
+}
+
+// parsePercentRange parses a range such as "10-100"
+func parsePercentRange(value string) (int, int, error) {
+	low, high, ok := strings.Cut(value, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid range %q", value)
+	}
+	minimum, err := strconv.Atoi(strings.TrimSpace(low))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid range %q", value)
+	}
+	maximum, err := strconv.Atoi(strings.TrimSpace(high))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid range %q", value)
+	}
+	return minimum, maximum, nil
+}
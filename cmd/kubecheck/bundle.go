@@ -0,0 +1,496 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Bundle holds every resource from a scan so rules can look across resources
+type Bundle struct {
+	Resources []K8sResource
+}
+
+// BundleViolation is a cross-resource violation attributed to one resource of a bundle
+type BundleViolation struct {
+	Index     int // position of the offending resource in Bundle.Resources
+	Violation Violation
+}
+
+// EvaluateBundle evaluates the cross-resource conditions of all rules after a scan
+// It complements EvaluateResource, which sees one resource at a time
+func (re *RuleEngine) EvaluateBundle(bundle *Bundle) []BundleViolation {
+	var violations []BundleViolation
+
+	for _, rule := range re.rules {
+		// Files the rule excludes are invisible to it, not only unreported
+		scoped := bundle.scopedTo(rule)
+		for i, resource := range bundle.Resources {
+			if !re.appliesTo(rule, resource) {
+				continue
+			}
+
+			matched, details := re.matchBundle(rule, resource, scoped)
+			if !matched {
+				continue
+			}
+
+			message := strings.ReplaceAll(rule.Message, "{kind}", resource.Kind)
+			message = strings.ReplaceAll(message, "{name}", getResourceName(resource))
+			message = strings.ReplaceAll(message, "{details}", details)
+
+			violation := Violation{
+				Severity: rule.Severity,
+				Message:  message,
+				Rule:     rule.Name,
+				Help:     rule.Help,
+				Profile:  rule.Profile,
+			}
+			for _, v := range re.ApplyIgnores(resource, []Violation{violation}) {
+				violations = append(violations, BundleViolation{Index: i, Violation: v})
+			}
+		}
+	}
+
+	return violations
+}
+
+// matchBundle reports whether a rule matches a resource of a bundle: its
+// when expression if it holds cross-resource conditions, otherwise the first
+// of its conditions that matches. Only one violation is reported per resource
+func (re *RuleEngine) matchBundle(rule Rule, resource K8sResource, bundle *Bundle) (bool, string) {
+	if rule.When != nil {
+		if rule.When.layer() != bundleLayer {
+			return false, ""
+		}
+		return re.evaluateWhen(rule.When, whenScope{resource: resource, podSpec: extractPodSpec(resource, re.config.PodSpecPaths), bundle: bundle})
+	}
+	for _, condition := range rule.parsed {
+		if matched, details := re.checkBundleCondition(condition, resource, bundle); matched {
+			return true, details
+		}
+	}
+	return false, ""
+}
+
+// scopedTo returns the bundle without the resources from files the rule excludes
+func (b *Bundle) scopedTo(rule Rule) *Bundle {
+	if len(rule.ExcludePaths) == 0 {
+		return b
+	}
+	scoped := &Bundle{}
+	for _, resource := range b.Resources {
+		if rule.AppliesToPath(resource.File) {
+			scoped.Resources = append(scoped.Resources, resource)
+		}
+	}
+	return scoped
+}
+
+// checkBundleCondition evaluates a single cross-resource condition
+// Container- and resource-level conditions never match here
+func (re *RuleEngine) checkBundleCondition(condition Condition, resource K8sResource, bundle *Bundle) (bool, string) {
+	conditionType := condition.Type
+
+	switch conditionType {
+	case "duplicate_resource":
+		return duplicateResource(resource, bundle, re.config.IsClusterScoped)
+	case "config_reference_missing":
+		// In a partial scan, most referenced objects live elsewhere
+		if !re.config.AssumeCompleteBundle {
+			return false, ""
+		}
+		return configReferenceMissing(resource, bundle, re.config.PodSpecPaths)
+	case "service_account_missing":
+		if !re.config.AssumeCompleteBundle {
+			return false, ""
+		}
+		return serviceAccountMissing(resource, bundle, re.config.PodSpecPaths)
+	case "crd_schema_violation":
+		return crdSchemaViolations(resource, bundle)
+	case "missing_pdb":
+		return missingPDB(resource, bundle, re.config.PodSpecPaths)
+	case "pdb_selector_unmatched":
+		return pdbSelectorUnmatched(resource, bundle, re.config.PodSpecPaths)
+	case "pdb_blocks_eviction":
+		return pdbBlocksEviction(resource, bundle, re.config.PodSpecPaths)
+	case "service_selector_unmatched":
+		return serviceSelectorUnmatched(resource, bundle, re.config.PodSpecPaths)
+	case "gateway_backend_missing":
+		return gatewayBackendMissing(resource, bundle)
+	case "namespace_missing_default_deny":
+		return namespaceMissingDefaultDeny(resource, bundle, re.config.PodSpecPaths)
+	case "pvc_rwo_shared":
+		return pvcRWOShared(resource, bundle, re.config.PodSpecPaths)
+	case "statefulset_service_not_headless":
+		return statefulSetServiceNotHeadless(resource, bundle)
+	case "hpa_target_missing":
+		return hpaTargetMissing(resource, bundle)
+	case "replicas_set_with_hpa":
+		return replicasSetWithHPA(resource, bundle)
+	default:
+		return false, ""
+	}
+}
+
+// resourceIdentity is the key kubectl apply uses for a resource: API group,
+// kind, namespace, and name. Cluster-scoped kinds have no namespace
+func resourceIdentity(resource K8sResource, isClusterScoped func(string) bool) string {
+	namespace := ""
+	if !isClusterScoped(resource.Kind) {
+		namespace = getResourceNamespace(resource)
+	}
+	return strings.Join([]string{apiGroup(resource.APIVersion), resource.Kind, namespace, getResourceName(resource)}, "/")
+}
+
+// resourceLocation names the file and document a resource was read from
+func resourceLocation(resource K8sResource) string {
+	suffix := fmt.Sprintf("#%d", resource.Document)
+	// Documents read from stdin are already named <stdin>#N
+	if strings.HasSuffix(resource.File, suffix) {
+		return resource.File
+	}
+	return resource.File + suffix
+}
+
+// duplicateResource flags resources whose identity another document in the
+// scan also defines; kubectl apply silently keeps whichever comes last
+// The details list the locations of the other definitions
+func duplicateResource(resource K8sResource, bundle *Bundle, isClusterScoped func(string) bool) (bool, string) {
+	if len(bundle.Resources) < 2 || getResourceName(resource) == "" {
+		return false, ""
+	}
+
+	identity := resourceIdentity(resource, isClusterScoped)
+	self := resourceLocation(resource)
+	var others []string
+	for _, other := range bundle.Resources {
+		if other.Kind != resource.Kind || resourceIdentity(other, isClusterScoped) != identity {
+			continue
+		}
+		if location := resourceLocation(other); location != self {
+			others = append(others, location)
+		}
+	}
+	return len(others) > 0, strings.Join(others, ", ")
+}
+
+// missingPDB flags replicated workloads with 2+ replicas that no PodDisruptionBudget
+// in the same namespace selects
+func missingPDB(resource K8sResource, bundle *Bundle, specPaths map[string]string) (bool, string) {
+	replicas, ok := workloadReplicas(resource)
+	if !ok {
+		return false, ""
+	}
+	if replicas < 2 {
+		return false, ""
+	}
+
+	labels := podTemplateLabels(resource, specPaths)
+	for _, other := range bundle.Resources {
+		if other.Kind != "PodDisruptionBudget" || getResourceNamespace(other) != getResourceNamespace(resource) {
+			continue
+		}
+		selectorMap, _ := other.Spec["selector"].(map[string]interface{})
+		if selector := parseLabelSelector(selectorMap); selector != nil && selector.Matches(labels) {
+			return false, ""
+		}
+	}
+
+	return true, strconv.Itoa(replicas)
+}
+
+// pdbSelector returns a PodDisruptionBudget's selector, and nil for other kinds
+func pdbSelector(resource K8sResource) *LabelSelector {
+	if resource.Kind != "PodDisruptionBudget" {
+		return nil
+	}
+	selectorMap, _ := resource.Spec["selector"].(map[string]interface{})
+	return parseLabelSelector(selectorMap)
+}
+
+// pdbTargets returns the workloads in the PDB's namespace whose pod template
+// labels its selector matches
+func pdbTargets(resource K8sResource, selector *LabelSelector, bundle *Bundle, specPaths map[string]string) []K8sResource {
+	var targets []K8sResource
+	namespace := getResourceNamespace(resource)
+	for _, other := range bundle.Resources {
+		if !isWorkload(other, specPaths) || getResourceNamespace(other) != namespace {
+			continue
+		}
+		if selector.Matches(podTemplateLabels(other, specPaths)) {
+			targets = append(targets, other)
+		}
+	}
+	return targets
+}
+
+// pdbSelectorUnmatched flags PodDisruptionBudgets whose selector matches no pod
+// template in the same namespace, so they protect nothing
+// The details are the selector
+func pdbSelectorUnmatched(resource K8sResource, bundle *Bundle, specPaths map[string]string) (bool, string) {
+	selector := pdbSelector(resource)
+	if selector == nil || len(bundle.Resources) < 2 {
+		return false, ""
+	}
+	if len(pdbTargets(resource, selector, bundle, specPaths)) > 0 {
+		return false, ""
+	}
+	return true, selector.String()
+}
+
+// pdbBlocksEviction flags PodDisruptionBudgets that allow no voluntary
+// disruption, so node drains hang: maxUnavailable 0, minAvailable 100%, or a
+// minAvailable at or above the explicit replica count of a workload it selects
+func pdbBlocksEviction(resource K8sResource, bundle *Bundle, specPaths map[string]string) (bool, string) {
+	if resource.Kind != "PodDisruptionBudget" {
+		return false, ""
+	}
+
+	switch value := resource.Spec["maxUnavailable"].(type) {
+	case int, int64, float64:
+		if n, _ := getIntValue(resource.Spec, "maxUnavailable"); n == 0 {
+			return true, "maxUnavailable is 0"
+		}
+	case string:
+		if value == "0%" {
+			return true, "maxUnavailable is 0%"
+		}
+	}
+
+	if getStringValue(resource.Spec, "minAvailable") == "100%" {
+		return true, "minAvailable is 100%"
+	}
+	minAvailable, ok := getIntValue(resource.Spec, "minAvailable")
+	selector := pdbSelector(resource)
+	if !ok || selector == nil {
+		return false, ""
+	}
+	for _, target := range pdbTargets(resource, selector, bundle, specPaths) {
+		// Without an explicit count the replicas may be left to an HPA
+		replicas, ok := getIntValue(target.Spec, "replicas")
+		if ok && replicatedKinds[target.Kind] && minAvailable >= replicas {
+			return true, fmt.Sprintf("minAvailable %d covers all %d replicas of %s '%s'", minAvailable, replicas, target.Kind, getResourceName(target))
+		}
+	}
+	return false, ""
+}
+
+// serviceSelectorUnmatched flags Services whose selector matches no pod template
+// in the same namespace. Services without a selector route to manually managed
+// Endpoints, and a single-resource scan cannot show the workload, so both are skipped
+func serviceSelectorUnmatched(resource K8sResource, bundle *Bundle, specPaths map[string]string) (bool, string) {
+	if resource.Kind != "Service" || len(bundle.Resources) < 2 {
+		return false, ""
+	}
+	if serviceType(resource) == "ExternalName" {
+		return false, ""
+	}
+	selector := getStringMap(resource.Spec, "selector")
+	if len(selector) == 0 {
+		return false, ""
+	}
+
+	namespace := getResourceNamespace(resource)
+	for _, other := range bundle.Resources {
+		if !isWorkload(other, specPaths) || getResourceNamespace(other) != namespace {
+			continue
+		}
+		if (&LabelSelector{MatchLabels: selector}).Matches(podTemplateLabels(other, specPaths)) {
+			return false, ""
+		}
+	}
+
+	pairs := make([]string, 0, len(selector))
+	for key, value := range selector {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return true, strings.Join(pairs, ",")
+}
+
+// namespaceMissingDefaultDeny flags namespaces that run workloads without a
+// NetworkPolicy selecting every pod for Ingress. The finding goes to the Namespace
+// object, or to the namespace's first workload when the bundle has no Namespace object
+func namespaceMissingDefaultDeny(resource K8sResource, bundle *Bundle, specPaths map[string]string) (bool, string) {
+	var namespace string
+	switch {
+	case resource.Kind == "Namespace":
+		namespace = getResourceName(resource)
+	case isWorkload(resource, specPaths):
+		namespace = getResourceNamespace(resource)
+	default:
+		return false, ""
+	}
+
+	var firstWorkload *K8sResource
+	for i, other := range bundle.Resources {
+		switch {
+		case other.Kind == "Namespace" && getResourceName(other) == namespace && resource.Kind != "Namespace":
+			return false, "" // reported on the Namespace object instead
+		case other.Kind == "NetworkPolicy" && getResourceNamespace(other) == namespace && isDefaultDenyIngress(other):
+			return false, ""
+		case isWorkload(other, specPaths) && getResourceNamespace(other) == namespace && firstWorkload == nil:
+			firstWorkload = &bundle.Resources[i]
+		}
+	}
+	if firstWorkload == nil {
+		return false, ""
+	}
+	if resource.Kind != "Namespace" && (resource.Kind != firstWorkload.Kind || getResourceName(resource) != getResourceName(*firstWorkload)) {
+		return false, ""
+	}
+
+	return true, fmt.Sprintf("'%s' (unprotected: %s '%s')", namespace, firstWorkload.Kind, getResourceName(*firstWorkload))
+}
+
+// pvcRWOShared flags single-node PersistentVolumeClaims that a StatefulSet with
+// 2 or more replicas mounts as a pod volume instead of through volumeClaimTemplates,
+// so every replica shares one claim that only one node can attach
+func pvcRWOShared(resource K8sResource, bundle *Bundle, specPaths map[string]string) (bool, string) {
+	if resource.Kind != "PersistentVolumeClaim" {
+		return false, ""
+	}
+	accessModes := getStringList(resource.Spec, "accessModes")
+	if !containsString(accessModes, "ReadWriteOnce") && !containsString(accessModes, "ReadWriteOncePod") {
+		return false, ""
+	}
+
+	for _, other := range bundle.Resources {
+		if other.Kind != "StatefulSet" || getResourceNamespace(other) != getResourceNamespace(resource) {
+			continue
+		}
+		replicas, _ := workloadReplicas(other)
+		podSpec := extractPodSpec(other, specPaths)
+		if replicas < 2 || podSpec == nil {
+			continue
+		}
+		for _, volume := range podSpec.Volumes {
+			if volume.ClaimName == getResourceName(resource) {
+				return true, fmt.Sprintf("StatefulSet '%s' (%d replicas)", getResourceName(other), replicas)
+			}
+		}
+	}
+	return false, ""
+}
+
+// statefulSetServiceNotHeadless flags StatefulSets whose serviceName does not
+// resolve to a headless Service (clusterIP: None) in the same namespace, so the
+// pods get no stable DNS names; like serviceSelectorUnmatched, it skips
+// single-resource scans
+func statefulSetServiceNotHeadless(resource K8sResource, bundle *Bundle) (bool, string) {
+	if resource.Kind != "StatefulSet" || len(bundle.Resources) < 2 {
+		return false, ""
+	}
+	serviceName := getStringValue(resource.Spec, "serviceName")
+	if serviceName == "" {
+		return false, ""
+	}
+
+	for _, other := range bundle.Resources {
+		if other.Kind != "Service" || getResourceName(other) != serviceName || getResourceNamespace(other) != getResourceNamespace(resource) {
+			continue
+		}
+		if clusterIP := getStringValue(other.Spec, "clusterIP"); clusterIP != "None" {
+			if clusterIP == "" {
+				clusterIP = "unset"
+			}
+			return true, fmt.Sprintf("'%s' has clusterIP %s", serviceName, clusterIP)
+		}
+		return false, ""
+	}
+	return true, fmt.Sprintf("'%s' is not in the scanned manifests", serviceName)
+}
+
+// hpaTargetMissing flags HorizontalPodAutoscalers whose scaleTargetRef resolves to
+// no workload in the scan; like serviceSelectorUnmatched, it skips single-resource scans
+func hpaTargetMissing(resource K8sResource, bundle *Bundle) (bool, string) {
+	hpa := parseHPASpec(resource)
+	if hpa == nil || len(bundle.Resources) < 2 {
+		return false, ""
+	}
+	if findScaleTarget(resource, hpa, bundle) != nil {
+		return false, ""
+	}
+
+	ref := hpa.ScaleTargetRef
+	details := fmt.Sprintf("%s '%s'", ref.Kind, ref.Name)
+	if ref.APIVersion != "" {
+		details += " (" + ref.APIVersion + ")"
+	}
+	return true, details
+}
+
+// replicasSetWithHPA flags workloads that set spec.replicas while an HPA in the
+// scan scales them, so every apply resets the replica count
+func replicasSetWithHPA(resource K8sResource, bundle *Bundle) (bool, string) {
+	if _, ok := resource.Spec["replicas"]; !ok {
+		return false, ""
+	}
+
+	for _, other := range bundle.Resources {
+		hpa := parseHPASpec(other)
+		if hpa == nil {
+			continue
+		}
+		if target := findScaleTarget(other, hpa, bundle); target != nil && target.Kind == resource.Kind &&
+			getResourceName(*target) == getResourceName(resource) && getResourceNamespace(*target) == getResourceNamespace(resource) {
+			return true, getResourceName(other)
+		}
+	}
+	return false, ""
+}
+
+// findScaleTarget resolves an HPA's scaleTargetRef against the bundle
+// The apiVersion group must match too, so a Deployment referenced as
+// extensions/v1beta1 does not resolve to an apps/v1 one
+func findScaleTarget(resource K8sResource, hpa *HPASpec, bundle *Bundle) *K8sResource {
+	ref := hpa.ScaleTargetRef
+	namespace := getResourceNamespace(resource)
+	for i, other := range bundle.Resources {
+		if other.Kind != ref.Kind || getResourceName(other) != ref.Name || getResourceNamespace(other) != namespace {
+			continue
+		}
+		if ref.APIVersion != "" && apiGroup(ref.APIVersion) != apiGroup(other.APIVersion) {
+			continue
+		}
+		return &bundle.Resources[i]
+	}
+	return nil
+}
+
+// apiGroup returns the group of an apiVersion, "" for the core group
+func apiGroup(apiVersion string) string {
+	group, _, ok := strings.Cut(apiVersion, "/")
+	if !ok {
+		return ""
+	}
+	return group
+}
+
+// isWorkload reports whether a resource carries a pod spec, built in or configured
+func isWorkload(resource K8sResource, specPaths map[string]string) bool {
+	_, ok := podSpecPath(resource, specPaths)
+	return ok
+}
+
+// isDefaultDenyIngress reports whether a NetworkPolicy selects every pod in its
+// namespace (an empty podSelector) and governs Ingress traffic
+func isDefaultDenyIngress(policy K8sResource) bool {
+	podSelector, ok := policy.Spec["podSelector"].(map[string]interface{})
+	if !ok || len(podSelector) > 0 {
+		return false
+	}
+	// Without policyTypes, a policy always applies to Ingress
+	policyTypes := getStringList(policy.Spec, "policyTypes")
+	return len(policyTypes) == 0 || containsString(policyTypes, "Ingress")
+}
+
+// getResourceNamespace returns the namespace from metadata, treating an unset namespace as "default"
+func getResourceNamespace(resource K8sResource) string {
+	if namespace, ok := resource.Metadata["namespace"].(string); ok && namespace != "" {
+		return namespace
+	}
+	return "default"
+}
@@ -0,0 +1,1320 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// celRuleType is the rule type whose expression is a CEL program
+const celRuleType = "cel"
+
+// celCostLimit caps the steps one evaluation may take, so an expression
+// iterating over a large resource fails instead of stalling the scan
+const celCostLimit = 1000000
+
+// errCELCostLimit is returned by evaluations that exceed celCostLimit
+var errCELCostLimit = fmt.Errorf("expression exceeded the cost limit of %d", celCostLimit)
+
+// celProgram is a compiled CEL expression. kubecheck implements the part of
+// CEL that checks over manifests need: literals, field selection, indexing,
+// the logical, comparison, and arithmetic operators, has(), the list macros,
+// and the common string functions
+type celProgram struct {
+	root celNode
+}
+
+// compileCEL parses an expression and checks its variables and functions
+func compileCEL(source string) (*celProgram, error) {
+	tokens, err := lexCEL(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &celParser{tokens: tokens, vars: map[string]int{"object": 1, "containers": 1}}
+	root, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if token := p.peek(); token.kind != celEOF {
+		return nil, fmt.Errorf("column %d: unexpected %q", token.pos+1, token.text)
+	}
+	return &celProgram{root: root}, nil
+}
+
+// Evaluate runs the program with the given variables; the expression must
+// return a bool
+func (p *celProgram) Evaluate(vars map[string]interface{}) (bool, error) {
+	env := &celEnv{vars: vars}
+	value, err := p.root.eval(env)
+	if err != nil {
+		return false, err
+	}
+	result, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("expression returned %s, not bool", celTypeName(value))
+	}
+	return result, nil
+}
+
+// validateExpression checks that a rule has an expression exactly when it
+// is of type cel, and compiles it
+func validateExpression(rule Rule) error {
+	if rule.Type != celRuleType {
+		if rule.Expression != "" {
+			return fmt.Errorf("rule %q: expression is only evaluated by rules of type %s", rule.Name, celRuleType)
+		}
+		return nil
+	}
+	if rule.Expression == "" {
+		return fmt.Errorf("rule %q: rules of type %s need an expression", rule.Name, celRuleType)
+	}
+	if len(rule.Conditions) > 0 || rule.When != nil {
+		return fmt.Errorf("rule %q: rules of type %s take an expression instead of conditions", rule.Name, celRuleType)
+	}
+	if _, err := compileCEL(rule.Expression); err != nil {
+		return fmt.Errorf("rule %q: expression: %w", rule.Name, err)
+	}
+	return nil
+}
+
+// celVariables binds a resource for a cel rule: object is the whole
+// document and containers the containers, init containers, and ephemeral
+// containers of its pod spec, empty for resources without one
+func celVariables(resource K8sResource, specPaths map[string]string) map[string]interface{} {
+	containers := []interface{}{}
+	if podSpec := findPodSpec(resource, specPaths); podSpec != nil {
+		for _, field := range []string{"containers", "initContainers", "ephemeralContainers"} {
+			if list, ok := podSpec[field].([]interface{}); ok {
+				containers = append(containers, list...)
+			}
+		}
+	}
+	object := resource.Object
+	if object == nil {
+		object = map[string]interface{}{}
+	}
+	return map[string]interface{}{"object": object, "containers": containers}
+}
+
+// evaluateCELRule evaluates a cel rule against a resource. An expression
+// that fails, such as on a field the resource lacks, does not match; one
+// that runs past the cost limit is reported so it cannot pass silently
+func (re *RuleEngine) evaluateCELRule(rule Rule, resource K8sResource) []Violation {
+	matched, err := rule.program.Evaluate(celVariables(resource, re.config.PodSpecPaths))
+	message, help := rule.Message, rule.Help
+	switch {
+	case errors.Is(err, errCELCostLimit):
+		message = "{kind} '{name}': " + err.Error()
+		help = "simplify the expression or narrow the rule with kinds"
+	case err != nil || !matched:
+		return nil
+	}
+
+	message = strings.ReplaceAll(message, "{kind}", resource.Kind)
+	message = strings.ReplaceAll(message, "{name}", getResourceName(resource))
+	message = strings.ReplaceAll(message, "{details}", "")
+
+	return []Violation{{
+		Severity: rule.Severity,
+		Message:  message,
+		Rule:     rule.Name,
+		Help:     help,
+		Profile:  rule.Profile,
+	}}
+}
+
+// celEnv is the state of one evaluation: the variables in scope and the
+// cost spent so far
+type celEnv struct {
+	vars map[string]interface{}
+	cost int
+}
+
+// step charges one unit of cost
+func (env *celEnv) step() error {
+	env.cost++
+	if env.cost > celCostLimit {
+		return errCELCostLimit
+	}
+	return nil
+}
+
+// celTokenKind is the kind of a lexical token
+type celTokenKind int
+
+const (
+	celEOF celTokenKind = iota
+	celIdent
+	celInt
+	celDouble
+	celString
+	celOperator
+)
+
+// celToken is a lexical token and its byte offset in the expression
+type celToken struct {
+	kind  celTokenKind
+	text  string
+	value interface{}
+	pos   int
+}
+
+// celOperators lists the operators and punctuation, longest first
+var celOperators = []string{
+	"==", "!=", "<=", ">=", "&&", "||",
+	"<", ">", "!", "+", "-", "*", "/", "%", "(", ")", "[", "]", "{", "}", ".", ",", "?", ":",
+}
+
+// lexCEL splits an expression into tokens
+func lexCEL(source string) ([]celToken, error) {
+	var tokens []celToken
+	for i := 0; i < len(source); {
+		c := source[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '"' || c == '\'' || ((c == 'r' || c == 'R') && i+1 < len(source) && (source[i+1] == '"' || source[i+1] == '\'')):
+			value, end, err := lexCELString(source, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, celToken{kind: celString, text: source[i:end], value: value, pos: i})
+			i = end
+		case c >= '0' && c <= '9':
+			end := i
+			isDouble := false
+			for end < len(source) && (source[end] >= '0' && source[end] <= '9' || source[end] == '.' || source[end] == 'e' || source[end] == 'E' ||
+				(source[end] == '-' || source[end] == '+') && (source[end-1] == 'e' || source[end-1] == 'E')) {
+				if source[end] == '.' || source[end] == 'e' || source[end] == 'E' {
+					isDouble = true
+				}
+				end++
+			}
+			text := source[i:end]
+			if isDouble {
+				value, err := strconv.ParseFloat(text, 64)
+				if err != nil {
+					return nil, fmt.Errorf("column %d: invalid number %q", i+1, text)
+				}
+				tokens = append(tokens, celToken{kind: celDouble, text: text, value: value, pos: i})
+			} else {
+				value, err := strconv.ParseInt(text, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("column %d: invalid number %q", i+1, text)
+				}
+				tokens = append(tokens, celToken{kind: celInt, text: text, value: value, pos: i})
+			}
+			i = end
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			end := i
+			for end < len(source) && (source[end] == '_' || source[end] >= 'a' && source[end] <= 'z' ||
+				source[end] >= 'A' && source[end] <= 'Z' || source[end] >= '0' && source[end] <= '9') {
+				end++
+			}
+			tokens = append(tokens, celToken{kind: celIdent, text: source[i:end], pos: i})
+			i = end
+		default:
+			matched := false
+			for _, op := range celOperators {
+				if strings.HasPrefix(source[i:], op) {
+					tokens = append(tokens, celToken{kind: celOperator, text: op, pos: i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("column %d: unexpected character %q", i+1, c)
+			}
+		}
+	}
+	return append(tokens, celToken{kind: celEOF, pos: len(source)}), nil
+}
+
+// lexCELString reads a quoted string starting at start, with an optional r
+// prefix for raw strings, and returns its value and end offset
+func lexCELString(source string, start int) (string, int, error) {
+	i := start
+	raw := false
+	if source[i] == 'r' || source[i] == 'R' {
+		raw = true
+		i++
+	}
+	quote := source[i]
+	i++
+
+	var value strings.Builder
+	for i < len(source) {
+		c := source[i]
+		switch {
+		case c == quote:
+			return value.String(), i + 1, nil
+		case c == '\\' && !raw && i+1 < len(source):
+			switch escaped := source[i+1]; escaped {
+			case 'n':
+				value.WriteByte('\n')
+			case 't':
+				value.WriteByte('\t')
+			case 'r':
+				value.WriteByte('\r')
+			case '\\', '\'', '"', '`', '?':
+				value.WriteByte(escaped)
+			default:
+				return "", 0, fmt.Errorf("column %d: invalid escape \\%c", i+1, escaped)
+			}
+			i += 2
+		default:
+			value.WriteByte(c)
+			i++
+		}
+	}
+	return "", 0, fmt.Errorf("column %d: unterminated string", start+1)
+}
+
+// celParser is a recursive-descent parser over the tokens of an expression.
+// vars counts the variables in scope, including macro variables
+type celParser struct {
+	tokens []celToken
+	pos    int
+	vars   map[string]int
+}
+
+func (p *celParser) peek() celToken {
+	if p.pos >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *celParser) next() celToken {
+	token := p.peek()
+	p.pos++
+	return token
+}
+
+// accept consumes the next token if it is the operator op
+func (p *celParser) accept(op string) bool {
+	if token := p.peek(); token.kind == celOperator && token.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// expect consumes the operator op or fails
+func (p *celParser) expect(op string) error {
+	if p.accept(op) {
+		return nil
+	}
+	return p.unexpected("expected " + strconv.Quote(op))
+}
+
+// unexpected reports the next token as unexpected
+func (p *celParser) unexpected(hint string) error {
+	token := p.peek()
+	if token.kind == celEOF {
+		return fmt.Errorf("column %d: unexpected end of expression, %s", token.pos+1, hint)
+	}
+	return fmt.Errorf("column %d: unexpected %q, %s", token.pos+1, token.text, hint)
+}
+
+// parseExpr parses a conditional: or ? or : expr
+func (p *celParser) parseExpr() (celNode, error) {
+	condition, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept("?") {
+		return condition, nil
+	}
+	then, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	otherwise, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	return &celTernary{condition: condition, then: then, otherwise: otherwise}, nil
+}
+
+// celPrecedence lists the binary operators from the loosest to the tightest
+var celPrecedence = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!=", "<", "<=", ">", ">=", "in"},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+// parseBinary parses the left-associative operators of a precedence level
+func (p *celParser) parseBinary(level int) (celNode, error) {
+	if level == len(celPrecedence) {
+		return p.parseUnary()
+	}
+	left, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		token := p.peek()
+		if (token.kind != celOperator && !(token.kind == celIdent && token.text == "in")) ||
+			!containsString(celPrecedence[level], token.text) {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = &celBinary{op: token.text, left: left, right: right}
+	}
+}
+
+// parseUnary parses ! and unary minus
+func (p *celParser) parseUnary() (celNode, error) {
+	for _, op := range []string{"!", "-"} {
+		if p.accept(op) {
+			operand, err := p.parseUnary()
+			if err != nil {
+				return nil, err
+			}
+			return &celUnary{op: op, operand: operand}, nil
+		}
+	}
+	return p.parseMember()
+}
+
+// parseMember parses field selection, method calls, and indexing
+func (p *celParser) parseMember() (celNode, error) {
+	node, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.accept("."):
+			token := p.next()
+			if token.kind != celIdent {
+				p.pos--
+				return nil, p.unexpected("expected a field or method name")
+			}
+			if !p.accept("(") {
+				node = &celSelect{operand: node, field: token.text}
+				continue
+			}
+			if node, err = p.parseMethod(node, token); err != nil {
+				return nil, err
+			}
+		case p.accept("["):
+			index, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			node = &celIndex{operand: node, index: index}
+		default:
+			return node, nil
+		}
+	}
+}
+
+// celMethods maps the methods kubecheck supports to their argument count
+var celMethods = map[string]int{
+	"size":       0,
+	"startsWith": 1,
+	"endsWith":   1,
+	"contains":   1,
+	"matches":    1,
+	"lowerAscii": 0,
+	"upperAscii": 0,
+}
+
+// celMacros are the methods whose first argument names a variable bound to
+// each element of the target
+var celMacros = map[string]bool{"all": true, "exists": true, "exists_one": true, "map": true, "filter": true}
+
+// parseMethod parses the arguments of a method call on target, after "("
+func (p *celParser) parseMethod(target celNode, name celToken) (celNode, error) {
+	if celMacros[name.text] {
+		variable := p.next()
+		if variable.kind != celIdent {
+			p.pos--
+			return nil, p.unexpected("expected a variable name for " + name.text)
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+		p.vars[variable.text]++
+		body, err := p.parseExpr()
+		p.vars[variable.text]--
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return &celComprehension{macro: name.text, target: target, variable: variable.text, body: body}, nil
+	}
+
+	arity, ok := celMethods[name.text]
+	if !ok {
+		return nil, fmt.Errorf("column %d: unknown function %q", name.pos+1, name.text)
+	}
+	args, err := p.parseArgs()
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != arity {
+		return nil, fmt.Errorf("column %d: %s takes %d argument(s), got %d", name.pos+1, name.text, arity, len(args))
+	}
+	call := &celCall{function: name.text, target: target, args: args}
+	return call, call.compilePattern()
+}
+
+// celFunctions maps the global functions kubecheck supports to their
+// argument count
+var celFunctions = map[string]int{
+	"size":    1,
+	"int":     1,
+	"double":  1,
+	"string":  1,
+	"matches": 2,
+}
+
+// parsePrimary parses literals, variables, global calls, parentheses, and
+// list and map literals
+func (p *celParser) parsePrimary() (celNode, error) {
+	token := p.next()
+	switch token.kind {
+	case celInt, celDouble, celString:
+		return &celLiteral{value: token.value}, nil
+	case celIdent:
+		switch token.text {
+		case "true", "false":
+			return &celLiteral{value: token.text == "true"}, nil
+		case "null":
+			return &celLiteral{value: nil}, nil
+		}
+		if !p.accept("(") {
+			if p.vars[token.text] == 0 {
+				return nil, fmt.Errorf("column %d: undeclared variable %q (use object or containers)", token.pos+1, token.text)
+			}
+			return &celVariable{name: token.text}, nil
+		}
+		return p.parseFunction(token)
+	case celOperator:
+		switch token.text {
+		case "(":
+			node, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			return node, p.expect(")")
+		case "[":
+			elements, err := p.parseList("]")
+			if err != nil {
+				return nil, err
+			}
+			return &celList{elements: elements}, nil
+		case "{":
+			return p.parseMap()
+		}
+	}
+	p.pos--
+	return nil, p.unexpected("expected a value")
+}
+
+// parseFunction parses the arguments of a global call, after "("
+func (p *celParser) parseFunction(name celToken) (celNode, error) {
+	if name.text == "has" {
+		arg, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		selection, ok := arg.(*celSelect)
+		if !ok {
+			return nil, fmt.Errorf("column %d: has() takes a field selection such as object.spec.replicas", name.pos+1)
+		}
+		return &celHas{operand: selection.operand, field: selection.field}, p.expect(")")
+	}
+
+	arity, ok := celFunctions[name.text]
+	if !ok {
+		return nil, fmt.Errorf("column %d: unknown function %q", name.pos+1, name.text)
+	}
+	args, err := p.parseArgs()
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != arity {
+		return nil, fmt.Errorf("column %d: %s takes %d argument(s), got %d", name.pos+1, name.text, arity, len(args))
+	}
+	call := &celCall{function: name.text, args: args}
+	return call, call.compilePattern()
+}
+
+// parseArgs parses call arguments up to the closing parenthesis
+func (p *celParser) parseArgs() ([]celNode, error) {
+	return p.parseList(")")
+}
+
+// parseList parses comma-separated expressions up to close, allowing a
+// trailing comma
+func (p *celParser) parseList(close string) ([]celNode, error) {
+	var nodes []celNode
+	for !p.accept(close) {
+		node, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+		if !p.accept(",") {
+			return nodes, p.expect(close)
+		}
+	}
+	return nodes, nil
+}
+
+// parseMap parses the entries of a map literal, after "{"
+func (p *celParser) parseMap() (celNode, error) {
+	node := &celMap{}
+	for !p.accept("}") {
+		key, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		value, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		node.keys = append(node.keys, key)
+		node.values = append(node.values, value)
+		if !p.accept(",") {
+			return node, p.expect("}")
+		}
+	}
+	return node, nil
+}
+
+// celNode is a node of a compiled expression
+type celNode interface {
+	eval(env *celEnv) (interface{}, error)
+}
+
+type celLiteral struct {
+	value interface{}
+}
+
+func (n *celLiteral) eval(env *celEnv) (interface{}, error) {
+	return n.value, env.step()
+}
+
+type celVariable struct {
+	name string
+}
+
+func (n *celVariable) eval(env *celEnv) (interface{}, error) {
+	if err := env.step(); err != nil {
+		return nil, err
+	}
+	return celValue(env.vars[n.name]), nil
+}
+
+type celSelect struct {
+	operand celNode
+	field   string
+}
+
+func (n *celSelect) eval(env *celEnv) (interface{}, error) {
+	value, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	if err := env.step(); err != nil {
+		return nil, err
+	}
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("cannot select %q from %s", n.field, celTypeName(value))
+	}
+	field, ok := fields[n.field]
+	if !ok {
+		return nil, fmt.Errorf("no such key: %s", n.field)
+	}
+	return celValue(field), nil
+}
+
+// celHas is has(operand.field): whether the field is set
+type celHas struct {
+	operand celNode
+	field   string
+}
+
+func (n *celHas) eval(env *celEnv) (interface{}, error) {
+	value, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	if err := env.step(); err != nil {
+		return nil, err
+	}
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("cannot test %q on %s", n.field, celTypeName(value))
+	}
+	_, ok = fields[n.field]
+	return ok, nil
+}
+
+type celIndex struct {
+	operand celNode
+	index   celNode
+}
+
+func (n *celIndex) eval(env *celEnv) (interface{}, error) {
+	value, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	index, err := n.index.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch value := value.(type) {
+	case []interface{}:
+		i, ok := index.(int64)
+		if !ok {
+			return nil, fmt.Errorf("list index must be int, not %s", celTypeName(index))
+		}
+		if i < 0 || i >= int64(len(value)) {
+			return nil, fmt.Errorf("index %d out of range for a list of %d", i, len(value))
+		}
+		return celValue(value[i]), nil
+	case map[string]interface{}:
+		key, ok := index.(string)
+		if !ok {
+			return nil, fmt.Errorf("map key must be string, not %s", celTypeName(index))
+		}
+		field, ok := value[key]
+		if !ok {
+			return nil, fmt.Errorf("no such key: %s", key)
+		}
+		return celValue(field), nil
+	default:
+		return nil, fmt.Errorf("cannot index %s", celTypeName(value))
+	}
+}
+
+type celUnary struct {
+	op      string
+	operand celNode
+}
+
+func (n *celUnary) eval(env *celEnv) (interface{}, error) {
+	value, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch value := value.(type) {
+	case bool:
+		if n.op == "!" {
+			return !value, nil
+		}
+	case int64:
+		if n.op == "-" {
+			return -value, nil
+		}
+	case float64:
+		if n.op == "-" {
+			return -value, nil
+		}
+	}
+	return nil, fmt.Errorf("no such overload: %s%s", n.op, celTypeName(value))
+}
+
+type celBinary struct {
+	op          string
+	left, right celNode
+}
+
+func (n *celBinary) eval(env *celEnv) (interface{}, error) {
+	if n.op == "&&" || n.op == "||" {
+		return n.evalLogical(env)
+	}
+
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	if err := env.step(); err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return celEqual(left, right), nil
+	case "!=":
+		return !celEqual(left, right), nil
+	case "<", "<=", ">", ">=":
+		order, err := celCompare(left, right)
+		if err != nil {
+			return nil, fmt.Errorf("no such overload: %s %s %s", celTypeName(left), n.op, celTypeName(right))
+		}
+		switch n.op {
+		case "<":
+			return order < 0, nil
+		case "<=":
+			return order <= 0, nil
+		case ">":
+			return order > 0, nil
+		default:
+			return order >= 0, nil
+		}
+	case "in":
+		return celIn(left, right)
+	default:
+		return celArithmetic(n.op, left, right)
+	}
+}
+
+// evalLogical evaluates && and || the way CEL does: a false operand of &&
+// or a true operand of || decides the result even when the other operand
+// fails, so guards may come on either side
+func (n *celBinary) evalLogical(env *celEnv) (interface{}, error) {
+	decisive := n.op == "||"
+
+	left, leftErr := n.left.eval(env)
+	if errors.Is(leftErr, errCELCostLimit) {
+		return nil, leftErr
+	}
+	if leftErr == nil {
+		if b, ok := left.(bool); ok && b == decisive {
+			return decisive, nil
+		}
+	}
+
+	right, rightErr := n.right.eval(env)
+	if errors.Is(rightErr, errCELCostLimit) {
+		return nil, rightErr
+	}
+	if rightErr == nil {
+		if b, ok := right.(bool); ok && b == decisive {
+			return decisive, nil
+		}
+	}
+
+	if leftErr != nil {
+		return nil, leftErr
+	}
+	if rightErr != nil {
+		return nil, rightErr
+	}
+	if _, ok := left.(bool); !ok {
+		return nil, fmt.Errorf("no such overload: %s %s %s", celTypeName(left), n.op, celTypeName(right))
+	}
+	if _, ok := right.(bool); !ok {
+		return nil, fmt.Errorf("no such overload: %s %s %s", celTypeName(left), n.op, celTypeName(right))
+	}
+	return !decisive, nil
+}
+
+type celTernary struct {
+	condition, then, otherwise celNode
+}
+
+func (n *celTernary) eval(env *celEnv) (interface{}, error) {
+	condition, err := n.condition.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := condition.(bool)
+	if !ok {
+		return nil, fmt.Errorf("condition of ?: must be bool, not %s", celTypeName(condition))
+	}
+	if b {
+		return n.then.eval(env)
+	}
+	return n.otherwise.eval(env)
+}
+
+type celList struct {
+	elements []celNode
+}
+
+func (n *celList) eval(env *celEnv) (interface{}, error) {
+	list := make([]interface{}, 0, len(n.elements))
+	for _, element := range n.elements {
+		value, err := element.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+	}
+	return list, nil
+}
+
+type celMap struct {
+	keys, values []celNode
+}
+
+func (n *celMap) eval(env *celEnv) (interface{}, error) {
+	fields := make(map[string]interface{}, len(n.keys))
+	for i := range n.keys {
+		key, err := n.keys[i].eval(env)
+		if err != nil {
+			return nil, err
+		}
+		name, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("map key must be string, not %s", celTypeName(key))
+		}
+		value, err := n.values[i].eval(env)
+		if err != nil {
+			return nil, err
+		}
+		fields[name] = value
+	}
+	return fields, nil
+}
+
+// celCall is a function call; target is nil for global functions
+type celCall struct {
+	function string
+	target   celNode
+	args     []celNode
+	// pattern is the compiled regex of matches() with a literal pattern
+	pattern *regexp.Regexp
+}
+
+// compilePattern compiles the literal pattern of matches() at load time
+func (n *celCall) compilePattern() error {
+	if n.function != "matches" {
+		return nil
+	}
+	literal, ok := n.args[len(n.args)-1].(*celLiteral)
+	if !ok {
+		return nil
+	}
+	pattern, ok := literal.value.(string)
+	if !ok {
+		return nil
+	}
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid regex %q: %w", pattern, err)
+	}
+	n.pattern = compiled
+	return nil
+}
+
+func (n *celCall) eval(env *celEnv) (interface{}, error) {
+	var args []interface{}
+	if n.target != nil {
+		target, err := n.target.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, target)
+	}
+	for _, arg := range n.args {
+		value, err := arg.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, value)
+	}
+	if err := env.step(); err != nil {
+		return nil, err
+	}
+
+	switch n.function {
+	case "size":
+		switch value := args[0].(type) {
+		case string:
+			return int64(utf8.RuneCountInString(value)), nil
+		case []interface{}:
+			return int64(len(value)), nil
+		case map[string]interface{}:
+			return int64(len(value)), nil
+		}
+	case "int":
+		switch value := args[0].(type) {
+		case int64:
+			return value, nil
+		case float64:
+			return int64(value), nil
+		case string:
+			i, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("cannot convert %q to int", value)
+			}
+			return i, nil
+		}
+	case "double":
+		switch value := args[0].(type) {
+		case int64:
+			return float64(value), nil
+		case float64:
+			return value, nil
+		case string:
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("cannot convert %q to double", value)
+			}
+			return f, nil
+		}
+	case "string":
+		switch value := args[0].(type) {
+		case string:
+			return value, nil
+		case int64:
+			return strconv.FormatInt(value, 10), nil
+		case float64:
+			return strconv.FormatFloat(value, 'g', -1, 64), nil
+		case bool:
+			return strconv.FormatBool(value), nil
+		}
+	case "startsWith", "endsWith", "contains", "matches":
+		s, ok1 := args[0].(string)
+		arg, ok2 := args[1].(string)
+		if !ok1 || !ok2 {
+			break
+		}
+		switch n.function {
+		case "startsWith":
+			return strings.HasPrefix(s, arg), nil
+		case "endsWith":
+			return strings.HasSuffix(s, arg), nil
+		case "contains":
+			return strings.Contains(s, arg), nil
+		}
+		pattern := n.pattern
+		if pattern == nil {
+			compiled, err := regexp.Compile(arg)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regex %q: %w", arg, err)
+			}
+			pattern = compiled
+		}
+		return pattern.MatchString(s), nil
+	case "lowerAscii", "upperAscii":
+		if s, ok := args[0].(string); ok {
+			if n.function == "lowerAscii" {
+				return strings.ToLower(s), nil
+			}
+			return strings.ToUpper(s), nil
+		}
+	}
+
+	types := make([]string, len(args))
+	for i, arg := range args {
+		types[i] = celTypeName(arg)
+	}
+	return nil, fmt.Errorf("no such overload: %s(%s)", n.function, strings.Join(types, ", "))
+}
+
+// celComprehension is a list macro such as containers.all(c, has(c.resources))
+type celComprehension struct {
+	macro    string
+	target   celNode
+	variable string
+	body     celNode
+}
+
+func (n *celComprehension) eval(env *celEnv) (interface{}, error) {
+	target, err := n.target.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	var elements []interface{}
+	switch target := target.(type) {
+	case []interface{}:
+		elements = target
+	case map[string]interface{}:
+		for key := range target {
+			elements = append(elements, key)
+		}
+	default:
+		return nil, fmt.Errorf("cannot iterate over %s", celTypeName(target))
+	}
+
+	saved, shadowed := env.vars[n.variable]
+	defer func() {
+		if shadowed {
+			env.vars[n.variable] = saved
+		} else {
+			delete(env.vars, n.variable)
+		}
+	}()
+
+	var results []interface{}
+	var firstErr error
+	count := 0
+	for _, element := range elements {
+		env.vars[n.variable] = element
+		value, err := n.body.eval(env)
+		if err != nil {
+			// all and exists, like && and ||, let a decisive element win
+			// over an element that fails
+			if errors.Is(err, errCELCostLimit) || (n.macro != "all" && n.macro != "exists") {
+				return nil, err
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if n.macro == "map" {
+			results = append(results, value)
+			continue
+		}
+
+		matched, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%s predicate returned %s, not bool", n.macro, celTypeName(value))
+		}
+		switch {
+		case n.macro == "all" && !matched:
+			return false, nil
+		case n.macro == "exists" && matched:
+			return true, nil
+		case n.macro == "filter" && matched:
+			results = append(results, element)
+		case n.macro == "exists_one" && matched:
+			count++
+		}
+	}
+
+	switch n.macro {
+	case "all", "exists":
+		if firstErr != nil {
+			return nil, firstErr
+		}
+		return n.macro == "all", nil
+	case "exists_one":
+		return count == 1, nil
+	default:
+		if results == nil {
+			results = []interface{}{}
+		}
+		return results, nil
+	}
+}
+
+// celValue converts a decoded YAML value to the types the evaluator uses:
+// int64 for integers and float64 for floating-point numbers
+func celValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case int:
+		return int64(value)
+	case int32:
+		return int64(value)
+	case uint64:
+		return int64(value)
+	case float32:
+		return float64(value)
+	default:
+		return value
+	}
+}
+
+// celTypeName names the CEL type of a value for error messages
+func celTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "bool"
+	case int64:
+		return "int"
+	case float64:
+		return "double"
+	case string:
+		return "string"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "map"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// celEqual compares two values; numbers compare by value across int and
+// double, and values of different types are not equal
+func celEqual(a, b interface{}) bool {
+	a, b = celValue(a), celValue(b)
+	switch a := a.(type) {
+	case nil:
+		return b == nil
+	case int64, float64, string, bool:
+		order, err := celCompare(a, b)
+		return err == nil && order == 0
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !celEqual(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for key, value := range a {
+			other, ok := b[key]
+			if !ok || !celEqual(value, other) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// celCompare orders two numbers, strings, or bools
+func celCompare(a, b interface{}) (int, error) {
+	switch a := a.(type) {
+	case int64:
+		switch b := b.(type) {
+		case int64:
+			switch {
+			case a < b:
+				return -1, nil
+			case a > b:
+				return 1, nil
+			}
+			return 0, nil
+		case float64:
+			return compareFloats(float64(a), b), nil
+		}
+	case float64:
+		switch b := b.(type) {
+		case int64:
+			return compareFloats(a, float64(b)), nil
+		case float64:
+			return compareFloats(a, b), nil
+		}
+	case string:
+		if b, ok := b.(string); ok {
+			return strings.Compare(a, b), nil
+		}
+	case bool:
+		if b, ok := b.(bool); ok {
+			switch {
+			case a == b:
+				return 0, nil
+			case !a:
+				return -1, nil
+			default:
+				return 1, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("cannot compare %s and %s", celTypeName(a), celTypeName(b))
+}
+
+// compareFloats returns -1, 0, or 1 as a is less than, equal to, or
+// greater than b
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// celIn reports whether a value is an element of a list or a key of a map
+func celIn(value, container interface{}) (interface{}, error) {
+	switch container := container.(type) {
+	case []interface{}:
+		for _, element := range container {
+			if celEqual(value, element) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case map[string]interface{}:
+		key, ok := value.(string)
+		if !ok {
+			return false, nil
+		}
+		_, ok = container[key]
+		return ok, nil
+	default:
+		return nil, fmt.Errorf("no such overload: %s in %s", celTypeName(value), celTypeName(container))
+	}
+}
+
+// celArithmetic applies +, -, *, /, and % to two ints or two doubles; + also
+// concatenates strings and lists
+func celArithmetic(op string, left, right interface{}) (interface{}, error) {
+	switch l := left.(type) {
+	case int64:
+		if r, ok := right.(int64); ok {
+			switch op {
+			case "+":
+				return l + r, nil
+			case "-":
+				return l - r, nil
+			case "*":
+				return l * r, nil
+			case "/", "%":
+				if r == 0 {
+					return nil, fmt.Errorf("division by zero")
+				}
+				if op == "/" {
+					return l / r, nil
+				}
+				return l % r, nil
+			}
+		}
+	case float64:
+		if r, ok := right.(float64); ok {
+			switch op {
+			case "+":
+				return l + r, nil
+			case "-":
+				return l - r, nil
+			case "*":
+				return l * r, nil
+			case "/":
+				return l / r, nil
+			}
+		}
+	case string:
+		if r, ok := right.(string); ok && op == "+" {
+			return l + r, nil
+		}
+	case []interface{}:
+		if r, ok := right.([]interface{}); ok && op == "+" {
+			return append(append([]interface{}{}, l...), r...), nil
+		}
+	}
+	return nil, fmt.Errorf("no such overload: %s %s %s", celTypeName(left), op, celTypeName(right))
+}
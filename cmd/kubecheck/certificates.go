@@ -0,0 +1,86 @@
+package main
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tlsSecretType is the Secret type that holds a certificate and its key
+const tlsSecretType = "kubernetes.io/tls"
+
+// tlsCertificates returns the certificates PEM-encoded in a TLS Secret's tls.crt
+// ok is false for other Secrets, or when tls.crt is absent or not valid base64;
+// err reports a tls.crt that is not a PEM certificate chain
+func tlsCertificates(resource K8sResource) (certs []*x509.Certificate, ok bool, err error) {
+	if resource.Kind != "Secret" || getStringValue(resource.Object, "type") != tlsSecretType {
+		return nil, false, nil
+	}
+
+	var data []byte
+	for _, entry := range parseSecretEntries(resource) {
+		if entry.Key == "tls.crt" && !entry.Invalid {
+			data = entry.Value
+		}
+	}
+	if data == nil {
+		return nil, false, nil
+	}
+
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return nil, true, fmt.Errorf("unexpected %s block", block.Type)
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, true, fmt.Errorf("failed to parse certificate: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, true, fmt.Errorf("no PEM certificate found")
+	}
+	return certs, true, nil
+}
+
+// tlsCertInvalid flags TLS Secrets whose tls.crt is not a PEM certificate chain
+func tlsCertInvalid(resource K8sResource) (bool, string) {
+	_, ok, err := tlsCertificates(resource)
+	if !ok || err == nil {
+		return false, ""
+	}
+	return true, err.Error()
+}
+
+// tlsCertExpiresBefore flags TLS Secrets holding a certificate whose notAfter is
+// before the deadline; the details give the subject CN and the date
+func tlsCertExpiresBefore(resource K8sResource, deadline time.Time) (bool, string) {
+	certs, ok, err := tlsCertificates(resource)
+	if !ok || err != nil {
+		return false, ""
+	}
+
+	for _, cert := range certs {
+		if cert.NotAfter.Before(deadline) {
+			return true, fmt.Sprintf("CN=%s, notAfter %s", cert.Subject.CommonName, cert.NotAfter.UTC().Format("2006-01-02"))
+		}
+	}
+	return false, ""
+}
+
+// parseDays parses a window such as "30" or "30d" as a number of days
+func parseDays(value string) (time.Duration, error) {
+	days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+	if err != nil || days < 0 {
+		return 0, fmt.Errorf("invalid number of days %q", value)
+	}
+	return time.Duration(days) * 24 * time.Hour, nil
+}
@@ -0,0 +1,624 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Condition is a rule condition parsed once when the config is loaded. Config
+// files write it as "type:value", as "type(value, key=value, ...)", or as a
+// mapping such as {type: replicas_less_than, value: 2}
+type Condition struct {
+	Type string
+	// Value is the condition's argument, with "$name" vars resolved; several
+	// positional arguments are joined with ","
+	Value string
+	// Args holds the named arguments other than value
+	Args map[string]string
+
+	// The fields below hold Value parsed by parseArgs when the config is
+	// loaded; which of them a condition uses depends on its type
+
+	// number is an integer threshold, or a percentage without its "%"
+	number int
+	// low and high bound a LOW-HIGH percentage range
+	low, high int
+	// factor is a decimal number
+	factor float64
+	// quantity is a Kubernetes quantity such as 500m or 1Gi
+	quantity float64
+	// window is a number of days
+	window time.Duration
+	// list holds the comma-separated items, trimmed, without empty ones
+	list []string
+	// key and operand are the two sides of a KEY=VALUE value; hasOperand is
+	// false when the "=VALUE" part is left out
+	key        string
+	operand    string
+	hasOperand bool
+	// path is the field path of the field_* conditions
+	path []fieldPathStep
+	// pattern is the regular expression of the condition
+	pattern *regexp.Regexp
+}
+
+// ConditionList holds a rule's conditions as written. Mapping entries are
+// turned into the "type(value, key=value)" form when the config is read
+type ConditionList []string
+
+// UnmarshalYAML accepts each condition as a string or as a mapping
+func (l *ConditionList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.SequenceNode {
+		return fmt.Errorf("line %d: conditions must be a list", node.Line)
+	}
+
+	list := make(ConditionList, 0, len(node.Content))
+	for _, item := range node.Content {
+		switch item.Kind {
+		case yaml.ScalarNode:
+			list = append(list, item.Value)
+		case yaml.MappingNode:
+			condition, err := conditionFromMapping(item)
+			if err != nil {
+				return err
+			}
+			list = append(list, condition)
+		default:
+			return fmt.Errorf("line %d: a condition must be a string or a mapping with a type", item.Line)
+		}
+	}
+	*l = list
+	return nil
+}
+
+// conditionFromMapping writes a {type: ..., value: ..., key: ...} condition
+// in the call form, quoting every argument so any value survives the trip
+func conditionFromMapping(node *yaml.Node) (string, error) {
+	var conditionType string
+	var args []string
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i].Value, node.Content[i+1]
+
+		var text string
+		switch value.Kind {
+		case yaml.ScalarNode:
+			text = value.Value
+		case yaml.SequenceNode:
+			var items []string
+			for _, v := range value.Content {
+				items = append(items, v.Value)
+			}
+			text = strings.Join(items, ",")
+		default:
+			return "", fmt.Errorf("line %d: condition argument %q must be a string or a list", value.Line, key)
+		}
+
+		if key == "type" {
+			conditionType = text
+			continue
+		}
+		args = append(args, key+"="+strconv.Quote(text))
+	}
+	if conditionType == "" {
+		return "", fmt.Errorf("line %d: condition mapping has no type", node.Line)
+	}
+	return conditionType + "(" + strings.Join(args, ", ") + ")", nil
+}
+
+// conditionCallPattern matches the "type(arguments)" form
+var conditionCallPattern = regexp.MustCompile(`(?s)^([a-z0-9_]+)\((.*)\)$`)
+
+// conditionArgNamePattern matches the name of a named argument
+var conditionArgNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// conditionKeyValueArgs lists the conditions whose "KEY=VALUE" value can also
+// be given as two named arguments, e.g. annotation_not_matching(key=team, pattern="^team-")
+var conditionKeyValueArgs = map[string][2]string{
+	"annotation_not_matching": {"key", "pattern"},
+	"node_selector_contains":  {"key", "value"},
+	"field_equals":            {"path", "value"},
+	"field_not_equals":        {"path", "value"},
+	"field_matches":           {"path", "pattern"},
+	"field_not_matches":       {"path", "pattern"},
+}
+
+// conditionKeyValueSeparators lists the KEY=VALUE conditions that separate
+// key and value with something other than "="
+var conditionKeyValueSeparators = map[string]string{
+	"field_matches":     "~",
+	"field_not_matches": "~",
+}
+
+// parseCondition parses a condition in the "type:value" or "type(...)" form
+// Only the first ":" separates type and value, so values may contain colons
+// (registry ports, digests)
+func parseCondition(text string) (Condition, error) {
+	match := conditionCallPattern.FindStringSubmatch(strings.TrimSpace(text))
+	if match == nil {
+		conditionType, value, _ := strings.Cut(text, ":")
+		return Condition{Type: conditionType, Value: value}, nil
+	}
+
+	condition := Condition{Type: match[1]}
+	args, err := splitConditionArgs(match[2])
+	if err != nil {
+		return Condition{}, err
+	}
+
+	var positional []string
+	for _, arg := range args {
+		name, value, named := strings.Cut(arg, "=")
+		if !named || !conditionArgNamePattern.MatchString(strings.TrimSpace(name)) {
+			if positional, err = appendConditionArg(positional, arg); err != nil {
+				return Condition{}, err
+			}
+			continue
+		}
+
+		name = strings.TrimSpace(name)
+		if value, err = unquoteConditionArg(value); err != nil {
+			return Condition{}, fmt.Errorf("argument %s: %w", name, err)
+		}
+		// value= is the positional value, except for conditions that name
+		// their second argument value
+		if name == "value" && conditionKeyValueArgs[condition.Type][1] != "value" {
+			positional = append(positional, value)
+			continue
+		}
+		if condition.Args == nil {
+			condition.Args = make(map[string]string)
+		}
+		if _, ok := condition.Args[name]; ok {
+			return Condition{}, fmt.Errorf("argument %s is given twice", name)
+		}
+		condition.Args[name] = value
+	}
+	condition.Value = strings.Join(positional, ",")
+
+	if err := condition.applyKeyValueArgs(); err != nil {
+		return Condition{}, err
+	}
+	return condition, nil
+}
+
+// appendConditionArg unquotes a positional argument and appends it
+func appendConditionArg(positional []string, arg string) ([]string, error) {
+	value, err := unquoteConditionArg(arg)
+	if err != nil {
+		return nil, fmt.Errorf("argument %d: %w", len(positional)+1, err)
+	}
+	return append(positional, value), nil
+}
+
+// applyKeyValueArgs folds the named arguments of KEY=VALUE conditions into
+// Value, and rejects named arguments the condition does not take
+func (c *Condition) applyKeyValueArgs() error {
+	names, takesKeyValue := conditionKeyValueArgs[c.Type]
+	for name := range c.Args {
+		if !takesKeyValue || (name != names[0] && name != names[1]) {
+			return fmt.Errorf("%s takes no argument %q", c.Type, name)
+		}
+	}
+	if !takesKeyValue || len(c.Args) == 0 {
+		return nil
+	}
+	if c.Value != "" {
+		return fmt.Errorf("%s takes either a value or %s and %s, not both", c.Type, names[0], names[1])
+	}
+
+	key, ok := c.Args[names[0]]
+	if !ok {
+		return fmt.Errorf("%s needs the %s argument", c.Type, names[0])
+	}
+	c.Value = key
+	if value, ok := c.Args[names[1]]; ok {
+		separator, ok := conditionKeyValueSeparators[c.Type]
+		if !ok {
+			separator = "="
+		}
+		c.Value += separator + value
+	}
+	c.Args = nil
+	return nil
+}
+
+// splitConditionArgs splits call arguments on the commas outside quotes and
+// parentheses, so regular expressions such as (a|b) stay whole
+func splitConditionArgs(text string) ([]string, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+
+	var args []string
+	depth, start := 0, 0
+	inQuote, escaped := false, false
+	for i, r := range text {
+		switch {
+		case escaped:
+			escaped = false
+		case inQuote && r == '\\':
+			escaped = true
+		case r == '"':
+			inQuote = !inQuote
+		case inQuote:
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ',' && depth == 0:
+			args = append(args, strings.TrimSpace(text[start:i]))
+			start = i + 1
+		}
+	}
+	if inQuote {
+		return nil, fmt.Errorf("unterminated quoted argument")
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced parentheses in arguments")
+	}
+	return append(args, strings.TrimSpace(text[start:])), nil
+}
+
+// unquoteConditionArg returns an argument's text, unquoting a double-quoted
+// argument with Go escape rules
+func unquoteConditionArg(arg string) (string, error) {
+	arg = strings.TrimSpace(arg)
+	if !strings.HasPrefix(arg, `"`) {
+		if arg == "" {
+			return "", fmt.Errorf("empty argument")
+		}
+		return arg, nil
+	}
+	value, err := strconv.Unquote(arg)
+	if err != nil {
+		return "", fmt.Errorf("invalid quoted argument %s", arg)
+	}
+	return value, nil
+}
+
+// conditionArgParser parses the value of a condition into its typed fields
+type conditionArgParser struct {
+	parse func(c *Condition, value string) error
+	// required conditions reject an empty value
+	required bool
+	// fallback is parsed in place of an empty value
+	fallback string
+}
+
+// conditionArgParsers lists the conditions whose value is parsed when the
+// config is loaded; the rest compare it as text
+var conditionArgParsers = map[string]conditionArgParser{
+	"replicas_less_than":                            {parse: parseIntegerArg, required: true},
+	"revision_history_exceeds":                      {parse: parseIntegerArg, fallback: strconv.Itoa(defaultRevisionHistoryLimit)},
+	"termination_grace_period_exceeds":              {parse: parseIntegerArg, required: true},
+	"missing_startup_probe_with_high_initial_delay": {parse: parseIntegerArg, required: true},
+	"host_port_below":                               {parse: parseIntegerArg, required: true},
+	"port_below":                                    {parse: parseIntegerArg, required: true},
+	"daemonset_max_unavailable_exceeds":             {parse: parsePercentArg, required: true},
+	"hpa_cpu_target_out_of_range":                   {parse: parsePercentRangeArg, fallback: "10-100"},
+	"memory_limit_ratio_exceeds":                    {parse: parseNumberArg, required: true},
+	"tls_cert_expires_within":                       {parse: parseDaysArg, required: true},
+	"annotation_size_exceeds":                       {parse: parseQuantityArg, required: true},
+	"secret_size_exceeds":                           {parse: parseQuantityArg, required: true},
+	"configmap_size_exceeds":                        {parse: parseQuantityArg, required: true},
+	"emptydir_sizelimit_exceeds":                    {parse: parseQuantityArg, required: true},
+	"cpu_limit_exceeds":                             {parse: parseQuantityArg, required: true},
+	"field_missing":                                 {parse: parseFieldPathArg, required: true},
+	"field_equals":                                  {parse: parseFieldComparisonArg, required: true},
+	"field_not_equals":                              {parse: parseFieldComparisonArg, required: true},
+	"field_matches":                                 {parse: parseFieldPatternArg, required: true},
+	"field_not_matches":                             {parse: parseFieldPatternArg, required: true},
+	"image_tag_not_matching":                        {parse: parsePatternArg},
+	"container_name_not_matching":                   {parse: parsePatternArg},
+	"plaintext_secret_env":                          {parse: parsePatternArg, fallback: defaultSecretNamePattern},
+	"annotation_not_matching":                       {parse: parseKeyPatternArg, required: true},
+	"node_selector_contains":                        {parse: parseKeyValueArg},
+	"kind_in":                                       {parse: parseListArg},
+	"container_name_in":                             {parse: parseListArg},
+	"image_registry_not_in":                         {parse: parseListArg},
+	"private_image_without_pull_secret":             {parse: parseListArg},
+	"seccomp_profile_not_in":                        {parse: parseListArg},
+	"capabilities_added":                            {parse: parseListArg},
+	"capabilities_added_not_in":                     {parse: parseListArg},
+	"mount_not_readonly":                            {parse: parseListArg},
+	"priority_class_equals":                         {parse: parseListArg},
+	"storage_class_not_in":                          {parse: parseListArg},
+	"hpa_min_replicas_one":                          {parse: parseListArg},
+	"binding_cluster_admin":                         {parse: parseListArg},
+	"missing_label":                                 {parse: parseListArg},
+	"missing_annotation":                            {parse: parseListArg},
+	"sysctl_not_in":                                 {parse: parseListArg, fallback: safeSysctls},
+	"volume_source_in":                              {parse: parseListArg},
+	"volume_source_not_in":                          {parse: parseListArg},
+}
+
+// parseArgs parses the condition's value into the typed fields its type
+// evaluates, so a value that cannot be used fails the load
+func (c *Condition) parseArgs() error {
+	parser, ok := conditionArgParsers[c.Type]
+	if !ok {
+		return nil
+	}
+	value := c.Value
+	if value == "" {
+		if parser.required {
+			return fmt.Errorf("%s needs a value", c.Type)
+		}
+		value = parser.fallback
+	}
+	if err := parser.parse(c, value); err != nil {
+		return fmt.Errorf("%s: %w", c.Type, err)
+	}
+	return nil
+}
+
+// parseIntegerArg accepts whole numbers
+func parseIntegerArg(c *Condition, value string) error {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("value %q is not an integer", value)
+	}
+	c.number = n
+	return nil
+}
+
+// parseNumberArg accepts decimal numbers
+func parseNumberArg(c *Condition, value string) error {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("value %q is not a number", value)
+	}
+	c.factor = f
+	return nil
+}
+
+// parsePercentArg accepts whole numbers with an optional "%"
+func parsePercentArg(c *Condition, value string) error {
+	n, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+	if err != nil {
+		return fmt.Errorf("value %q is not an integer or percentage", value)
+	}
+	c.number = n
+	return nil
+}
+
+// parsePercentRangeArg accepts a LOW-HIGH percentage range
+func parsePercentRangeArg(c *Condition, value string) error {
+	var err error
+	c.low, c.high, err = parsePercentRange(value)
+	return err
+}
+
+// parseDaysArg accepts a number of days such as 30 or 30d
+func parseDaysArg(c *Condition, value string) error {
+	var err error
+	c.window, err = parseDays(value)
+	return err
+}
+
+// parseQuantityArg accepts Kubernetes quantities such as 500m or 1Gi
+func parseQuantityArg(c *Condition, value string) error {
+	var err error
+	c.quantity, err = ParseQuantity(value)
+	return err
+}
+
+// parsePatternArg compiles a regular expression
+func parsePatternArg(c *Condition, value string) error {
+	pattern, err := regexp.Compile(value)
+	if err != nil {
+		return fmt.Errorf("invalid regex %q: %w", value, err)
+	}
+	c.pattern = pattern
+	return nil
+}
+
+// parseListArg splits a comma-separated list
+func parseListArg(c *Condition, value string) error {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			c.list = append(c.list, item)
+		}
+	}
+	return nil
+}
+
+// parseKeyValueArg accepts KEY or KEY=VALUE
+func parseKeyValueArg(c *Condition, value string) error {
+	c.key, c.operand, c.hasOperand = strings.Cut(value, "=")
+	return nil
+}
+
+// parseKeyPatternArg accepts KEY=REGEX
+func parseKeyPatternArg(c *Condition, value string) error {
+	key, pattern, ok := strings.Cut(value, "=")
+	if !ok {
+		return fmt.Errorf("value %q must be KEY=REGEX", value)
+	}
+	c.key = key
+	return parsePatternArg(c, pattern)
+}
+
+// conditionLayer is where a condition type is evaluated
+type conditionLayer int
+
+const (
+	// containerLayer conditions are checked once per container
+	containerLayer conditionLayer = iota + 1
+	// resourceLayer conditions are checked once per resource
+	resourceLayer
+	// bundleLayer conditions are checked against the whole scan
+	bundleLayer
+)
+
+// conditionLayers lists every condition type by layer, in the order of the
+// switches in checkCondition, checkResourceCondition, and checkBundleCondition
+var conditionLayers = map[string]conditionLayer{
+	"image_tag_equals":                              containerLayer,
+	"image_tag_missing":                             containerLayer,
+	"image_digest_missing":                          containerLayer,
+	"image_digest_present":                          containerLayer,
+	"image_not_fully_qualified":                     containerLayer,
+	"image_tag_not_matching":                        containerLayer,
+	"container_name_not_matching":                   containerLayer,
+	"container_name_in":                             containerLayer,
+	"image_registry_not_in":                         containerLayer,
+	"private_image_without_pull_secret":             containerLayer,
+	"cpu_request_exceeds_limit":                     containerLayer,
+	"memory_request_exceeds_limit":                  containerLayer,
+	"invalid_quantity":                              containerLayer,
+	"cpu_limit_set":                                 containerLayer,
+	"cpu_limit_exceeds":                             containerLayer,
+	"memory_limit_ratio_exceeds":                    containerLayer,
+	"missing_cpu_requests":                          containerLayer,
+	"missing_memory_requests":                       containerLayer,
+	"missing_cpu_limits":                            containerLayer,
+	"missing_memory_limits":                         containerLayer,
+	"missing_security_context":                      containerLayer,
+	"run_as_non_root_false":                         containerLayer,
+	"run_as_user_zero":                              containerLayer,
+	"run_as_non_root_not_true":                      containerLayer,
+	"allow_privilege_escalation_not_false":          containerLayer,
+	"seccomp_profile_equals":                        containerLayer,
+	"seccomp_profile_not_in":                        containerLayer,
+	"apparmor_profile_equals":                       containerLayer,
+	"selinux_options_unsafe":                        containerLayer,
+	"host_process_true":                             containerLayer,
+	"missing_liveness_probe":                        containerLayer,
+	"missing_readiness_probe":                       containerLayer,
+	"missing_startup_probe_with_high_initial_delay": containerLayer,
+	"liveness_equals_readiness":                     containerLayer,
+	"plaintext_secret_env":                          containerLayer,
+	"secret_env_exposure":                           containerLayer,
+	"privileged_true":                               containerLayer,
+	"missing_image_pull_policy":                     containerLayer,
+	"image_pull_policy_missing":                     containerLayer,
+	"image_pull_policy_equals":                      containerLayer,
+	"image_pull_policy_always_with_digest":          containerLayer,
+	"image_pull_policy_stale_latest":                containerLayer,
+	"ephemeral_container":                           containerLayer,
+	"capabilities_not_dropped_all":                  containerLayer,
+	"capabilities_added":                            containerLayer,
+	"capabilities_added_not_in":                     containerLayer,
+	"host_port_set":                                 containerLayer,
+	"host_port_below":                               containerLayer,
+	"proc_mount_unmasked":                           containerLayer,
+	"mount_not_readonly":                            containerLayer,
+	"secret_volume_not_readonly":                    containerLayer,
+	"missing_prestop_hook":                          containerLayer,
+	"port_name_missing":                             containerLayer,
+	"port_below":                                    containerLayer,
+	"duplicate_container_port":                      containerLayer,
+	"read_only_root_filesystem_not_true":            containerLayer,
+	"replicas_less_than":                            resourceLayer,
+	"api_version_removed":                           resourceLayer,
+	"api_version_deprecated":                        resourceLayer,
+	"security_annotation_removed":                   resourceLayer,
+	"security_annotation_deprecated":                resourceLayer,
+	"job_restart_policy_invalid":                    resourceLayer,
+	"job_backoff_limit_missing":                     resourceLayer,
+	"job_active_deadline_missing":                   resourceLayer,
+	"invalid_cron_schedule":                         resourceLayer,
+	"cron_schedule_every_minute":                    resourceLayer,
+	"concurrency_policy_missing_or_allow":           resourceLayer,
+	"history_limit_missing":                         resourceLayer,
+	"starting_deadline_missing":                     resourceLayer,
+	"kind_in":                                       resourceLayer,
+	"metadata_name_invalid":                         resourceLayer,
+	"label_syntax_invalid":                          resourceLayer,
+	"annotation_key_invalid":                        resourceLayer,
+	"annotation_size_exceeds":                       resourceLayer,
+	"namespace_missing":                             resourceLayer,
+	"namespace_equals":                              resourceLayer,
+	"rbac_wildcard_verb":                            resourceLayer,
+	"rbac_wildcard_resource":                        resourceLayer,
+	"rbac_wildcard_apigroup":                        resourceLayer,
+	"rbac_secrets_read":                             resourceLayer,
+	"service_type_equals":                           resourceLayer,
+	"service_external_traffic_policy_missing":       resourceLayer,
+	"ingress_tls_missing":                           resourceLayer,
+	"ingress_host_wildcard":                         resourceLayer,
+	"ingress_default_backend_only":                  resourceLayer,
+	"ingress_class_missing":                         resourceLayer,
+	"ingress_legacy_class_annotation":               resourceLayer,
+	"gateway_listener_tls_missing":                  resourceLayer,
+	"gateway_route_parent_refs_missing":             resourceLayer,
+	"gateway_host_wildcard":                         resourceLayer,
+	"secret_data_credential":                        resourceLayer,
+	"secret_data_invalid_base64":                    resourceLayer,
+	"secret_size_exceeds":                           resourceLayer,
+	"selector_not_matching_template":                resourceLayer,
+	"selector_match_expressions":                    resourceLayer,
+	"revision_history_exceeds":                      resourceLayer,
+	"daemonset_on_delete_strategy":                  resourceLayer,
+	"daemonset_max_unavailable_exceeds":             resourceLayer,
+	"statefulset_service_name_missing":              resourceLayer,
+	"volume_claim_template_storage_class_missing":   resourceLayer,
+	"pvc_storage_request_missing":                   resourceLayer,
+	"pvc_storage_request_invalid":                   resourceLayer,
+	"storage_class_not_in":                          resourceLayer,
+	"hpa_replica_range_invalid":                     resourceLayer,
+	"hpa_min_replicas_one":                          resourceLayer,
+	"hpa_metrics_missing":                           resourceLayer,
+	"hpa_cpu_target_out_of_range":                   resourceLayer,
+	"configmap_size_exceeds":                        resourceLayer,
+	"configmap_credential":                          resourceLayer,
+	"configmap_invalid_key":                         resourceLayer,
+	"tls_cert_invalid":                              resourceLayer,
+	"tls_cert_expired":                              resourceLayer,
+	"tls_cert_expires_within":                       resourceLayer,
+	"binding_cluster_admin":                         resourceLayer,
+	"binding_system_subject":                        resourceLayer,
+	"missing_label":                                 resourceLayer,
+	"missing_annotation":                            resourceLayer,
+	"annotation_not_matching":                       resourceLayer,
+	"field_missing":                                 resourceLayer,
+	"field_equals":                                  resourceLayer,
+	"field_not_equals":                              resourceLayer,
+	"field_matches":                                 resourceLayer,
+	"field_not_matches":                             resourceLayer,
+	"host_network_true":                             resourceLayer,
+	"host_pid_true":                                 resourceLayer,
+	"host_ipc_true":                                 resourceLayer,
+	"host_network_without_cluster_first_dns":        resourceLayer,
+	"dns_policy_equals":                             resourceLayer,
+	"missing_spread_constraints":                    resourceLayer,
+	"share_process_namespace_true":                  resourceLayer,
+	"sysctl_not_in":                                 resourceLayer,
+	"tolerates_taint":                               resourceLayer,
+	"toleration_operator_exists_all":                resourceLayer,
+	"node_selector_contains":                        resourceLayer,
+	"priority_class_missing":                        resourceLayer,
+	"priority_class_equals":                         resourceLayer,
+	"termination_grace_period_zero":                 resourceLayer,
+	"termination_grace_period_exceeds":              resourceLayer,
+	"service_account_default":                       resourceLayer,
+	"emptydir_missing_sizelimit":                    resourceLayer,
+	"emptydir_memory_missing_sizelimit":             resourceLayer,
+	"emptydir_sizelimit_exceeds":                    resourceLayer,
+	"duplicate_container_name":                      resourceLayer,
+	"conflicting_host_port":                         resourceLayer,
+	"volume_mount_undefined":                        resourceLayer,
+	"volume_unused":                                 resourceLayer,
+	"volume_source_in":                              resourceLayer,
+	"volume_source_not_in":                          resourceLayer,
+	"duplicate_resource":                            bundleLayer,
+	"config_reference_missing":                      bundleLayer,
+	"service_account_missing":                       bundleLayer,
+	"crd_schema_violation":                          bundleLayer,
+	"missing_pdb":                                   bundleLayer,
+	"pdb_selector_unmatched":                        bundleLayer,
+	"pdb_blocks_eviction":                           bundleLayer,
+	"service_selector_unmatched":                    bundleLayer,
+	"gateway_backend_missing":                       bundleLayer,
+	"namespace_missing_default_deny":                bundleLayer,
+	"pvc_rwo_shared":                                bundleLayer,
+	"statefulset_service_not_headless":              bundleLayer,
+	"hpa_target_missing":                            bundleLayer,
+	"replicas_set_with_hpa":                         bundleLayer,
+}
@@ -3,117 +3,1714 @@ package main
 import (
 	"fmt"
 	"os"
-
-	"gopkg.in/yaml.v3"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
 )
 
 // RuleConfig represents the configuration file structure
 type RuleConfig struct {
-	Rules []Rule `yaml:"rules"`
+	// Extends names a base config, "default" for the built-in rules or a file
+	// path relative to this one, that Rules add to and override
+	Extends string `yaml:"extends,omitempty"`
+	Rules   []Rule `yaml:"rules"`
+	// Vars holds named lists that condition values reference as "$name"
+	Vars map[string][]string `yaml:"vars,omitempty"`
+	// ClusterScopedKinds adds kinds, such as cluster-scoped CRDs, to the built-in list
+	ClusterScopedKinds []string `yaml:"clusterScopedKinds,omitempty"`
+	// NoDefaultStorageClass marks the target clusters as having no default
+	// StorageClass, so claims must name one
+	NoDefaultStorageClass bool `yaml:"noDefaultStorageClass,omitempty"`
+	// AssumeCompleteBundle declares that a scan holds every object the manifests
+	// reference, so missing ConfigMaps, Secrets, and ServiceAccounts are reported
+	AssumeCompleteBundle bool `yaml:"assumeCompleteBundle,omitempty"`
+	// Profiles activates built-in rule bundles, such as pss-baseline, alongside Rules
+	Profiles []string `yaml:"profiles,omitempty"`
+	// DisabledRules turns off rules by name, including profile rules
+	DisabledRules []string `yaml:"disabledRules,omitempty"`
+	// PodSpecPaths maps custom kinds, or "apiVersion/Kind" when a kind name is
+	// shared, to the dotted path of their pod spec, e.g. Rollout: spec.template.spec
+	PodSpecPaths map[string]string `yaml:"podSpecPaths,omitempty"`
+	// RegoPolicies lists .rego files and directories, relative to this config,
+	// whose deny and warn rules run against every resource
+	RegoPolicies []string `yaml:"regoPolicies,omitempty"`
+	// ExitCodes remaps the exit code that findings of each severity produce,
+	// e.g. {ERROR: 3, WARN: 0}; --fail-on and --exit-zero take precedence
+	ExitCodes map[string]int `yaml:"exitCodes,omitempty"`
+
+	// exitCodePositions maps the severities of exitCodes to the file:line
+	// that last set them
+	exitCodePositions map[string]string
+
+	// problems are the decoding problems of the files merged into the config,
+	// reported by Validate
+	problems configProblems
+
+	// deselectedRules are the rules --tags and --skip-tags left out; ignore
+	// annotations may still name them
+	deselectedRules []string
+}
+
+// clusterScopedKinds lists the built-in kinds that have no namespace, plus
+// GatewayClass, whose Gateway API group is installed as CRDs
+var clusterScopedKinds = func() map[string]bool {
+	kinds := map[string]bool{"GatewayClass": true}
+	for _, groupKinds := range builtInAPIGroups {
+		for _, kind := range groupKinds {
+			kinds[kind] = true
+		}
+	}
+	return kinds
+}()
+
+// IsClusterScoped reports whether resources of the given kind have no namespace
+func (c *RuleConfig) IsClusterScoped(kind string) bool {
+	if clusterScopedKinds[kind] {
+		return true
+	}
+	for _, k := range c.ClusterScopedKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
 }
 
 // Rule represents a single validation rule
 type Rule struct {
-	Name        string   `yaml:"name"`
-	Description string   `yaml:"description"`
-	Severity    string   `yaml:"severity"` // ERROR or WARN
-	Type        string   `yaml:"type"`     // image, resources, security, etc.
-	Conditions  []string `yaml:"conditions"`
-	Message     string   `yaml:"message"`
-	Help        string   `yaml:"help,omitempty"`
-}
-
-// LoadRuleConfig loads rules from a YAML file
-func LoadRuleConfig(filepath string) (*RuleConfig, error) {
-	data, err := os.ReadFile(filepath)
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Severity    string `yaml:"severity"` // ERROR or WARN
+	Type        string `yaml:"type"`     // image, resources, security, etc.
+	// Tags group rules for --tags and --skip-tags, such as security or cost
+	Tags       []string      `yaml:"tags,omitempty"`
+	Conditions ConditionList `yaml:"conditions"`
+	// When combines conditions with all, any, and not; when set, it is
+	// evaluated instead of Conditions
+	When *ConditionExpr `yaml:"when,omitempty"`
+	// Expression is the CEL program of a rule of type cel; the resource is a
+	// violation when it returns true
+	Expression string   `yaml:"expression,omitempty"`
+	Message    string   `yaml:"message"`
+	Help       string   `yaml:"help,omitempty"`
+	AppliesTo  []string `yaml:"appliesTo,omitempty"` // container, initContainer, ephemeralContainer; empty means all
+	// Kinds restricts the rule to these resource kinds, each a kind or an
+	// "apiVersion/Kind" such as apps/v1/Deployment; empty means all
+	Kinds []string `yaml:"kinds,omitempty"`
+	// ExcludeKinds skips the rule for these resource kinds, written as in Kinds
+	ExcludeKinds []string `yaml:"excludeKinds,omitempty"`
+	// Namespaces restricts the rule to resources in namespaces matching these
+	// globs, e.g. prod-*; cluster-scoped resources never match
+	Namespaces []string `yaml:"namespaces,omitempty"`
+	// ExcludeNamespaces skips the rule for resources in namespaces matching
+	// these globs, such as kube-system
+	ExcludeNamespaces []string `yaml:"excludeNamespaces,omitempty"`
+	// Selector restricts the rule to resources whose labels, or pod template
+	// labels, it selects
+	Selector *LabelSelector `yaml:"selector,omitempty"`
+	// ExcludeContainers skips containers whose name matches one of these globs,
+	// such as injected sidecars like istio-proxy
+	ExcludeContainers []string `yaml:"excludeContainers,omitempty"`
+	// ExcludeImages skips containers whose image matches one of these globs,
+	// e.g. gcr.io/istio-*; "*" also matches "/"
+	ExcludeImages []string `yaml:"excludeImages,omitempty"`
+	// ExcludePaths skips the rule for files whose path, or a parent directory, matches a glob
+	ExcludePaths []string `yaml:"excludePaths,omitempty"`
+	// AllowEnv lists env var names the rule's conditions never see
+	AllowEnv []string `yaml:"allowEnv,omitempty"`
+	// Enabled: false turns the rule off while keeping it in the config; nil means enabled
+	Enabled *bool `yaml:"enabled,omitempty"`
+	// Tests are manifests that `kubecheck config validate` runs the rule
+	// against, each expected to produce a violation or to pass
+	Tests []RuleTest `yaml:"tests,omitempty"`
+	// Profile is set on rules that come from a built-in profile
+	Profile string `yaml:"-"`
+	// Source is the config file that last defined or changed the rule; empty
+	// for built-in rules
+	Source string `yaml:"-"`
+	// positions maps the keys of the rule, and "" for the rule itself, to the
+	// file:line that set them, for validation errors
+	positions map[string]string
+
+	// parsed holds Conditions as parsed by the rule engine
+	parsed []Condition
+	// program is Expression as compiled by the rule engine
+	program *celProgram
+}
+
+// RuleEnabled reports whether a rule runs: neither set to enabled: false nor
+// listed under disabledRules
+func (c *RuleConfig) RuleEnabled(rule Rule) bool {
+	if rule.Enabled != nil && !*rule.Enabled {
+		return false
+	}
+	return !containsString(c.DisabledRules, rule.Name)
+}
+
+// AppliesToKind reports whether the rule evaluates resources of the given
+// apiVersion and kind
+func (r Rule) AppliesToKind(apiVersion, kind string) bool {
+	for _, k := range r.ExcludeKinds {
+		if kindMatches(k, apiVersion, kind) {
+			return false
+		}
+	}
+	if len(r.Kinds) == 0 {
+		return true
+	}
+	for _, k := range r.Kinds {
+		if kindMatches(k, apiVersion, kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// kindMatches reports whether a kinds entry names the resource: a bare kind
+// matches it in any API group, and "apiVersion/Kind" only in that apiVersion
+func kindMatches(entry, apiVersion, kind string) bool {
+	if i := strings.LastIndex(entry, "/"); i >= 0 {
+		return entry[:i] == apiVersion && entry[i+1:] == kind
+	}
+	return entry == kind
+}
+
+// AppliesToScope reports whether the resource falls under the rule's
+// namespaces, excluded namespaces, and selector. Namespaced resources without
+// metadata.namespace count as in "default"
+func (r Rule) AppliesToScope(resource K8sResource, clusterScoped bool, specPaths map[string]string) bool {
+	if len(r.Namespaces) > 0 {
+		if clusterScoped {
+			return false
+		}
+		namespace := getResourceNamespace(resource)
+		matched := false
+		for _, pattern := range r.Namespaces {
+			if ok, _ := path.Match(pattern, namespace); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(r.ExcludeNamespaces) > 0 && !clusterScoped {
+		namespace := getResourceNamespace(resource)
+		for _, pattern := range r.ExcludeNamespaces {
+			if ok, _ := path.Match(pattern, namespace); ok {
+				return false
+			}
+		}
+	}
+
+	if r.Selector != nil && !r.Selector.Matches(getStringMap(resource.Metadata, "labels")) {
+		templateLabels := podTemplateLabels(resource, specPaths)
+		if templateLabels == nil || !r.Selector.Matches(templateLabels) {
+			return false
+		}
+	}
+	return true
+}
+
+// AppliesToPath reports whether the rule evaluates resources read from the given file
+// A pattern matches the path as scanned or any of its parent directories, so
+// overlays/* excludes everything under overlays/staging
+func (r Rule) AppliesToPath(path string) bool {
+	if path == "" {
+		return true
+	}
+	for _, pattern := range r.ExcludePaths {
+		for p := filepath.Clean(path); p != "." && p != string(filepath.Separator); p = filepath.Dir(p) {
+			if matched, _ := filepath.Match(filepath.Clean(pattern), p); matched {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// globMatch matches a value against a glob in which "*" matches any run of
+// characters, including "/", and "?" any single character
+func globMatch(pattern, value string) bool {
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.ReplaceAll(expr, `\*`, ".*")
+	expr = strings.ReplaceAll(expr, `\?`, ".")
+	matched, _ := regexp.MatchString("^"+expr+"$", value)
+	return matched
+}
+
+// AppliesToOrigin reports whether the rule evaluates containers of the given origin
+func (r Rule) AppliesToOrigin(origin string) bool {
+	if len(r.AppliesTo) == 0 {
+		return true
+	}
+	for _, o := range r.AppliesTo {
+		if o == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultExtends is the extends value that names the built-in rules
+const defaultExtends = "default"
+
+// LoadRuleConfig loads rules from YAML files, directories of them, and
+// HTTPS URLs, each merged onto the configs it extends and then onto the ones
+// before it, so later definitions of a rule override earlier ones
+func LoadRuleConfig(paths []string, options ConfigLoadOptions) (*RuleConfig, error) {
+	files, err := ConfigFiles(paths)
+	if err != nil {
+		return nil, err
+	}
+	if options.SHA256 != "" && (len(files) != 1 || files[0] != paths[0]) {
+		return nil, fmt.Errorf("--config-sha256 pins a single config file, not %s", strings.Join(paths, ", "))
+	}
+
+	config := &RuleConfig{}
+	loaded := make(map[string]bool)
+	for _, file := range files {
+		key, err := configKey(file)
+		if err != nil {
+			return nil, err
+		}
+		// Skip fragments that an earlier fragment already extended
+		if loaded[key] {
+			continue
+		}
+		if config, err = loadConfigLayer(file, options, nil, loaded, config); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file: %w", err)
+	}
+
+	return config, nil
+}
+
+// ConfigFiles expands config paths into the files to load, in order: a
+// directory stands for the .yaml and .yml files directly inside it, in
+// lexical order, and URLs are kept as they are
+func ConfigFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		if isConfigURL(path) {
+			files = append(files, path)
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil || !info.IsDir() {
+			// A missing file is reported when it is read
+			files = append(files, path)
+			continue
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config directory: %w", err)
+		}
+		var fragments []string
+		for _, entry := range entries {
+			if ext := filepath.Ext(entry.Name()); !entry.IsDir() && (ext == ".yaml" || ext == ".yml") {
+				fragments = append(fragments, filepath.Join(path, entry.Name()))
+			}
+		}
+		if len(fragments) == 0 {
+			return nil, fmt.Errorf("config directory %s holds no .yaml files", path)
+		}
+		files = append(files, fragments...)
+	}
+	return files, nil
+}
+
+// configKey identifies a config across the ways it can be named: the
+// absolute path of a file, or a URL as written
+func configKey(path string) (string, error) {
+	if isConfigURL(path) || path == defaultExtends {
+		return path, nil
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve config path: %w", err)
+	}
+	return absPath, nil
+}
+
+// loadConfigLayer reads one config file or URL and merges it onto the
+// config it extends. chain holds the configs whose extends led here, to
+// detect cycles, and loaded those already merged by another fragment,
+// including the built-in rules, which are not merged twice. under is the
+// config earlier fragments built, if any: it goes between the base and the
+// fragment, so a later fragment's extends cannot undo what they changed
+func loadConfigLayer(path string, options ConfigLoadOptions, chain []string, loaded map[string]bool, under *RuleConfig) (*RuleConfig, error) {
+	absPath, err := configKey(path)
+	if err != nil {
+		return nil, err
+	}
+	if containsString(chain, absPath) {
+		return nil, fmt.Errorf("circular extends: %s", strings.Join(append(chain, absPath), " -> "))
+	}
+
+	data, err := readConfig(path, options)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := decodeConfig(path, data)
+	if err != nil {
+		return nil, err
+	}
+	if isConfigURL(path) && len(config.RegoPolicies) > 0 {
+		return nil, fmt.Errorf("config %s: regoPolicies must be local files, not listed in a config loaded from a URL", path)
+	}
+	for i, policy := range config.RegoPolicies {
+		if !filepath.IsAbs(policy) {
+			config.RegoPolicies[i] = filepath.Join(filepath.Dir(path), policy)
+		}
+	}
+	if !isConfigURL(path) {
+		for _, rule := range config.Rules {
+			for i, test := range rule.Tests {
+				if test.Manifest != "" && !filepath.IsAbs(test.Manifest) {
+					rule.Tests[i].Manifest = filepath.Join(filepath.Dir(path), test.Manifest)
+				}
+			}
+		}
+	}
+
+	defer func() { loaded[absPath] = true }()
+
+	base := &RuleConfig{}
+	switch extends := config.Extends; {
+	case extends == "":
+	case extends == defaultExtends:
+		if !loaded[defaultExtends] {
+			loaded[defaultExtends] = true
+			base = GetDefaultConfig()
+		}
+	default:
+		if extends, err = resolveExtends(path, extends); err != nil {
+			return nil, err
+		}
+		key, err := configKey(extends)
+		if err != nil {
+			return nil, err
+		}
+		if loaded[key] {
+			break
+		}
+		// Only the config named on the command line is pinned
+		options.SHA256 = ""
+		if base, err = loadConfigLayer(extends, options, append(chain, absPath), loaded, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	if under != nil {
+		base.Merge(under)
+	}
+	base.Merge(config)
+	return base, nil
+}
+
+// Merge layers overlay onto the config. Rules are matched by name: fields the
+// overlay rule sets replace those of the base rule, and new rules are appended
+// Vars, podSpecPaths, and exitCodes merge by key, lists are added to, and
+// switches that either side turns on stay on
+func (c *RuleConfig) Merge(overlay *RuleConfig) {
+	index := make(map[string]int, len(c.Rules))
+	for i, rule := range c.Rules {
+		index[rule.Name] = i
+	}
+	for _, rule := range overlay.Rules {
+		if i, ok := index[rule.Name]; ok {
+			c.Rules[i].override(rule)
+			continue
+		}
+		index[rule.Name] = len(c.Rules)
+		c.Rules = append(c.Rules, rule)
+	}
+
+	if len(overlay.Vars) > 0 && c.Vars == nil {
+		c.Vars = make(map[string][]string, len(overlay.Vars))
+	}
+	for name, values := range overlay.Vars {
+		c.Vars[name] = values
+	}
+	if len(overlay.PodSpecPaths) > 0 && c.PodSpecPaths == nil {
+		c.PodSpecPaths = make(map[string]string, len(overlay.PodSpecPaths))
+	}
+	for kind, path := range overlay.PodSpecPaths {
+		c.PodSpecPaths[kind] = path
+	}
+	if len(overlay.ExitCodes) > 0 && c.ExitCodes == nil {
+		c.ExitCodes = make(map[string]int, len(overlay.ExitCodes))
+	}
+	for severity, code := range overlay.ExitCodes {
+		c.ExitCodes[severity] = code
+	}
+	if len(overlay.exitCodePositions) > 0 && c.exitCodePositions == nil {
+		c.exitCodePositions = make(map[string]string, len(overlay.exitCodePositions))
+	}
+	for severity, position := range overlay.exitCodePositions {
+		c.exitCodePositions[severity] = position
+	}
+
+	c.ClusterScopedKinds = appendUnique(c.ClusterScopedKinds, overlay.ClusterScopedKinds...)
+	c.Profiles = appendUnique(c.Profiles, overlay.Profiles...)
+	c.DisabledRules = appendUnique(c.DisabledRules, overlay.DisabledRules...)
+	c.RegoPolicies = appendUnique(c.RegoPolicies, overlay.RegoPolicies...)
+	c.NoDefaultStorageClass = c.NoDefaultStorageClass || overlay.NoDefaultStorageClass
+	c.problems = append(c.problems, overlay.problems...)
+	c.AssumeCompleteBundle = c.AssumeCompleteBundle || overlay.AssumeCompleteBundle
+	c.Extends = ""
+}
+
+// override replaces the fields of a rule that the overlay rule sets
+func (r *Rule) override(overlay Rule) {
+	if overlay.Description != "" {
+		r.Description = overlay.Description
+	}
+	if overlay.Severity != "" {
+		r.Severity = overlay.Severity
+	}
+	if overlay.Type != "" {
+		r.Type = overlay.Type
+	}
+	if overlay.Tags != nil {
+		r.Tags = overlay.Tags
+	}
+	if overlay.Conditions != nil {
+		r.Conditions = overlay.Conditions
+	}
+	if overlay.When != nil {
+		r.When = overlay.When
+	}
+	if overlay.Expression != "" {
+		r.Expression = overlay.Expression
+	}
+	if overlay.Message != "" {
+		r.Message = overlay.Message
+	}
+	if overlay.Help != "" {
+		r.Help = overlay.Help
+	}
+	if overlay.AppliesTo != nil {
+		r.AppliesTo = overlay.AppliesTo
+	}
+	if overlay.Kinds != nil {
+		r.Kinds = overlay.Kinds
+	}
+	if overlay.ExcludeKinds != nil {
+		r.ExcludeKinds = overlay.ExcludeKinds
+	}
+	if overlay.Namespaces != nil {
+		r.Namespaces = overlay.Namespaces
+	}
+	if overlay.ExcludeNamespaces != nil {
+		r.ExcludeNamespaces = overlay.ExcludeNamespaces
+	}
+	if overlay.Selector != nil {
+		r.Selector = overlay.Selector
+	}
+	if overlay.ExcludeContainers != nil {
+		r.ExcludeContainers = overlay.ExcludeContainers
+	}
+	if overlay.ExcludeImages != nil {
+		r.ExcludeImages = overlay.ExcludeImages
+	}
+	if overlay.ExcludePaths != nil {
+		r.ExcludePaths = overlay.ExcludePaths
+	}
+	if overlay.AllowEnv != nil {
+		r.AllowEnv = overlay.AllowEnv
+	}
+	if overlay.Enabled != nil {
+		r.Enabled = overlay.Enabled
+	}
+	if overlay.Tests != nil {
+		r.Tests = overlay.Tests
+	}
+	r.Source = overlay.Source
+	if len(overlay.positions) > 0 && r.positions == nil {
+		r.positions = make(map[string]string, len(overlay.positions))
+	}
+	for field, position := range overlay.positions {
+		r.positions[field] = position
+	}
+}
+
+// appendUnique appends the values that list does not hold yet
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		if !containsString(list, v) {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// Validate checks the config before evaluation: severities, messages,
+// condition types and values, regular expressions, and the problems found
+// while decoding. Every problem is reported, with the file and line of the
+// offending field when the rule comes from a file
+func (c *RuleConfig) Validate() error {
+	problems := append(configProblems{}, c.problems...)
+
+	kinds := make([]string, 0, len(c.PodSpecPaths))
+	for kind := range c.PodSpecPaths {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		if path := c.PodSpecPaths[kind]; path != "spec" && !strings.HasPrefix(path, "spec.") {
+			problems.add("", "podSpecPaths %q: path %q must start at spec", kind, path)
+		}
+	}
+	c.validateExitCodes(&problems)
+
+	for _, rule := range c.Rules {
+		if rule.Name == "" {
+			problems.add(rule.position(""), "rule has no name")
+			continue
+		}
+		switch rule.Severity {
+		case SeverityError, SeverityWarn:
+		case "":
+			problems.add(rule.position(""), "rule %q: missing severity (ERROR or WARN)", rule.Name)
+		default:
+			problems.add(rule.position("severity"), "rule %q: severity %q must be ERROR or WARN", rule.Name, rule.Severity)
+		}
+		if strings.TrimSpace(rule.Message) == "" {
+			problems.add(rule.position("message"), "rule %q: missing message", rule.Name)
+		}
+		for i, pattern := range rule.Namespaces {
+			if _, err := path.Match(pattern, ""); err != nil {
+				problems.add(rule.itemPosition("namespaces", i), "rule %q: invalid namespace pattern %q: %v", rule.Name, pattern, err)
+			}
+		}
+		for i, pattern := range rule.ExcludeNamespaces {
+			if _, err := path.Match(pattern, ""); err != nil {
+				problems.add(rule.itemPosition("excludeNamespaces", i), "rule %q: invalid namespace pattern %q: %v", rule.Name, pattern, err)
+			}
+		}
+		if rule.Selector != nil {
+			for _, requirement := range rule.Selector.MatchExpressions {
+				switch requirement.Operator {
+				case "In", "NotIn", "Exists", "DoesNotExist":
+				default:
+					problems.add(rule.position("selector"), "rule %q: selector operator %q for key %q must be In, NotIn, Exists, or DoesNotExist", rule.Name, requirement.Operator, requirement.Key)
+				}
+			}
+		}
+		for i, text := range rule.Conditions {
+			condition, err := c.parseRuleCondition(rule, text)
+			if err != nil {
+				problems.add(rule.itemPosition("conditions", i), "%v", err)
+				continue
+			}
+			if _, ok := conditionLayers[condition.Type]; !ok {
+				problems.add(rule.itemPosition("conditions", i), "rule %q: unknown condition type %q", rule.Name, condition.Type)
+			}
+		}
+		if err := validateExpression(rule); err != nil {
+			problems.add(rule.position("expression"), "%v", err)
+		}
+		for i, test := range rule.Tests {
+			if test.Manifest == "" {
+				problems.add(rule.itemPosition("tests", i), "rule %q: test %d has no manifest", rule.Name, i+1)
+			}
+			if test.Expect != ruleTestViolation && test.Expect != ruleTestPass {
+				problems.add(rule.itemPosition("tests", i), "rule %q: test %d: expect must be %s or %s, not %q", rule.Name, i+1, ruleTestViolation, ruleTestPass, test.Expect)
+			}
+		}
+		if rule.When != nil {
+			// when errors carry the line of the offending node
+			if err := c.parseWhen(rule); err != nil {
+				problems.add(rule.Source, "%v", err)
+			}
+		}
+	}
+	return problems.err()
+}
+
+// parseConditions parses the conditions of a rule, resolves their vars, and
+// parses their values
+func (c *RuleConfig) parseConditions(rule Rule) ([]Condition, error) {
+	conditions := make([]Condition, 0, len(rule.Conditions))
+	for _, text := range rule.Conditions {
+		condition, err := c.parseRuleCondition(rule, text)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+	}
+	return conditions, nil
+}
+
+// parseRuleCondition parses one condition of a rule, resolves its vars, and
+// parses its value into the typed arguments the rule engine evaluates
+func (c *RuleConfig) parseRuleCondition(rule Rule, text string) (Condition, error) {
+	condition, err := parseCondition(text)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		return Condition{}, fmt.Errorf("rule %q: condition %q: %w", rule.Name, text, err)
+	}
+	if condition.Value, err = c.resolveVars(condition.Value); err != nil {
+		return Condition{}, fmt.Errorf("rule %q: condition %q: %w", rule.Name, text, err)
 	}
+	if err := condition.parseArgs(); err != nil {
+		return Condition{}, fmt.Errorf("rule %q: condition %q: %w", rule.Name, text, err)
+	}
+	return condition, nil
+}
 
-	var config RuleConfig
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+// varReferencePattern matches a condition value that names a var, such as
+// $allowedRegistries
+var varReferencePattern = regexp.MustCompile(`^\$([A-Za-z_][A-Za-z0-9_-]*)$`)
+
+// resolveVars replaces a "$name" condition value with the comma-joined
+// list of the same name from the vars section; naming a var that is not
+// defined is an error
+func (c *RuleConfig) resolveVars(value string) (string, error) {
+	name, ok := varReference(value)
+	if !ok {
+		return value, nil
+	}
+	list, ok := c.Vars[name]
+	if !ok {
+		return "", fmt.Errorf("undefined var %s (defined: %s)", value, joinVarNames(c.Vars))
 	}
+	return strings.Join(list, ","), nil
+}
 
-	return &config, nil
+// varReference returns the var a condition value names, if it is a reference
+func varReference(value string) (string, bool) {
+	match := varReferencePattern.FindStringSubmatch(value)
+	if match == nil {
+		return "", false
+	}
+	return match[1], true
+}
+
+// joinVarNames lists the defined var names, sorted, or "none"
+func joinVarNames(vars map[string][]string) string {
+	if len(vars) == 0 {
+		return "none"
+	}
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
 }
 
 // GetDefaultConfig returns the default rule configuration
 func GetDefaultConfig() *RuleConfig {
 	return &RuleConfig{
 		Rules: []Rule{
+			{
+				Name:        "no-removed-api-versions",
+				Description: "Resources must not use apiVersions removed in the target Kubernetes version",
+				Severity:    "ERROR",
+				Type:        "api",
+				Tags:        []string{"upgrade"},
+				Conditions:  []string{"api_version_removed"},
+				Message:     "{kind} '{name}' uses {details}",
+				Help:        "migrate the manifest to the replacement apiVersion before upgrading",
+			},
+			{
+				Name:        "no-deprecated-api-versions",
+				Description: "Resources should not use apiVersions deprecated in the target Kubernetes version",
+				Severity:    "WARN",
+				Type:        "api",
+				Tags:        []string{"upgrade"},
+				Conditions:  []string{"api_version_deprecated"},
+				Message:     "{kind} '{name}' uses {details}",
+				Help:        "migrate the manifest to the replacement apiVersion before it is removed",
+			},
+			{
+				Name:        "no-removed-security-annotations",
+				Description: "Pods must not rely on seccomp annotations the target Kubernetes version ignores",
+				Severity:    "ERROR",
+				Type:        "security",
+				Tags:        []string{"security", "upgrade"},
+				Conditions:  []string{"security_annotation_removed"},
+				Message:     "{kind} '{name}' relies on security annotations the kubelet ignores: {details}",
+				Help:        "move each profile to the securityContext field named above and remove the annotation",
+			},
+			{
+				Name:        "no-deprecated-security-annotations",
+				Description: "Pods should set seccomp and AppArmor profiles through securityContext",
+				Severity:    "WARN",
+				Type:        "security",
+				Tags:        []string{"security", "upgrade"},
+				Conditions:  []string{"security_annotation_deprecated"},
+				Message:     "{kind} '{name}' uses deprecated security annotations: {details}",
+				Help:        "move each profile to the securityContext field named above and remove the annotation",
+			},
+			{
+				Name:        "valid-metadata-names",
+				Description: "Resource names must be valid for their kind",
+				Severity:    "ERROR",
+				Type:        "metadata",
+				Tags:        []string{"metadata", "correctness"},
+				Conditions:  []string{"metadata_name_invalid"},
+				Message:     "{kind} '{name}' has an invalid name: {details}",
+				Help:        "use lowercase letters, digits, and '-'; Service names must start with a letter and fit in 63 characters",
+			},
+			{
+				Name:        "valid-labels",
+				Description: "Label keys and values must be valid",
+				Severity:    "ERROR",
+				Type:        "metadata",
+				Tags:        []string{"metadata", "correctness"},
+				Conditions:  []string{"label_syntax_invalid"},
+				Message:     "{kind} '{name}' has invalid labels: {details}",
+				Help:        "label values are at most 63 alphanumerics, '-', '_', or '.', and keys may add a DNS subdomain prefix such as example.com/",
+			},
+			{
+				Name:        "valid-annotation-keys",
+				Description: "Annotation keys must be valid",
+				Severity:    "ERROR",
+				Type:        "metadata",
+				Tags:        []string{"metadata", "correctness"},
+				Conditions:  []string{"annotation_key_invalid"},
+				Message:     "{kind} '{name}' has invalid annotation keys: {details}",
+				Help:        "keys are an optional DNS subdomain prefix and '/', then at most 63 alphanumerics, '-', '_', or '.'",
+			},
+			{
+				Name:        "limit-annotation-size",
+				Description: "Single annotations should stay small",
+				Severity:    "WARN",
+				Type:        "metadata",
+				Tags:        []string{"metadata", "correctness"},
+				Conditions:  []string{"annotation_size_exceeds:64Ki"},
+				Message:     "{kind} '{name}' has large annotations: {details}",
+				Help:        "move large payloads into a ConfigMap; all annotations of an object share a 256KiB limit",
+			},
 			{
 				Name:        "no-latest-image",
 				Description: "Disallow latest image tags",
 				Severity:    "ERROR",
 				Type:        "image",
+				Tags:        []string{"images", "availability"},
 				Conditions:  []string{"image_tag_equals:latest", "image_tag_missing"},
-				Message:     "Container '{container}' uses 'latest' image tag",
+				Message:     "{origin} '{container}' uses 'latest' image tag",
 				Help:        "use a specific version or digest",
 			},
+			{
+				Name:        "require-read-only-root-filesystem",
+				Description: "Containers should mount their root filesystem read-only",
+				Severity:    "WARN",
+				Type:        "security",
+				Tags:        []string{"security"},
+				Conditions:  []string{"read_only_root_filesystem_not_true"},
+				Message:     "{origin} '{container}' has a writable root filesystem",
+				Help:        "set securityContext.readOnlyRootFilesystem: true so a compromised process cannot modify binaries or drop tools; mount an emptyDir for paths that must be writable",
+			},
 			{
 				Name:        "require-resource-requests",
 				Description: "Require CPU and memory requests",
 				Severity:    "WARN",
 				Type:        "resources",
+				Tags:        []string{"cost", "availability"},
 				Conditions:  []string{"missing_cpu_requests", "missing_memory_requests"},
-				Message:     "Container '{container}' missing resource requests",
+				Message:     "{origin} '{container}' missing resource requests",
 				Help:        "set requests.cpu and requests.memory",
+				AppliesTo:   []string{OriginContainer, OriginInitContainer},
 			},
 			{
 				Name:        "require-resource-limits",
 				Description: "Require CPU and memory limits",
 				Severity:    "WARN",
 				Type:        "resources",
+				Tags:        []string{"cost", "availability"},
 				Conditions:  []string{"missing_cpu_limits", "missing_memory_limits"},
-				Message:     "Container '{container}' missing resource limits",
+				Message:     "{origin} '{container}' missing resource limits",
 				Help:        "set limits.cpu and limits.memory",
+				AppliesTo:   []string{OriginContainer, OriginInitContainer},
+			},
+			{
+				Name:        "requests-within-limits",
+				Description: "CPU and memory requests must not exceed their limits",
+				Severity:    "ERROR",
+				Type:        "resources",
+				Tags:        []string{"correctness"},
+				Conditions:  []string{"cpu_request_exceeds_limit", "memory_request_exceeds_limit"},
+				Message:     "{origin} '{container}' requests more than its limit: {details}",
+				Help:        "lower the request or raise the limit; the API server rejects requests above limits",
+			},
+			{
+				Name:        "valid-resource-quantities",
+				Description: "CPU and memory quantities must be valid Kubernetes quantities",
+				Severity:    "ERROR",
+				Type:        "resources",
+				Tags:        []string{"correctness"},
+				Conditions:  []string{"invalid_quantity"},
+				Message:     "{origin} '{container}' has invalid resource quantities: {details}",
+				Help:        "use quantities such as 500m, 2, 512Mi, or 1Gi",
+			},
+			{
+				Name:        "limit-memory-overcommit",
+				Description: "Memory limits should stay within 4x the request",
+				Severity:    "WARN",
+				Type:        "resources",
+				Tags:        []string{"cost"},
+				Conditions:  []string{"memory_limit_ratio_exceeds:4"},
+				Message:     "{origin} '{container}' memory {details}",
+				Help:        "raise the memory request or lower the limit; large gaps break bin-packing and risk node OOM",
+			},
+			{
+				Name:        "limit-memory-emptydir",
+				Description: "Memory-backed emptyDir volumes must set a sizeLimit",
+				Severity:    "WARN",
+				Type:        "resources",
+				Tags:        []string{"cost"},
+				Conditions:  []string{"emptydir_memory_missing_sizelimit"},
+				Message:     "{kind} '{name}' has a memory-backed {details} without a sizeLimit",
+				Help:        "set emptyDir.sizeLimit; tmpfs usage counts against the pod's memory limit",
+			},
+			{
+				Name:        "require-pvc-storage-request",
+				Description: "PersistentVolumeClaims must request storage",
+				Severity:    "ERROR",
+				Type:        "resources",
+				Tags:        []string{"correctness"},
+				Conditions:  []string{"pvc_storage_request_missing"},
+				Message:     "{kind} '{name}' is missing {details}",
+				Help:        "set resources.requests.storage; the API server rejects claims without it",
+			},
+			{
+				Name:        "valid-pvc-storage-request",
+				Description: "PersistentVolumeClaim storage requests must be valid quantities",
+				Severity:    "ERROR",
+				Type:        "resources",
+				Tags:        []string{"correctness"},
+				Conditions:  []string{"pvc_storage_request_invalid"},
+				Message:     "{kind} '{name}' requests invalid storage {details}",
+				Help:        "use a Kubernetes quantity such as 10Gi",
+			},
+			{
+				Name:        "no-shared-rwo-claims",
+				Description: "Multi-replica StatefulSets should not share a ReadWriteOnce claim",
+				Severity:    "WARN",
+				Type:        "reliability",
+				Tags:        []string{"availability"},
+				Conditions:  []string{"pvc_rwo_shared"},
+				Message:     "{kind} '{name}' is ReadWriteOnce but mounted by every replica of {details}",
+				Help:        "move the claim into the StatefulSet's volumeClaimTemplates so each replica gets its own volume",
+			},
+			{
+				Name:        "no-duplicate-resources",
+				Description: "Each resource must be defined only once in a scan",
+				Severity:    "ERROR",
+				Type:        "reliability",
+				Tags:        []string{"correctness"},
+				Conditions:  []string{"duplicate_resource"},
+				Message:     "{kind} '{name}' is also defined in {details}",
+				Help:        "remove the extra copies; kubectl apply keeps whichever definition it applies last",
+			},
+			{
+				Name:        "config-references-resolve",
+				Description: "Referenced ConfigMaps, Secrets, and keys must exist when the scan is complete",
+				Severity:    "WARN",
+				Type:        "reliability",
+				Tags:        []string{"correctness"},
+				Conditions:  []string{"config_reference_missing"},
+				Message:     "{kind} '{name}' references objects missing from the scan: {details}",
+				Help:        "add the ConfigMap or Secret (or the key), or mark the reference optional: true; otherwise the pod stays in CreateContainerConfigError",
+			},
+			{
+				Name:        "service-account-exists",
+				Description: "Workloads must use a ServiceAccount defined in the scan when the scan is complete",
+				Severity:    "WARN",
+				Type:        "reliability",
+				Tags:        []string{"correctness"},
+				Conditions:  []string{"service_account_missing"},
+				Message:     "{kind} '{name}' uses ServiceAccount {details}, which is not in the scan",
+				Help:        "add the ServiceAccount to the manifests or fix serviceAccountName; pods are not created without it",
+			},
+			{
+				Name:        "custom-resources-match-crd",
+				Description: "Custom resources must match the schema of a CRD in the same scan",
+				Severity:    "ERROR",
+				Type:        "api",
+				Tags:        []string{"upgrade", "correctness"},
+				Conditions:  []string{"crd_schema_violation"},
+				Message:     "{kind} '{name}' does not match its CustomResourceDefinition: {details}",
+				Help:        "fix the fields named above; the API server rejects or silently drops them",
+			},
+			{
+				Name:        "selector-matches-template",
+				Description: "Workload selectors must match the pod template labels",
+				Severity:    "ERROR",
+				Type:        "reliability",
+				Tags:        []string{"correctness"},
+				Conditions:  []string{"selector_not_matching_template"},
+				Message:     "{kind} '{name}' selector does not match its pod template labels: {details}",
+				Help:        "make spec.selector.matchLabels a subset of spec.template.metadata.labels; StatefulSet and DaemonSet selectors cannot be changed later",
+			},
+			{
+				Name:        "selector-match-expressions",
+				Description: "Workload selectors using matchExpressions cannot be verified against the template",
+				Severity:    "WARN",
+				Type:        "reliability",
+				Tags:        []string{"correctness"},
+				Conditions:  []string{"selector_match_expressions"},
+				Message:     "{kind} '{name}' selector uses matchExpressions ({details}), which cannot be statically verified against the pod template",
+				Help:        "prefer matchLabels, or check that the template labels satisfy every expression",
+			},
+			{
+				Name:        "require-statefulset-service-name",
+				Description: "StatefulSets must set spec.serviceName",
+				Severity:    "ERROR",
+				Type:        "reliability",
+				Tags:        []string{"correctness"},
+				Conditions:  []string{"statefulset_service_name_missing"},
+				Message:     "{kind} '{name}' does not set spec.serviceName",
+				Help:        "set serviceName to the headless Service that gives the pods stable DNS names",
+			},
+			{
+				Name:        "statefulset-headless-service",
+				Description: "StatefulSet serviceNames should resolve to a headless Service in the scan",
+				Severity:    "WARN",
+				Type:        "reliability",
+				Tags:        []string{"correctness"},
+				Conditions:  []string{"statefulset_service_not_headless"},
+				Message:     "{kind} '{name}' has no headless Service: {details}",
+				Help:        "add a Service with clusterIP: None and the StatefulSet's pod labels as its selector",
+			},
+			{
+				Name:        "require-storage-class",
+				Description: "volumeClaimTemplates must name a storageClassName when clusters have no default class",
+				Severity:    "WARN",
+				Type:        "resources",
+				Tags:        []string{"cost"},
+				Conditions:  []string{"volume_claim_template_storage_class_missing"},
+				Message:     "{kind} '{name}' requests storage without a storageClassName in {details}",
+				Help:        "set storageClassName; without a default StorageClass the claim stays Pending",
 			},
 			{
 				Name:        "no-root-containers",
 				Description: "Containers must not run as root",
 				Severity:    "ERROR",
 				Type:        "security",
+				Tags:        []string{"security"},
 				Conditions:  []string{"missing_security_context", "run_as_non_root_false", "run_as_user_zero"},
-				Message:     "Container '{container}' running as root or missing securityContext",
+				Message:     "{origin} '{container}' running as root or missing securityContext",
 				Help:        "set runAsNonRoot: true and runAsUser to non-zero value",
 			},
+			{
+				Name:        "no-plaintext-secrets",
+				Description: "Credentials must not be set as literal env values",
+				Severity:    "ERROR",
+				Type:        "security",
+				Tags:        []string{"security"},
+				Conditions:  []string{"plaintext_secret_env"},
+				Message:     "{origin} '{container}' sets credentials as plaintext env vars: {details}",
+				Help:        "move the value into a Secret and reference it with valueFrom.secretKeyRef",
+			},
+			{
+				Name:        "prefer-secret-volumes",
+				Description: "Secrets should be mounted as volumes rather than exposed as env vars",
+				Severity:    "WARN",
+				Type:        "security",
+				Tags:        []string{"security"},
+				Conditions:  []string{"secret_env_exposure"},
+				Message:     "{origin} '{container}' exposes secrets as env vars: {details}",
+				Help:        "mount the Secret as a volume; env vars leak into crash dumps and kubectl describe",
+			},
+			{
+				Name:        "no-committed-credentials",
+				Description: "Secret manifests must not contain real credentials",
+				Severity:    "ERROR",
+				Type:        "security",
+				Tags:        []string{"security"},
+				Conditions:  []string{"secret_data_credential"},
+				Message:     "{kind} '{name}' commits a credential in {details}",
+				Help:        "rotate the credential and deliver it through a secret manager, SealedSecrets, or SOPS instead of plain manifests",
+			},
+			{
+				Name:        "valid-secret-data",
+				Description: "Secret data values must be valid base64",
+				Severity:    "ERROR",
+				Type:        "security",
+				Tags:        []string{"security", "correctness"},
+				Conditions:  []string{"secret_data_invalid_base64"},
+				Message:     "{kind} '{name}' data key {details} is not valid base64",
+				Help:        "base64-encode the value, or move it to stringData to store it as plain text",
+			},
+			{
+				Name:        "limit-secret-size",
+				Description: "Secrets should stay well below the 1MiB object size limit",
+				Severity:    "WARN",
+				Type:        "resources",
+				Tags:        []string{"correctness"},
+				Conditions:  []string{"secret_size_exceeds:900Ki"},
+				Message:     "{kind} '{name}' holds {details} of data, close to the 1MiB limit",
+				Help:        "split the Secret, or mount large files from a volume instead",
+			},
+			{
+				Name:        "no-credentials-in-configmaps",
+				Description: "Credentials belong in Secrets, not ConfigMaps",
+				Severity:    "WARN",
+				Type:        "security",
+				Tags:        []string{"security"},
+				Conditions:  []string{"configmap_credential"},
+				Message:     "{kind} '{name}' stores a credential in {details}",
+				Help:        "move the value to a Secret and rotate it; ConfigMaps are readable by anyone who can view the namespace",
+			},
+			{
+				Name:        "valid-configmap-keys",
+				Description: "ConfigMap keys must be valid",
+				Severity:    "ERROR",
+				Type:        "resources",
+				Tags:        []string{"correctness"},
+				Conditions:  []string{"configmap_invalid_key"},
+				Message:     "{kind} '{name}' has invalid keys: {details}",
+				Help:        "keys may only contain letters, digits, '-', '_', and '.', up to 253 characters",
+			},
+			{
+				Name:        "limit-configmap-size",
+				Description: "ConfigMaps should stay well below the 1MiB object size limit",
+				Severity:    "WARN",
+				Type:        "resources",
+				Tags:        []string{"correctness"},
+				Conditions:  []string{"configmap_size_exceeds:900Ki"},
+				Message:     "{kind} '{name}' holds {details}, close to the 1MiB limit",
+				Help:        "split the ConfigMap, or ship large files in the image or a volume",
+			},
+			{
+				Name:        "valid-tls-certificates",
+				Description: "TLS Secrets must hold a PEM certificate chain",
+				Severity:    "ERROR",
+				Type:        "security",
+				Tags:        []string{"security", "correctness"},
+				Conditions:  []string{"tls_cert_invalid"},
+				Message:     "{kind} '{name}' has a malformed tls.crt: {details}",
+				Help:        "store the PEM-encoded certificate chain, starting with -----BEGIN CERTIFICATE-----",
+			},
+			{
+				Name:        "no-expired-certificates",
+				Description: "TLS Secrets must not hold expired certificates",
+				Severity:    "ERROR",
+				Type:        "security",
+				Tags:        []string{"security", "availability"},
+				Conditions:  []string{"tls_cert_expired"},
+				Message:     "{kind} '{name}' holds an expired certificate ({details})",
+				Help:        "renew the certificate, or let cert-manager issue it",
+			},
+			{
+				Name:        "certificate-expiry-window",
+				Description: "TLS certificates should be renewed before their last 30 days",
+				Severity:    "WARN",
+				Type:        "security",
+				Tags:        []string{"security", "availability"},
+				Conditions:  []string{"tls_cert_expires_within:30d"},
+				Message:     "{kind} '{name}' holds a certificate expiring within 30 days ({details})",
+				Help:        "renew the certificate, or let cert-manager issue it",
+			},
 			{
 				Name:        "no-privileged-containers",
 				Description: "Containers must not run in privileged mode",
 				Severity:    "ERROR",
 				Type:        "security",
+				Tags:        []string{"security"},
 				Conditions:  []string{"privileged_true"},
-				Message:     "Container '{container}' is running in privileged mode",
+				Message:     "{origin} '{container}' is running in privileged mode",
 				Help:        "set securityContext.privileged: false or remove the field",
 			},
 			{
-				Name:        "require-liveness-probe",
-				Description: "Containers should define a liveness probe",
-				Severity:    "WARN",
+				Name:        "no-host-namespaces",
+				Description: "Pods must not share the host's network, PID, or IPC namespaces",
+				Severity:    "ERROR",
+				Type:        "security",
+				Tags:        []string{"security"},
+				Conditions:  []string{"host_network_true", "host_pid_true", "host_ipc_true"},
+				Message:     "{kind} '{name}' shares a host namespace ({details})",
+				Help:        "remove hostNetwork, hostPID, and hostIPC from the pod spec",
+			},
+			{
+				Name:        "host-network-dns-policy",
+				Description: "Pods on the host network must use dnsPolicy ClusterFirstWithHostNet",
+				Severity:    "ERROR",
 				Type:        "reliability",
-				Conditions:  []string{"missing_liveness_probe"},
-				Message:     "Container '{container}' is missing a liveness probe",
-				Help:        "add a livenessProbe to detect and restart unhealthy containers",
+				Tags:        []string{"availability"},
+				Conditions:  []string{"host_network_without_cluster_first_dns"},
+				Message:     "{kind} '{name}' uses hostNetwork with {details}",
+				Help:        "set dnsPolicy: ClusterFirstWithHostNet so the pod can resolve cluster Services",
+			},
+			{
+				Name:        "no-unsafe-sysctls",
+				Description: "Pods must only set sysctls from the Kubernetes safe set",
+				Severity:    "ERROR",
+				Type:        "security",
+				Tags:        []string{"security"},
+				Conditions:  []string{"sysctl_not_in"},
+				Message:     "{kind} '{name}' sets unsafe sysctls: {details}",
+				Help:        "remove the sysctl; unsafe sysctls need kubelet allowlisting and can affect the whole node",
+			},
+			{
+				Name:        "require-read-only-secret-mounts",
+				Description: "Secret and ConfigMap volumes should be mounted read-only",
+				Severity:    "WARN",
+				Type:        "security",
+				Tags:        []string{"security"},
+				Conditions:  []string{"secret_volume_not_readonly"},
+				Message:     "{origin} '{container}' mounts {details} without readOnly",
+				Help:        "set readOnly: true on the volumeMount",
+			},
+			{
+				Name:         "no-default-service-account",
+				Description:  "Workloads should run under a dedicated ServiceAccount",
+				Severity:     "WARN",
+				Type:         "security",
+				Tags:         []string{"security"},
+				Conditions:   []string{"service_account_default"},
+				Message:      "{kind} '{name}' uses the default ServiceAccount",
+				Help:         "create a dedicated ServiceAccount per workload and set spec.serviceAccountName",
+				ExcludeKinds: []string{"Pod"},
+			},
+			{
+				Name:        "no-cluster-rbac-wildcards",
+				Description: "ClusterRoles must not grant wildcard verbs, resources, or API groups",
+				Severity:    "ERROR",
+				Type:        "security",
+				Tags:        []string{"security"},
+				Conditions:  []string{"rbac_wildcard_verb", "rbac_wildcard_resource", "rbac_wildcard_apigroup"},
+				Message:     "{kind} '{name}' grants wildcard {details}",
+				Help:        "list the exact verbs, resources, and apiGroups the subject needs",
+				Kinds:       []string{"ClusterRole"},
+			},
+			{
+				Name:        "no-rbac-wildcards",
+				Description: "Roles should not grant wildcard verbs, resources, or API groups",
+				Severity:    "WARN",
+				Type:        "security",
+				Tags:        []string{"security"},
+				Conditions:  []string{"rbac_wildcard_verb", "rbac_wildcard_resource", "rbac_wildcard_apigroup"},
+				Message:     "{kind} '{name}' grants wildcard {details}",
+				Help:        "list the exact verbs, resources, and apiGroups the subject needs",
+				Kinds:       []string{"Role"},
+			},
+			{
+				Name:        "no-cluster-secret-read",
+				Description: "ClusterRoles should not grant read access to every Secret",
+				Severity:    "WARN",
+				Type:        "security",
+				Tags:        []string{"security"},
+				Conditions:  []string{"rbac_secrets_read"},
+				Message:     "{kind} '{name}' grants {details} cluster-wide",
+				Help:        "use a namespaced Role, or restrict the rule with resourceNames",
+			},
+			{
+				Name:        "no-cluster-admin-bindings",
+				Description: "Bindings must not grant cluster-admin outside kube-system",
+				Severity:    "ERROR",
+				Type:        "security",
+				Tags:        []string{"security"},
+				Conditions:  []string{"binding_cluster_admin:kube-system"},
+				Message:     "{kind} '{name}' binds {details}",
+				Help:        "bind a narrower ClusterRole, or a Role scoped to the namespaces the subject manages",
+			},
+			{
+				Name:        "no-system-group-bindings",
+				Description: "Bindings should not grant roles to built-in system groups",
+				Severity:    "WARN",
+				Type:        "security",
+				Tags:        []string{"security"},
+				Conditions:  []string{"binding_system_subject"},
+				Message:     "{kind} '{name}' binds {details}",
+				Help:        "bind specific users, groups, or ServiceAccounts; system:authenticated and system:unauthenticated cover every caller, and system:masters bypasses RBAC",
+			},
+			{
+				Name:              "no-host-ports",
+				Description:       "Containers outside kube-system should not bind host ports",
+				Severity:          "WARN",
+				Type:              "security",
+				Tags:              []string{"security"},
+				Conditions:        []string{"host_port_set"},
+				Message:           "{origin} '{container}' binds host ports: {details}",
+				Help:              "remove ports[].hostPort and expose the container through a Service",
+				ExcludeNamespaces: []string{"kube-system"},
+			},
+			{
+				Name:        "no-duplicate-container-ports",
+				Description: "A container must not declare the same port and protocol twice",
+				Severity:    "ERROR",
+				Type:        "networking",
+				Tags:        []string{"networking", "correctness"},
+				Conditions:  []string{"duplicate_container_port"},
+				Message:     "{origin} '{container}' declares a {details}",
+				Help:        "remove the duplicate entry from ports; the API server rejects it at apply time",
+			},
+			{
+				Name:        "no-conflicting-host-ports",
+				Description: "Containers in a pod must not bind the same host port",
+				Severity:    "ERROR",
+				Type:        "networking",
+				Tags:        []string{"networking", "correctness"},
+				Conditions:  []string{"conflicting_host_port"},
+				Message:     "{kind} '{name}' binds a host port twice: {details}",
+				Help:        "give each container its own hostPort; the API server rejects the pod at apply time",
+			},
+			{
+				Name:        "unique-container-names",
+				Description: "Container names must be unique within a pod",
+				Severity:    "ERROR",
+				Type:        "naming",
+				Tags:        []string{"metadata", "correctness"},
+				Conditions:  []string{"duplicate_container_name"},
+				Message:     "{kind} '{name}' has duplicate container names: {details}",
+				Help:        "rename the containers; names must be unique across containers, initContainers, and ephemeralContainers",
+			},
+			{
+				Name:        "volume-mounts-defined",
+				Description: "volumeMounts must refer to volumes declared in the pod spec",
+				Severity:    "ERROR",
+				Type:        "resources",
+				Tags:        []string{"correctness"},
+				Conditions:  []string{"volume_mount_undefined"},
+				Message:     "{kind} '{name}' mounts undeclared volumes: {details}",
+				Help:        "add the volume to spec.volumes or fix the mount name; the pod cannot be created otherwise",
+			},
+			{
+				Name:        "no-unused-volumes",
+				Description: "Declared volumes should be mounted by a container",
+				Severity:    "WARN",
+				Type:        "resources",
+				Tags:        []string{"cost"},
+				Conditions:  []string{"volume_unused"},
+				Message:     "{kind} '{name}' declares volumes no container mounts: {details}",
+				Help:        "remove the volume or add the missing volumeMount",
+			},
+			{
+				Name:        "no-privileged-container-ports",
+				Description: "Containers should listen on unprivileged ports",
+				Severity:    "WARN",
+				Type:        "networking",
+				Tags:        []string{"networking", "security"},
+				Conditions:  []string{"port_below:1024"},
+				Message:     "{origin} '{container}' listens on a privileged {details}",
+				Help:        "listen on a port of 1024 or above and map it in the Service; binding low ports usually means running as root",
+			},
+			{
+				Name:        "no-node-port-services",
+				Description: "Services should not be exposed through NodePorts",
+				Severity:    "WARN",
+				Type:        "networking",
+				Tags:        []string{"networking", "security"},
+				Conditions:  []string{"service_type_equals:NodePort"},
+				Message:     "{kind} '{name}' is exposed on every node as type {details}",
+				Help:        "use a ClusterIP Service behind an Ingress or Gateway instead",
+			},
+			{
+				Name:        "require-drop-all-capabilities",
+				Description: "Containers must drop all Linux capabilities",
+				Severity:    "WARN",
+				Type:        "security",
+				Tags:        []string{"security"},
+				Conditions:  []string{"capabilities_not_dropped_all"},
+				Message:     "{origin} '{container}' does not drop ALL capabilities",
+				Help:        "set securityContext.capabilities.drop: [\"ALL\"] and add back only what is needed",
+			},
+			{
+				Name:        "no-dangerous-capabilities",
+				Description: "Containers may only add NET_BIND_SERVICE",
+				Severity:    "ERROR",
+				Type:        "security",
+				Tags:        []string{"security"},
+				Conditions:  []string{"capabilities_added_not_in:" + restrictedCapabilities},
+				Message:     "{origin} '{container}' adds forbidden capabilities: {details}",
+				Help:        "remove them from securityContext.capabilities.add; only NET_BIND_SERVICE is allowed",
+			},
+			{
+				Name:         "require-liveness-probe",
+				Description:  "Containers should define a liveness probe",
+				Severity:     "WARN",
+				Type:         "reliability",
+				Tags:         []string{"availability"},
+				Conditions:   []string{"missing_liveness_probe"},
+				Message:      "{origin} '{container}' is missing a liveness probe",
+				Help:         "add a livenessProbe to detect and restart unhealthy containers",
+				AppliesTo:    []string{OriginContainer},
+				ExcludeKinds: []string{"Job", "CronJob"},
 			},
 			{
 				Name:        "require-readiness-probe",
-				Description: "Containers should define a readiness probe",
+				Description: "Containers behind Services should define a readiness probe",
 				Severity:    "WARN",
 				Type:        "reliability",
+				Tags:        []string{"availability"},
 				Conditions:  []string{"missing_readiness_probe"},
-				Message:     "Container '{container}' is missing a readiness probe",
-				Help:        "add a readinessProbe to prevent traffic reaching unready containers",
+				Message:     "{origin} '{container}' is missing a readiness probe",
+				Help:        "add a readinessProbe, e.g. readinessProbe: {httpGet: {path: /ready, port: 8080}, periodSeconds: 5}",
+				AppliesTo:   []string{OriginContainer},
+				Kinds:       []string{"Deployment", "StatefulSet", "DaemonSet"},
+			},
+			{
+				Name:         "distinct-liveness-readiness",
+				Description:  "Liveness and readiness probes should not run the same check",
+				Severity:     "WARN",
+				Type:         "reliability",
+				Tags:         []string{"correctness"},
+				Conditions:   []string{"liveness_equals_readiness"},
+				Message:      "{origin} '{container}' uses identical liveness and readiness probes",
+				Help:         "point the liveness probe at a cheap process-health endpoint so a dependency outage unreadies pods instead of restarting them",
+				AppliesTo:    []string{OriginContainer},
+				ExcludeKinds: []string{"Job", "CronJob"},
+			},
+			{
+				Name:         "prefer-startup-probe",
+				Description:  "Slow-starting containers should use a startupProbe instead of a long liveness delay",
+				Severity:     "WARN",
+				Type:         "reliability",
+				Tags:         []string{"availability"},
+				Conditions:   []string{"missing_startup_probe_with_high_initial_delay:60"},
+				Message:      "{origin} '{container}' delays its liveness probe by {details}s instead of using a startupProbe",
+				Help:         "move the delay into a startupProbe (failureThreshold x periodSeconds) and drop initialDelaySeconds from the livenessProbe",
+				AppliesTo:    []string{OriginContainer},
+				ExcludeKinds: []string{"Job", "CronJob"},
+			},
+			{
+				Name:        "valid-job-restart-policy",
+				Description: "Job pods must use restartPolicy Never or OnFailure",
+				Severity:    "ERROR",
+				Type:        "reliability",
+				Tags:        []string{"batch"},
+				Conditions:  []string{"job_restart_policy_invalid"},
+				Message:     "{kind} '{name}' has an invalid restart policy: {details}",
+				Help:        "set restartPolicy to Never or OnFailure in the Job's pod template",
+				Kinds:       []string{"Job", "CronJob"},
+			},
+			{
+				Name:        "require-job-backoff-limit",
+				Description: "Jobs should set backoffLimit to bound retries",
+				Severity:    "WARN",
+				Type:        "reliability",
+				Tags:        []string{"batch"},
+				Conditions:  []string{"job_backoff_limit_missing"},
+				Message:     "{kind} '{name}' does not set {details}",
+				Help:        "set backoffLimit so a failing Job stops retrying",
+				Kinds:       []string{"Job", "CronJob"},
+			},
+			{
+				Name:        "require-job-active-deadline",
+				Description: "Jobs should set activeDeadlineSeconds to bound their runtime",
+				Severity:    "WARN",
+				Type:        "reliability",
+				Tags:        []string{"batch"},
+				Conditions:  []string{"job_active_deadline_missing"},
+				Message:     "{kind} '{name}' does not set {details}",
+				Help:        "set activeDeadlineSeconds so a stuck Job is terminated",
+				Kinds:       []string{"Job", "CronJob"},
+			},
+			{
+				Name:        "valid-cron-schedule",
+				Description: "CronJob schedules must parse",
+				Severity:    "ERROR",
+				Type:        "reliability",
+				Tags:        []string{"batch"},
+				Conditions:  []string{"invalid_cron_schedule"},
+				Message:     "{kind} '{name}' has an invalid schedule {details}",
+				Help:        "use five fields (minute hour day-of-month month day-of-week) or a macro such as @hourly",
+				Kinds:       []string{"CronJob"},
+			},
+			{
+				Name:        "no-every-minute-cron",
+				Description: "CronJobs should not run every minute",
+				Severity:    "WARN",
+				Type:        "reliability",
+				Tags:        []string{"batch"},
+				Conditions:  []string{"cron_schedule_every_minute"},
+				Message:     "{kind} '{name}' runs every minute ('{details}')",
+				Help:        "set the intended minute and hour; '* * * * *' is usually a placeholder",
+				Kinds:       []string{"CronJob"},
+			},
+			{
+				Name:        "require-cron-concurrency-policy",
+				Description: "CronJobs should set concurrencyPolicy to Forbid or Replace",
+				Severity:    "WARN",
+				Type:        "reliability",
+				Tags:        []string{"batch"},
+				Conditions:  []string{"concurrency_policy_missing_or_allow"},
+				Message:     "{kind} '{name}' allows overlapping runs (concurrencyPolicy {details})",
+				Help:        "set concurrencyPolicy: Forbid (skip a run while one is active) or Replace (cancel the active run)",
+				Kinds:       []string{"CronJob"},
+			},
+			{
+				Name:        "require-cron-history-limits",
+				Description: "CronJobs should bound the Jobs they keep",
+				Severity:    "WARN",
+				Type:        "reliability",
+				Tags:        []string{"batch"},
+				Conditions:  []string{"history_limit_missing"},
+				Message:     "{kind} '{name}' does not set {details}",
+				Help:        "set successfulJobsHistoryLimit and failedJobsHistoryLimit so finished Jobs and their pods are cleaned up",
+				Kinds:       []string{"CronJob"},
+			},
+			{
+				Name:        "require-cron-starting-deadline",
+				Description: "CronJobs with concurrencyPolicy Forbid should set startingDeadlineSeconds",
+				Severity:    "WARN",
+				Type:        "reliability",
+				Tags:        []string{"batch"},
+				Conditions:  []string{"starting_deadline_missing"},
+				Message:     "{kind} '{name}' forbids concurrent runs but does not set spec.startingDeadlineSeconds",
+				Help:        "set startingDeadlineSeconds; without it, 100 skipped runs stop the CronJob from being scheduled",
+				Kinds:       []string{"CronJob"},
+			},
+			{
+				Name:        "no-daemonset-on-delete",
+				Description: "DaemonSets should roll out updates instead of waiting for manual pod deletion",
+				Severity:    "WARN",
+				Type:        "reliability",
+				Tags:        []string{"availability"},
+				Conditions:  []string{"daemonset_on_delete_strategy"},
+				Message:     "{kind} '{name}' uses updateStrategy OnDelete",
+				Help:        "use updateStrategy type RollingUpdate (the default); with OnDelete, updates such as security patches only reach pods deleted by hand",
+				Kinds:       []string{"DaemonSet"},
+			},
+			{
+				Name:        "sane-termination-grace-period",
+				Description: "terminationGracePeriodSeconds should be neither 0 nor excessively long",
+				Severity:    "WARN",
+				Type:        "reliability",
+				Tags:        []string{"availability"},
+				Conditions:  []string{"termination_grace_period_zero", "termination_grace_period_exceeds:600"},
+				Message:     "{kind} '{name}' sets {details}",
+				Help:        "0 drops in-flight requests and long periods stall node drains; the default of 30 suits most workloads",
+			},
+			{
+				Name:        "require-multiple-replicas",
+				Description: "Workloads should run more than one replica",
+				Severity:    "WARN",
+				Type:        "reliability",
+				Tags:        []string{"availability"},
+				Conditions:  []string{"replicas_less_than:2"},
+				Message:     "{kind} '{name}' runs {details} replica(s)",
+				Help:        "run at least 2 replicas so the workload stays available while nodes are drained",
+				Kinds:       []string{"Deployment", "StatefulSet"},
+			},
+			{
+				Name:        "valid-hpa-replica-range",
+				Description: "HorizontalPodAutoscalers need room between minReplicas and maxReplicas",
+				Severity:    "WARN",
+				Type:        "reliability",
+				Tags:        []string{"correctness"},
+				Conditions:  []string{"hpa_replica_range_invalid"},
+				Message:     "{kind} '{name}' cannot scale ({details})",
+				Help:        "set minReplicas below maxReplicas, or drop the HPA and set spec.replicas",
+			},
+			{
+				Name:        "require-hpa-metrics",
+				Description: "HorizontalPodAutoscalers should declare their metrics",
+				Severity:    "WARN",
+				Type:        "reliability",
+				Tags:        []string{"availability"},
+				Conditions:  []string{"hpa_metrics_missing"},
+				Message:     "{kind} '{name}' declares no metrics and falls back to 80% CPU",
+				Help:        "list the metrics to scale on under spec.metrics",
+			},
+			{
+				Name:        "sane-hpa-cpu-target",
+				Description: "HPA CPU utilization targets should be between 10% and 100%",
+				Severity:    "WARN",
+				Type:        "reliability",
+				Tags:        []string{"availability"},
+				Conditions:  []string{"hpa_cpu_target_out_of_range:10-100"},
+				Message:     "{kind} '{name}' targets CPU {details}",
+				Help:        "targets above 100% only scale once pods exceed their requests; targets below 10% scale on noise",
+			},
+			{
+				Name:        "hpa-target-exists",
+				Description: "HPA scaleTargetRefs must name a workload in the scan",
+				Severity:    "ERROR",
+				Type:        "reliability",
+				Tags:        []string{"correctness"},
+				Conditions:  []string{"hpa_target_missing"},
+				Message:     "{kind} '{name}' scales {details}, which is not in the scanned manifests",
+				Help:        "fix the kind, name, or apiVersion of spec.scaleTargetRef; an unresolved target never scales",
+			},
+			{
+				Name:        "no-replicas-with-hpa",
+				Description: "Workloads scaled by an HPA should not set spec.replicas",
+				Severity:    "WARN",
+				Type:        "reliability",
+				Tags:        []string{"availability"},
+				Conditions:  []string{"replicas_set_with_hpa"},
+				Message:     "{kind} '{name}' sets spec.replicas but HorizontalPodAutoscaler '{details}' scales it",
+				Help:        "remove spec.replicas, or drop it in the overlay with a JSON patch (op: remove, path: /spec/replicas), so each apply does not reset the HPA's replica count",
+			},
+			{
+				Name:        "require-pod-spreading",
+				Description: "Replicated workloads should spread their pods across nodes",
+				Severity:    "WARN",
+				Type:        "reliability",
+				Tags:        []string{"availability"},
+				Conditions:  []string{"missing_spread_constraints"},
+				Message:     "{kind} '{name}' runs {details} replicas without topologySpreadConstraints or hostname podAntiAffinity",
+				Help:        "add a topologySpreadConstraints entry or a podAntiAffinity term on kubernetes.io/hostname",
+				Kinds:       []string{"Deployment", "StatefulSet"},
+			},
+			{
+				Name:        "require-pod-disruption-budget",
+				Description: "Replicated workloads should be covered by a PodDisruptionBudget",
+				Severity:    "WARN",
+				Type:        "reliability",
+				Tags:        []string{"availability"},
+				Conditions:  []string{"missing_pdb"},
+				Message:     "{kind} '{name}' runs {details} replicas but no PodDisruptionBudget selects its pods",
+				Help:        "add a PodDisruptionBudget whose selector matches the pod template labels",
+				Kinds:       []string{"Deployment", "StatefulSet"},
+			},
+			{
+				Name:        "pdb-selector-matches-workload",
+				Description: "PodDisruptionBudget selectors should match a scanned workload",
+				Severity:    "WARN",
+				Type:        "reliability",
+				Tags:        []string{"correctness"},
+				Conditions:  []string{"pdb_selector_unmatched"},
+				Message:     "{kind} '{name}' selector ({details}) matches no pod template in its namespace",
+				Help:        "fix the selector to match the workload's pod template labels; a PDB that selects nothing protects nothing",
+			},
+			{
+				Name:        "pdb-allows-eviction",
+				Description: "PodDisruptionBudgets should allow at least one pod to be evicted",
+				Severity:    "WARN",
+				Type:        "reliability",
+				Tags:        []string{"availability"},
+				Conditions:  []string{"pdb_blocks_eviction"},
+				Message:     "{kind} '{name}' blocks node drains: {details}",
+				Help:        "allow at least one disruption, e.g. maxUnavailable: 1, so nodes can be drained for upgrades",
+			},
+			{
+				Name:        "require-ingress-tls",
+				Description: "Ingress hosts should be served over TLS",
+				Severity:    "WARN",
+				Type:        "networking",
+				Tags:        []string{"networking", "security"},
+				Conditions:  []string{"ingress_tls_missing"},
+				Message:     "{kind} '{name}' has no TLS for {details}",
+				Help:        "add a spec.tls entry listing the host and the Secret holding its certificate",
+			},
+			{
+				Name:        "require-ingress-class",
+				Description: "Ingresses should name the controller that serves them",
+				Severity:    "WARN",
+				Type:        "networking",
+				Tags:        []string{"networking"},
+				Conditions:  []string{"ingress_class_missing"},
+				Message:     "{kind} '{name}' sets no ingress class",
+				Help:        "set spec.ingressClassName; unless the cluster marks a default IngressClass, most controllers ignore this Ingress",
+			},
+			{
+				Name:        "no-legacy-ingress-class-annotation",
+				Description: "Ingresses should use spec.ingressClassName instead of the deprecated annotation",
+				Severity:    "WARN",
+				Type:        "networking",
+				Tags:        []string{"networking", "upgrade"},
+				Conditions:  []string{"ingress_legacy_class_annotation"},
+				Message:     "{kind} '{name}' selects its controller with the deprecated {details} annotation",
+				Help:        "move the value to spec.ingressClassName and remove the annotation",
+			},
+			{
+				Name:        "require-gateway-tls",
+				Description: "Gateway listeners on port 443 or HTTPS should configure TLS",
+				Severity:    "WARN",
+				Type:        "networking",
+				Tags:        []string{"networking", "security"},
+				Conditions:  []string{"gateway_listener_tls_missing"},
+				Message:     "{kind} '{name}' has listeners without TLS: {details}",
+				Help:        "use protocol HTTPS with tls.certificateRefs naming the certificate Secret, or TLS with mode Passthrough",
+			},
+			{
+				Name:        "require-route-parent-refs",
+				Description: "Gateway API routes must attach to a Gateway",
+				Severity:    "ERROR",
+				Type:        "networking",
+				Tags:        []string{"networking", "correctness"},
+				Conditions:  []string{"gateway_route_parent_refs_missing"},
+				Message:     "{kind} '{name}' has no parentRefs and attaches to no Gateway",
+				Help:        "add spec.parentRefs naming the Gateway, and listener sectionName if needed, that serves the route",
+			},
+			{
+				Name:        "no-wildcard-gateway-hosts",
+				Description: "Gateway listeners and routes should name exact hostnames",
+				Severity:    "WARN",
+				Type:        "networking",
+				Tags:        []string{"networking", "security"},
+				Conditions:  []string{"gateway_host_wildcard"},
+				Message:     "{kind} '{name}' uses wildcard hostnames: {details}",
+				Help:        "list the exact hostnames, so the Gateway does not route traffic for hosts nobody owns",
+			},
+			{
+				Name:        "route-backends-exist",
+				Description: "Gateway API route backends should be Services in the scan",
+				Severity:    "WARN",
+				Type:        "networking",
+				Tags:        []string{"networking", "correctness"},
+				Conditions:  []string{"gateway_backend_missing"},
+				Message:     "{kind} '{name}' forwards to {details}, which is not in the scanned manifests",
+				Help:        "fix the backendRefs name or namespace; an unresolved backend returns 500s for its share of traffic",
+			},
+			{
+				Name:        "service-selector-matches-workload",
+				Description: "Service selectors should match the pod template of a scanned workload",
+				Severity:    "WARN",
+				Type:        "networking",
+				Tags:        []string{"networking", "correctness"},
+				Conditions:  []string{"service_selector_unmatched"},
+				Message:     "{kind} '{name}' selects no workload in the scan (selector {details})",
+				Help:        "make spec.selector match the pod template labels of the workload it fronts",
+			},
+			{
+				Name:        "require-recommended-labels",
+				Description: "Workloads should carry the recommended app.kubernetes.io labels",
+				Severity:    "WARN",
+				Type:        "metadata",
+				Tags:        []string{"metadata"},
+				Conditions:  []string{"missing_label:app.kubernetes.io/name,app.kubernetes.io/part-of"},
+				Message:     "{kind} '{name}' is missing required labels: {details}",
+				Help:        "set the labels on both metadata.labels and the pod template",
+				Kinds:       []string{"Deployment", "StatefulSet", "DaemonSet", "Job", "CronJob"},
+			},
+			{
+				Name:        "require-namespace",
+				Description: "Namespaced resources should set metadata.namespace explicitly",
+				Severity:    "WARN",
+				Type:        "metadata",
+				Tags:        []string{"metadata"},
+				Conditions:  []string{"namespace_missing"},
+				Message:     "{kind} '{name}' does not set metadata.namespace",
+				Help:        "set metadata.namespace so the resource does not land in the current kubectl context's namespace",
 			},
 			{
 				Name:        "require-image-pull-policy",
 				Description: "Containers should explicitly set imagePullPolicy",
 				Severity:    "WARN",
 				Type:        "image",
+				Tags:        []string{"images"},
 				Conditions:  []string{"missing_image_pull_policy"},
-				Message:     "Container '{container}' does not set imagePullPolicy",
+				Message:     "{origin} '{container}' does not set imagePullPolicy",
 				Help:        "set imagePullPolicy to Always, IfNotPresent, or Never",
 			},
+			{
+				Name:        "no-ephemeral-containers",
+				Description: "Ephemeral containers should not be committed to manifests",
+				Severity:    "WARN",
+				Type:        "reliability",
+				Tags:        []string{"availability"},
+				Conditions:  []string{"ephemeral_container"},
+				Message:     "{origin} '{container}' is declared in the manifest",
+				Help:        "ephemeral containers are for kubectl debug; remove them from declarative specs",
+			},
 		},
 	}
 }
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configProblems collects what is wrong with a config, each prefixed with
+// the file:line it was found at, so every problem is reported at once
+type configProblems []string
+
+// add records a problem at a position, or without one for built-in rules
+func (p *configProblems) add(position, format string, args ...interface{}) {
+	problem := fmt.Sprintf(format, args...)
+	if position != "" {
+		problem = position + ": " + problem
+	}
+	*p = append(*p, problem)
+}
+
+// err returns the problems as one error, or nil when there are none
+func (p configProblems) err() error {
+	switch len(p) {
+	case 0:
+		return nil
+	case 1:
+		return errors.New(p[0])
+	default:
+		return fmt.Errorf("%d problems:\n  %s", len(p), strings.Join(p, "\n  "))
+	}
+}
+
+// unknownFieldPattern matches the error yaml.v3 reports for a key that no
+// struct field takes, e.g. "line 7: field severety not found in type main.Rule"
+var unknownFieldPattern = regexp.MustCompile(`^line (\d+): field (.+) not found in type main\.(\w+)$`)
+
+// typeErrorLinePattern matches the line prefix of the other decoding errors
+var typeErrorLinePattern = regexp.MustCompile(`^line (\d+): (.*)$`)
+
+// decodeConfig decodes one config file, rejecting keys the config format
+// does not have. Decoding problems, and rules defined twice, are recorded
+// on the config so Validate reports them with the rest; a file that is not
+// valid YAML fails right away
+func decodeConfig(path string, data []byte) (*RuleConfig, error) {
+	var config RuleConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	err := decoder.Decode(&config)
+	var typeErr *yaml.TypeError
+	switch {
+	case err == nil, errors.Is(err, io.EOF):
+	case errors.As(err, &typeErr):
+	default:
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	nodes := ruleNodes(&root)
+	if exitCodes := topLevelNode(&root, "exitCodes"); exitCodes != nil && exitCodes.Kind == yaml.MappingNode {
+		config.exitCodePositions = make(map[string]string, len(exitCodes.Content)/2)
+		for j := 0; j+1 < len(exitCodes.Content); j += 2 {
+			key := exitCodes.Content[j]
+			config.exitCodePositions[key.Value] = configPosition(path, key.Line)
+		}
+	}
+
+	if typeErr != nil {
+		for _, message := range typeErr.Errors {
+			config.problems = append(config.problems, decodeProblem(path, message, nodes, config.Rules))
+		}
+	}
+
+	lines := make(map[string]int)
+	for i := range config.Rules {
+		rule := &config.Rules[i]
+		rule.Source = path
+		if i >= len(nodes) {
+			continue
+		}
+		rule.positions = map[string]string{"": configPosition(path, nodes[i].Line)}
+		for j := 0; j+1 < len(nodes[i].Content); j += 2 {
+			key := nodes[i].Content[j]
+			rule.positions[key.Value] = configPosition(path, key.Line)
+			if value := nodes[i].Content[j+1]; value.Kind == yaml.SequenceNode {
+				for k, item := range value.Content {
+					rule.positions[itemField(key.Value, k)] = configPosition(path, item.Line)
+				}
+			}
+		}
+		if rule.Name == "" {
+			continue
+		}
+		if first, ok := lines[rule.Name]; ok {
+			config.problems.add(rule.position("name"), "rule %q is defined twice (first on line %d)", rule.Name, first)
+			continue
+		}
+		lines[rule.Name] = nodes[i].Line
+	}
+	return &config, nil
+}
+
+// topLevelNode returns the value of a top-level key of a config, or nil
+func topLevelNode(root *yaml.Node, key string) *yaml.Node {
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil
+	}
+	top := root.Content[0]
+	for i := 0; i+1 < len(top.Content); i += 2 {
+		if top.Content[i].Value == key {
+			return top.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// ruleNodes returns the mapping node of every entry of the rules list
+func ruleNodes(root *yaml.Node) []*yaml.Node {
+	if rules := topLevelNode(root, "rules"); rules != nil && rules.Kind == yaml.SequenceNode {
+		return rules.Content
+	}
+	return nil
+}
+
+// lastLine returns the last line a node, or one of its children, is on
+func lastLine(node *yaml.Node) int {
+	line := node.Line
+	for _, child := range node.Content {
+		if l := lastLine(child); l > line {
+			line = l
+		}
+	}
+	return line
+}
+
+// configPosition formats a file and line as file:line
+func configPosition(path string, line int) string {
+	return path + ":" + strconv.Itoa(line)
+}
+
+// decodeProblem rewrites a yaml.v3 decoding error as file:line and names
+// the rule it is in, e.g. `kubecheck.yaml:7: rule "x": unknown field "severety"`
+func decodeProblem(path, message string, nodes []*yaml.Node, rules []Rule) string {
+	match := typeErrorLinePattern.FindStringSubmatch(message)
+	if match == nil {
+		return path + ": " + message
+	}
+	line, _ := strconv.Atoi(match[1])
+	text := match[2]
+	if field := unknownFieldPattern.FindStringSubmatch(message); field != nil {
+		text = fmt.Sprintf("unknown field %q", field[2])
+		if field[3] == "RuleConfig" {
+			text = fmt.Sprintf("unknown top-level field %q", field[2])
+		}
+	}
+
+	for i, node := range nodes {
+		if node.Line <= line && line <= lastLine(node) && i < len(rules) && rules[i].Name != "" {
+			text = fmt.Sprintf("rule %q: %s", rules[i].Name, text)
+			break
+		}
+	}
+	return configPosition(path, line) + ": " + text
+}
+
+// position returns the file:line of a rule field, falling back to the start
+// of the rule, or "" for rules that come from no file
+func (r Rule) position(field string) string {
+	if position, ok := r.positions[field]; ok {
+		return position
+	}
+	return r.positions[""]
+}
+
+// itemField names the i-th item of a list field in a rule's positions
+func itemField(field string, i int) string {
+	return field + "[" + strconv.Itoa(i) + "]"
+}
+
+// itemPosition returns the file:line of the i-th item of a list field,
+// falling back to the field itself
+func (r Rule) itemPosition(field string, i int) string {
+	if position, ok := r.positions[itemField(field, i)]; ok {
+		return position
+	}
+	return r.position(field)
+}
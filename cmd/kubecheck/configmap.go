@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// configMapKeyPattern is the key format the API server accepts for ConfigMap data
+var configMapKeyPattern = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+
+// configMapKeyMaxLength is the longest key the API server accepts
+const configMapKeyMaxLength = 253
+
+// parseConfigMapEntries reads a ConfigMap's data and base64-decoded binaryData, sorted by key
+func parseConfigMapEntries(resource K8sResource) []DataEntry {
+	var entries []DataEntry
+
+	for key, value := range getStringMap(resource.Object, "data") {
+		entries = append(entries, DataEntry{Field: "data", Key: key, Value: []byte(value)})
+	}
+	for key, encoded := range getStringMap(resource.Object, "binaryData") {
+		encoded = strings.NewReplacer("\n", "", "\r", "").Replace(encoded)
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			entries = append(entries, DataEntry{Field: "binaryData", Key: key, Invalid: true})
+			continue
+		}
+		entries = append(entries, DataEntry{Field: "binaryData", Key: key, Value: value})
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries
+}
+
+// configMapSizeExceeds flags ConfigMaps whose values total more than maximum bytes
+// The details give the total and the largest keys
+func configMapSizeExceeds(resource K8sResource, maximum float64) (bool, string) {
+	if resource.Kind != "ConfigMap" {
+		return false, ""
+	}
+
+	entries := parseConfigMapEntries(resource)
+	size := 0
+	for _, entry := range entries {
+		size += len(entry.Value)
+	}
+	if float64(size) <= maximum {
+		return false, ""
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return len(entries[i].Value) > len(entries[j].Value) })
+	var largest []string
+	for i := 0; i < len(entries) && i < 3; i++ {
+		largest = append(largest, fmt.Sprintf("'%s' %s", entries[i].Key, formatBytes(len(entries[i].Value))))
+	}
+	return true, fmt.Sprintf("%s (largest: %s)", formatBytes(size), strings.Join(largest, ", "))
+}
+
+// configMapCredential flags ConfigMap values that match a committed credential
+// family, using the same patterns as Secrets; the values are never echoed
+func configMapCredential(resource K8sResource) (bool, string) {
+	if resource.Kind != "ConfigMap" {
+		return false, ""
+	}
+
+	var matches []string
+	for _, entry := range parseConfigMapEntries(resource) {
+		if entry.Invalid {
+			continue
+		}
+		if family := detectCredential(string(entry.Value)); containsString(committedCredentialFamilies, family) {
+			matches = append(matches, fmt.Sprintf("key '%s' (%s)", entry.Key, family))
+		}
+	}
+	return len(matches) > 0, strings.Join(matches, ", ")
+}
+
+// configMapInvalidKey flags ConfigMap keys the API server would reject
+func configMapInvalidKey(resource K8sResource) (bool, string) {
+	if resource.Kind != "ConfigMap" {
+		return false, ""
+	}
+
+	var keys []string
+	for _, entry := range parseConfigMapEntries(resource) {
+		if !configMapKeyPattern.MatchString(entry.Key) || len(entry.Key) > configMapKeyMaxLength {
+			keys = append(keys, fmt.Sprintf("%q", entry.Key))
+		}
+	}
+	return len(keys) > 0, strings.Join(keys, ", ")
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Expected outcomes of a rule test
+const (
+	ruleTestViolation = "violation"
+	ruleTestPass      = "pass"
+)
+
+// RuleTest is a manifest a rule is expected to report, or to pass
+//
+//	tests:
+//	  - manifest: fixtures/bad.yaml
+//	    expect: violation
+type RuleTest struct {
+	// Manifest is the fixture file, relative to the config that declares it
+	Manifest string `yaml:"manifest"`
+	// Expect is violation or pass
+	Expect string `yaml:"expect"`
+}
+
+// RuleTestResult is the outcome of one rule test
+type RuleTestResult struct {
+	Rule string
+	Test RuleTest
+	// Violations are the findings of the rule on the manifest
+	Violations []Violation
+	// Err is set when the manifest could not be read
+	Err error
+	// Skipped is set for tests of configs loaded from a URL, whose fixtures
+	// are not local files
+	Skipped bool
+}
+
+// Passed reports whether the rule did what the test expects
+func (r RuleTestResult) Passed() bool {
+	if r.Err != nil {
+		return false
+	}
+	if r.Test.Expect == ruleTestViolation {
+		return len(r.Violations) > 0
+	}
+	return len(r.Violations) == 0
+}
+
+// RunRuleTests runs every test of the config's rules, each through an engine
+// that has only the rule under test enabled
+func RunRuleTests(config *RuleConfig, kubeVersion KubeVersion) []RuleTestResult {
+	var results []RuleTestResult
+	for _, rule := range config.Rules {
+		if len(rule.Tests) == 0 {
+			continue
+		}
+
+		single := *config
+		rule.Enabled = nil
+		single.Rules = []Rule{rule}
+		single.DisabledRules = nil
+		engine, err := NewRuleEngine(&single)
+		if err == nil {
+			engine.SetKubeVersion(kubeVersion)
+		}
+
+		for _, test := range rule.Tests {
+			result := RuleTestResult{Rule: rule.Name, Test: test}
+			switch {
+			case isConfigURL(rule.Source):
+				result.Skipped = true
+			case err != nil:
+				result.Err = err
+			default:
+				result.Violations, result.Err = runRuleTest(engine, rule.Name, test.Manifest)
+			}
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// runRuleTest evaluates a manifest, including the cross-resource checks, and
+// returns the findings of the named rule
+func runRuleTest(engine *RuleEngine, rule, manifest string) ([]Violation, error) {
+	resources, err := parseYAMLFile(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	var all []Violation
+	bundle := &Bundle{}
+	for _, resource := range resources {
+		resource.File = manifest
+		all = append(all, engine.EvaluateResource(resource)...)
+		bundle.Resources = append(bundle.Resources, resource)
+	}
+	for _, bv := range engine.EvaluateBundle(bundle) {
+		all = append(all, bv.Violation)
+	}
+
+	// Warnings about ignore annotations naming other rules are not the
+	// rule's findings
+	var violations []Violation
+	for _, v := range all {
+		if v.Rule == rule {
+			violations = append(violations, v)
+		}
+	}
+	return violations, nil
+}
+
+// runConfigCommand runs `kubecheck config validate [path...]`: it loads and
+// validates the config, compiles its Rego policies, and runs its rule tests.
+// Without a path it checks the --config files or the default config
+func runConfigCommand(args, configFiles []string, options ConfigLoadOptions, profileFlag, kubeVersionFlag string) int {
+	if len(args) == 0 || args[0] != "validate" {
+		fmt.Fprintln(os.Stderr, "Usage: kubecheck [options] config validate [path...]")
+		return ExitError
+	}
+
+	paths := args[1:]
+	if len(paths) == 0 {
+		paths = configFiles
+	}
+	if len(paths) == 0 {
+		path := defaultConfigPath()
+		if path == "" {
+			fmt.Fprintln(os.Stderr, "Error: no config file found; pass a path or --config")
+			return ExitError
+		}
+		paths = []string{path}
+	}
+	name := strings.Join(paths, ", ")
+
+	kubeVersion, err := ParseKubeVersion(kubeVersionFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return ExitError
+	}
+
+	config, err := LoadRuleConfig(paths, options)
+	if err == nil {
+		err = config.ApplyProfiles(append(config.Profiles, splitRuleNames(profileFlag)...))
+	}
+	if err == nil && len(config.RegoPolicies) > 0 {
+		_, err = LoadRegoPolicies(config.RegoPolicies)
+	}
+	if err != nil {
+		fmt.Printf("  %s%s%s %s is invalid\n\n", ColorRed, SymbolError, ColorReset, name)
+		fmt.Printf("  %s\n", strings.ReplaceAll(err.Error(), "\n", "\n  "))
+		return ExitError
+	}
+	fmt.Printf("  %s%s%s %s is valid (%d rules)\n", ColorGreen, SymbolOK, ColorReset, name, len(config.Rules))
+
+	results := RunRuleTests(config, kubeVersion)
+	if len(results) == 0 {
+		return ExitOK
+	}
+	fmt.Println()
+	failed := printRuleTestResults(os.Stdout, results)
+	if failed > 0 {
+		return ExitError
+	}
+	return ExitOK
+}
+
+// printRuleTestResults prints one line per rule test and a count, and
+// returns how many tests failed
+func printRuleTestResults(w io.Writer, results []RuleTestResult) int {
+	passed, failed, skipped := 0, 0, 0
+	for _, result := range results {
+		manifest := result.Test.Manifest
+		if rel, err := filepath.Rel(".", manifest); err == nil && !strings.HasPrefix(rel, "..") {
+			manifest = rel
+		}
+
+		switch {
+		case result.Skipped:
+			skipped++
+			fmt.Fprintf(w, "  %s-%s %s: %s skipped, fixtures of a config loaded from a URL are not run\n",
+				ColorGray, ColorReset, result.Rule, manifest)
+		case result.Passed():
+			passed++
+			fmt.Fprintf(w, "  %s%s%s %s: %s %s\n",
+				ColorGreen, SymbolOK, ColorReset, result.Rule, manifest, ruleTestOutcome(result))
+		default:
+			failed++
+			fmt.Fprintf(w, "  %s%s%s %s: %s expected %s, but %s\n",
+				ColorRed, SymbolError, ColorReset, result.Rule, manifest, result.Test.Expect, ruleTestOutcome(result))
+		}
+	}
+
+	fmt.Fprintf(w, "\n  %d test%s: %d passed, %d failed", len(results), pluralize(len(results)), passed, failed)
+	if skipped > 0 {
+		fmt.Fprintf(w, ", %d skipped", skipped)
+	}
+	fmt.Fprintln(w)
+	return failed
+}
+
+// ruleTestOutcome describes what the rule did on a test manifest
+func ruleTestOutcome(result RuleTestResult) string {
+	switch {
+	case result.Err != nil:
+		return fmt.Sprintf("the manifest could not be read: %v", result.Err)
+	case len(result.Violations) == 0:
+		return "passed"
+	default:
+		messages := make([]string, 0, len(result.Violations))
+		for _, v := range result.Violations {
+			messages = append(messages, v.Message)
+		}
+		return fmt.Sprintf("reported %d violation%s: %s", len(result.Violations), pluralize(len(result.Violations)), strings.Join(messages, "; "))
+	}
+}
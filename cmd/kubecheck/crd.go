@@ -0,0 +1,70 @@
+package main
+
+import "strings"
+
+// crdSchema returns the openAPIV3Schema a CustomResourceDefinition declares for
+// the given apiVersion and kind, and false when the CRD does not serve them
+// Both apiextensions.k8s.io/v1 (per-version schemas) and v1beta1 (a top-level
+// validation schema) are read
+func crdSchema(crd K8sResource, apiVersion, kind string) (*Schema, bool) {
+	if crd.Kind != "CustomResourceDefinition" {
+		return nil, false
+	}
+	names, _ := crd.Spec["names"].(map[string]interface{})
+	if getStringValue(names, "kind") != kind {
+		return nil, false
+	}
+	group, version, ok := strings.Cut(apiVersion, "/")
+	if !ok || group != getStringValue(crd.Spec, "group") {
+		return nil, false
+	}
+
+	var schemaMap map[string]interface{}
+	if validation, ok := crd.Spec["validation"].(map[string]interface{}); ok {
+		schemaMap, _ = validation["openAPIV3Schema"].(map[string]interface{})
+	}
+
+	served := getStringValue(crd.Spec, "version") == version
+	versions, _ := crd.Spec["versions"].([]interface{})
+	for _, v := range versions {
+		versionMap, ok := v.(map[string]interface{})
+		if !ok || getStringValue(versionMap, "name") != version {
+			continue
+		}
+		served = true
+		if schema, ok := versionMap["schema"].(map[string]interface{}); ok {
+			if versionSchema, ok := schema["openAPIV3Schema"].(map[string]interface{}); ok {
+				schemaMap = versionSchema
+			}
+		}
+	}
+
+	if !served || schemaMap == nil {
+		return nil, false
+	}
+	return parseSchema(schemaMap), true
+}
+
+// crdSchemaViolations validates a custom resource against the schema of a
+// CustomResourceDefinition in the scan. Resources without a matching CRD,
+// and CRDs without a schema, are left alone
+// The details list each violation with the path of its field
+func crdSchemaViolations(resource K8sResource, bundle *Bundle) (bool, string) {
+	if resource.Kind == "CustomResourceDefinition" {
+		return false, ""
+	}
+
+	for _, other := range bundle.Resources {
+		schema, ok := crdSchema(other, resource.APIVersion, resource.Kind)
+		if !ok {
+			continue
+		}
+
+		// The root always allows apiVersion, kind, and metadata, even when the
+		// schema does not list them
+		schema.EmbeddedResource = true
+		violations := schema.validateObject(resource.Object, "")
+		return len(violations) > 0, strings.Join(violations, "; ")
+	}
+	return false, ""
+}
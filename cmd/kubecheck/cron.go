@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronField describes one field of a standard 5-field cron schedule
+type cronField struct {
+	Name     string
+	Min, Max int
+	// Names maps the upper-case month or weekday names to their values
+	Names map[string]int
+}
+
+// cronFields are the fields of a schedule in order, with the ranges the
+// CronJob controller accepts
+var cronFields = []cronField{
+	{Name: "minute", Min: 0, Max: 59},
+	{Name: "hour", Min: 0, Max: 23},
+	{Name: "day of month", Min: 1, Max: 31},
+	{Name: "month", Min: 1, Max: 12, Names: map[string]int{
+		"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+		"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+	}},
+	{Name: "day of week", Min: 0, Max: 6, Names: map[string]int{
+		"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+	}},
+}
+
+// cronMacros are the predefined schedules Kubernetes accepts in place of the five fields
+var cronMacros = []string{"@yearly", "@annually", "@monthly", "@weekly", "@daily", "@midnight", "@hourly"}
+
+// parseCronSchedule validates a CronJob schedule the way the CronJob controller
+// parses it: five fields, a predefined macro, or "@every <duration>"
+func parseCronSchedule(schedule string) error {
+	schedule = strings.TrimSpace(schedule)
+	if schedule == "" {
+		return fmt.Errorf("schedule is empty")
+	}
+	if strings.HasPrefix(schedule, "TZ=") || strings.HasPrefix(schedule, "CRON_TZ=") {
+		return fmt.Errorf("time zones in the schedule are not supported, set spec.timeZone instead")
+	}
+
+	if strings.HasPrefix(schedule, "@") {
+		if containsString(cronMacros, schedule) {
+			return nil
+		}
+		if every, ok := strings.CutPrefix(schedule, "@every "); ok {
+			duration, err := time.ParseDuration(strings.TrimSpace(every))
+			if err != nil {
+				return fmt.Errorf("failed to parse @every duration: %w", err)
+			}
+			if duration <= 0 {
+				return fmt.Errorf("@every duration must be positive")
+			}
+			return nil
+		}
+		return fmt.Errorf("unrecognized macro %q", schedule)
+	}
+
+	fields := strings.Fields(schedule)
+	if len(fields) != len(cronFields) {
+		return fmt.Errorf("expected 5 fields (minute hour day-of-month month day-of-week), found %d", len(fields))
+	}
+	for i, field := range fields {
+		if err := parseCronField(field, cronFields[i]); err != nil {
+			return fmt.Errorf("%s field %q: %w", cronFields[i].Name, field, err)
+		}
+	}
+	return nil
+}
+
+// parseCronField validates one field: a comma-separated list of *, ?, values,
+// or ranges, each optionally followed by /step
+func parseCronField(field string, spec cronField) error {
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		switch {
+		case rangePart == "*" || rangePart == "?":
+		case strings.Contains(rangePart, "-"):
+			low, high, _ := strings.Cut(rangePart, "-")
+			start, err := parseCronValue(low, spec)
+			if err != nil {
+				return err
+			}
+			end, err := parseCronValue(high, spec)
+			if err != nil {
+				return err
+			}
+			if start > end {
+				return fmt.Errorf("range %s starts after it ends", rangePart)
+			}
+		default:
+			if _, err := parseCronValue(rangePart, spec); err != nil {
+				return err
+			}
+		}
+
+		if hasStep {
+			step, err := strconv.Atoi(stepPart)
+			if err != nil {
+				return fmt.Errorf("step %q is not a number", stepPart)
+			}
+			if step <= 0 {
+				return fmt.Errorf("step must be positive, got %d", step)
+			}
+		}
+	}
+	return nil
+}
+
+// parseCronValue parses a number or name and checks it against the field's range
+func parseCronValue(value string, spec cronField) (int, error) {
+	if n, ok := spec.Names[strings.ToUpper(value)]; ok {
+		return n, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", value)
+	}
+	if n < spec.Min || n > spec.Max {
+		return 0, fmt.Errorf("%d is outside %d-%d", n, spec.Min, spec.Max)
+	}
+	return n, nil
+}
+
+// cronJobSchedule returns a CronJob's spec.schedule, and false for other kinds
+func cronJobSchedule(resource K8sResource) (string, bool) {
+	if resource.Kind != "CronJob" {
+		return "", false
+	}
+	return getStringValue(resource.Spec, "schedule"), true
+}
+
+// invalidCronSchedule flags CronJobs whose schedule the controller cannot parse
+// The details are the schedule and the parser's explanation
+func invalidCronSchedule(resource K8sResource) (bool, string) {
+	schedule, ok := cronJobSchedule(resource)
+	if !ok {
+		return false, ""
+	}
+	if err := parseCronSchedule(schedule); err != nil {
+		return true, fmt.Sprintf("'%s': %v", schedule, err)
+	}
+	return false, ""
+}
+
+// cronScheduleEveryMinute flags CronJobs that run every minute ("* * * * *")
+func cronScheduleEveryMinute(resource K8sResource) (bool, string) {
+	schedule, ok := cronJobSchedule(resource)
+	if !ok {
+		return false, ""
+	}
+	fields := strings.Fields(schedule)
+	if len(fields) != len(cronFields) || parseCronSchedule(schedule) != nil {
+		return false, ""
+	}
+	for _, field := range fields {
+		if field != "*" && field != "?" && field != "*/1" {
+			return false, ""
+		}
+	}
+	return true, schedule
+}
+
+// concurrencyPolicyMissingOrAllow flags CronJobs that leave concurrencyPolicy
+// unset or Allow, so a slow run overlaps the next one
+func concurrencyPolicyMissingOrAllow(resource K8sResource) (bool, string) {
+	if resource.Kind != "CronJob" {
+		return false, ""
+	}
+	policy := getStringValue(resource.Spec, "concurrencyPolicy")
+	if policy == "" {
+		return true, "unset"
+	}
+	return policy == "Allow", policy
+}
+
+// historyLimitMissing flags CronJobs that leave either Job history limit unset
+// The details list the missing fields
+func historyLimitMissing(resource K8sResource) (bool, string) {
+	if resource.Kind != "CronJob" {
+		return false, ""
+	}
+	var missing []string
+	for _, field := range []string{"successfulJobsHistoryLimit", "failedJobsHistoryLimit"} {
+		if _, ok := getIntValue(resource.Spec, field); !ok {
+			missing = append(missing, "spec."+field)
+		}
+	}
+	return len(missing) > 0, strings.Join(missing, ", ")
+}
+
+// startingDeadlineMissing flags CronJobs with concurrencyPolicy Forbid and no
+// startingDeadlineSeconds: every skipped run counts as missed, and after 100
+// missed runs the controller stops scheduling the CronJob
+func startingDeadlineMissing(resource K8sResource) (bool, string) {
+	if resource.Kind != "CronJob" || getStringValue(resource.Spec, "concurrencyPolicy") != "Forbid" {
+		return false, ""
+	}
+	_, ok := getIntValue(resource.Spec, "startingDeadlineSeconds")
+	return !ok, ""
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxExitCode is the highest exit code exitCodes may map a severity to;
+// shells reserve the codes above it
+const maxExitCode = 125
+
+// ParseFailOn reads the --fail-on flag: the lowest severity that fails the
+// scan, ERROR or WARN, or "" when the flag is not set
+func ParseFailOn(value string) (string, error) {
+	switch severity := strings.ToUpper(strings.TrimSpace(value)); severity {
+	case "", SeverityError, SeverityWarn:
+		return severity, nil
+	default:
+		return "", fmt.Errorf("--fail-on must be ERROR or WARN, not %q", value)
+	}
+}
+
+// ExitCode returns the process exit code for a scan whose most severe
+// finding is level: ExitOK, ExitWarn, or ExitError. The config's exitCodes
+// remap the code of each severity, and the command line wins over them:
+// with failOn ERROR warnings pass, with failOn set any severity at or above
+// it fails even when exitCodes maps it to 0, and exitZero passes every scan
+func (c *RuleConfig) ExitCode(level int, failOn string, exitZero bool) int {
+	if exitZero || level == ExitOK {
+		return ExitOK
+	}
+	severity := SeverityError
+	if level == ExitWarn {
+		severity = SeverityWarn
+		if failOn == SeverityError {
+			return ExitOK
+		}
+	}
+
+	code, ok := c.ExitCodes[severity]
+	if !ok || (code == ExitOK && failOn != "") {
+		return level
+	}
+	return code
+}
+
+// validateExitCodes checks that exitCodes only maps ERROR and WARN, to
+// codes a process can return
+func (c *RuleConfig) validateExitCodes(problems *configProblems) {
+	severities := make([]string, 0, len(c.ExitCodes))
+	for severity := range c.ExitCodes {
+		severities = append(severities, severity)
+	}
+	sort.Strings(severities)
+	for _, severity := range severities {
+		if severity != SeverityError && severity != SeverityWarn {
+			problems.add(c.exitCodePositions[severity], "exitCodes: severity %q must be ERROR or WARN", severity)
+		}
+		if code := c.ExitCodes[severity]; code < 0 || code > maxExitCode {
+			problems.add(c.exitCodePositions[severity], "exitCodes: %s code %d must be between 0 and %d", severity, code, maxExitCode)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// fieldPathStep is one step of a field path: a map key, a list index, or
+// [*] for every element of a list or value of a map
+type fieldPathStep struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// parseFieldPath parses a dotted path into the raw resource, such as
+// spec.template.spec.containers[*].image or
+// metadata.annotations["kubecheck.io/ignore"]
+func parseFieldPath(path string) ([]fieldPathStep, error) {
+	if path == "" {
+		return nil, fmt.Errorf("empty field path")
+	}
+
+	var steps []fieldPathStep
+	for i := 0; i < len(path); {
+		switch c := path[i]; {
+		case c == '.':
+			if i == 0 || i == len(path)-1 || path[i+1] == '.' || path[i+1] == '[' {
+				return nil, fmt.Errorf("field path %q: misplaced \".\"", path)
+			}
+			i++
+		case c == '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("field path %q: unclosed \"[\"", path)
+			}
+			inner := path[i+1 : i+end]
+			if strings.HasPrefix(inner, `"`) {
+				// A quoted key may hold "]", so find the closing quote first
+				key, rest, err := unquoteFieldKey(path[i+1:])
+				if err != nil {
+					return nil, fmt.Errorf("field path %q: %w", path, err)
+				}
+				if !strings.HasPrefix(rest, "]") {
+					return nil, fmt.Errorf("field path %q: expected \"]\" after %q", path, key)
+				}
+				steps = append(steps, fieldPathStep{key: key})
+				i = len(path) - len(rest) + 1
+				continue
+			}
+			switch index, err := strconv.Atoi(inner); {
+			case inner == "*":
+				steps = append(steps, fieldPathStep{wildcard: true})
+			case err == nil && index >= 0:
+				steps = append(steps, fieldPathStep{index: index, isIndex: true})
+			default:
+				return nil, fmt.Errorf("field path %q: [%s] must be [*], an index, or a quoted key", path, inner)
+			}
+			i += end + 1
+		default:
+			end := strings.IndexAny(path[i:], ".[")
+			if end < 0 {
+				end = len(path) - i
+			}
+			steps = append(steps, fieldPathStep{key: path[i : i+end]})
+			i += end
+		}
+	}
+	return steps, nil
+}
+
+// unquoteFieldKey reads the quoted key at the start of text and returns it
+// with the text that follows
+func unquoteFieldKey(text string) (string, string, error) {
+	for i := 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '"':
+			key, err := strconv.Unquote(text[:i+1])
+			if err != nil {
+				return "", "", fmt.Errorf("invalid quoted key %s", text[:i+1])
+			}
+			return key, text[i+1:], nil
+		}
+	}
+	return "", "", fmt.Errorf("unterminated quoted key")
+}
+
+// fieldValue is a concrete location a field path leads to; found is false
+// when the field, or a map on the way to it, is not set
+type fieldValue struct {
+	path  string
+	value interface{}
+	found bool
+}
+
+// resolveFieldPath returns every location the path leads to in the
+// object, one per element or map value for [*]. A [*] over an empty list
+// leads nowhere,
+// while a missing field yields a single location that is not found
+func resolveFieldPath(object map[string]interface{}, steps []fieldPathStep) []fieldValue {
+	var values []fieldValue
+	var walk func(current interface{}, path string, steps []fieldPathStep)
+	walk = func(current interface{}, path string, steps []fieldPathStep) {
+		if len(steps) == 0 {
+			values = append(values, fieldValue{path: path, value: current, found: true})
+			return
+		}
+
+		step := steps[0]
+		switch {
+		case step.wildcard:
+			switch current := current.(type) {
+			case []interface{}:
+				for i, element := range current {
+					walk(element, fmt.Sprintf("%s[%d]", path, i), steps[1:])
+				}
+			case map[string]interface{}:
+				keys := make([]string, 0, len(current))
+				for key := range current {
+					keys = append(keys, key)
+				}
+				sort.Strings(keys)
+				for _, key := range keys {
+					walk(current[key], joinFieldPath(path, key), steps[1:])
+				}
+			default:
+				values = append(values, fieldValue{path: path + "[*]"})
+			}
+		case step.isIndex:
+			list, ok := current.([]interface{})
+			elementPath := fmt.Sprintf("%s[%d]", path, step.index)
+			if !ok || step.index >= len(list) {
+				values = append(values, fieldValue{path: elementPath})
+				return
+			}
+			walk(list[step.index], elementPath, steps[1:])
+		default:
+			keyPath := joinFieldPath(path, step.key)
+			fields, ok := current.(map[string]interface{})
+			if !ok {
+				values = append(values, fieldValue{path: keyPath})
+				return
+			}
+			next, ok := fields[step.key]
+			if !ok {
+				values = append(values, fieldValue{path: keyPath})
+				return
+			}
+			walk(next, keyPath, steps[1:])
+		}
+	}
+	walk(object, "", steps)
+	return values
+}
+
+// joinFieldPath appends a key to a path, quoting keys that hold dots or
+// brackets
+func joinFieldPath(path, key string) string {
+	if strings.ContainsAny(key, ".[]\"") {
+		return path + "[" + strconv.Quote(key) + "]"
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// fieldValueEquals compares a field with a value from the config: booleans
+// and numbers by value, so "false" matches false and "1" matches 1.0, and
+// strings as written. "null" matches a field set to null
+func fieldValueEquals(actual interface{}, expected string) bool {
+	switch actual := actual.(type) {
+	case nil:
+		return expected == "null"
+	case bool:
+		b, err := strconv.ParseBool(expected)
+		return err == nil && b == actual
+	case int, int64, float64:
+		number, err := strconv.ParseFloat(expected, 64)
+		return err == nil && number == toFloat(actual)
+	case string:
+		return actual == expected
+	default:
+		return false
+	}
+}
+
+// toFloat converts a decoded YAML number to float64
+func toFloat(value interface{}) float64 {
+	switch value := value.(type) {
+	case int:
+		return float64(value)
+	case int64:
+		return float64(value)
+	case float64:
+		return value
+	default:
+		return 0
+	}
+}
+
+// fieldValueString formats a field value for messages; maps and lists are
+// summarised rather than printed
+func fieldValueString(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return value
+	case map[string]interface{}:
+		return "a map"
+	case []interface{}:
+		return "a list"
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// fieldMissing reports the locations of a field path that are not set
+func fieldMissing(resource K8sResource, steps []fieldPathStep) (bool, string) {
+	var missing []string
+	for _, v := range resolveFieldPath(resource.Object, steps) {
+		if !v.found {
+			missing = append(missing, v.path)
+		}
+	}
+	return len(missing) > 0, strings.Join(missing, ", ")
+}
+
+// fieldEquals reports the locations of a field path whose value equals the
+// given one, or with negate, those that differ from it or are not set
+func fieldEquals(resource K8sResource, steps []fieldPathStep, expected string, negate bool) (bool, string) {
+	var matched []string
+	for _, v := range resolveFieldPath(resource.Object, steps) {
+		equal := v.found && fieldValueEquals(v.value, expected)
+		switch {
+		case !negate && equal:
+			matched = append(matched, v.path+"="+expected)
+		case negate && !v.found:
+			matched = append(matched, v.path+" is not set")
+		case negate && !equal:
+			matched = append(matched, v.path+"="+fieldValueString(v.value))
+		}
+	}
+	return len(matched) > 0, strings.Join(matched, ", ")
+}
+
+// isFieldPatternCondition reports whether a condition type is
+// field_matches or field_not_matches, which report each matching field
+func isFieldPatternCondition(conditionType string) bool {
+	return conditionType == "field_matches" || conditionType == "field_not_matches"
+}
+
+// fieldPatternCondition returns the fields a field_matches or
+// field_not_matches condition reports on a resource
+func fieldPatternCondition(condition Condition, resource K8sResource) []fieldValue {
+	return fieldPatternMatches(resource, condition.path, condition.pattern, condition.Type == "field_not_matches")
+}
+
+// fieldPatternMatches returns the locations of a field path whose value
+// matches the pattern, or with negate, those whose value does not. Unset
+// fields, maps, and lists are skipped; numbers and booleans are matched as
+// written
+func fieldPatternMatches(resource K8sResource, steps []fieldPathStep, pattern *regexp.Regexp, negate bool) []fieldValue {
+	var matched []fieldValue
+	for _, v := range resolveFieldPath(resource.Object, steps) {
+		switch v.value.(type) {
+		case map[string]interface{}, []interface{}:
+			continue
+		}
+		if v.found && pattern.MatchString(fieldValueString(v.value)) != negate {
+			matched = append(matched, v)
+		}
+	}
+	return matched
+}
+
+// parseFieldPathArg accepts a field path
+func parseFieldPathArg(c *Condition, value string) error {
+	var err error
+	c.path, err = parseFieldPath(value)
+	return err
+}
+
+// parseFieldComparisonArg accepts a PATH=VALUE pair
+func parseFieldComparisonArg(c *Condition, value string) error {
+	path, operand, ok := strings.Cut(value, "=")
+	if !ok {
+		return fmt.Errorf("value %q must be PATH=VALUE", value)
+	}
+	c.operand = operand
+	return parseFieldPathArg(c, path)
+}
+
+// parseFieldPatternArg accepts a PATH~REGEX pair
+func parseFieldPatternArg(c *Condition, value string) error {
+	path, pattern, ok := strings.Cut(value, "~")
+	if !ok {
+		return fmt.Errorf("value %q must be PATH~REGEX", value)
+	}
+	if err := parseFieldPathArg(c, path); err != nil {
+		return err
+	}
+	return parsePatternArg(c, pattern)
+}
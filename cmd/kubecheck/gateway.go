@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// gatewayAPIGroup is the API group of the Gateway API kinds
+const gatewayAPIGroup = "gateway.networking.k8s.io"
+
+// gatewayRouteKinds are the Gateway API kinds that attach to Gateways through parentRefs
+var gatewayRouteKinds = []string{"HTTPRoute", "GRPCRoute", "TLSRoute", "TCPRoute", "UDPRoute"}
+
+// isGatewayKind reports whether a resource is the given Gateway API kind
+func isGatewayKind(resource K8sResource, kinds ...string) bool {
+	return apiGroup(resource.APIVersion) == gatewayAPIGroup && containsString(kinds, resource.Kind)
+}
+
+// GatewayListener is one entry of a Gateway's spec.listeners
+type GatewayListener struct {
+	Name     string
+	Port     int
+	Protocol string
+	Hostname string
+	// TLS is nil when the listener has no tls block
+	TLS *GatewayListenerTLS
+}
+
+// GatewayListenerTLS is the tls block of a Gateway listener
+type GatewayListenerTLS struct {
+	Mode            string // Terminate (the default) or Passthrough
+	CertificateRefs int
+}
+
+// parseGatewayListeners reads the listeners of a Gateway
+func parseGatewayListeners(resource K8sResource) []GatewayListener {
+	listeners, _ := resource.Spec["listeners"].([]interface{})
+
+	var result []GatewayListener
+	for _, l := range listeners {
+		listenerMap, ok := l.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		listener := GatewayListener{
+			Name:     getStringValue(listenerMap, "name"),
+			Protocol: getStringValue(listenerMap, "protocol"),
+			Hostname: getStringValue(listenerMap, "hostname"),
+		}
+		listener.Port, _ = getIntValue(listenerMap, "port")
+		if tlsMap, ok := listenerMap["tls"].(map[string]interface{}); ok {
+			refs, _ := tlsMap["certificateRefs"].([]interface{})
+			listener.TLS = &GatewayListenerTLS{Mode: getStringValue(tlsMap, "mode"), CertificateRefs: len(refs)}
+			if listener.TLS.Mode == "" {
+				listener.TLS.Mode = "Terminate"
+			}
+		}
+		result = append(result, listener)
+	}
+	return result
+}
+
+// gatewayListenerTLSMissing flags Gateway listeners on port 443, or with the
+// HTTPS or TLS protocol, that do not configure TLS: plain HTTP or TCP on 443,
+// no tls block, or a terminating listener without certificateRefs
+// The details name each listener and what it lacks
+func gatewayListenerTLSMissing(resource K8sResource) (bool, string) {
+	if !isGatewayKind(resource, "Gateway") {
+		return false, ""
+	}
+
+	var problems []string
+	for _, listener := range parseGatewayListeners(resource) {
+		secureProtocol := listener.Protocol == "HTTPS" || listener.Protocol == "TLS"
+		if listener.Port != 443 && !secureProtocol {
+			continue
+		}
+
+		label := fmt.Sprintf("listener '%s' (%s on %d)", listener.Name, listener.Protocol, listener.Port)
+		switch {
+		case !secureProtocol:
+			problems = append(problems, label+" serves plaintext")
+		case listener.TLS == nil:
+			problems = append(problems, label+" has no tls block")
+		case listener.TLS.Mode == "Terminate" && listener.TLS.CertificateRefs == 0:
+			problems = append(problems, label+" terminates TLS without certificateRefs")
+		}
+	}
+	return len(problems) > 0, strings.Join(problems, ", ")
+}
+
+// gatewayRouteParentRefsMissing flags routes without spec.parentRefs, which
+// attach to no Gateway and so serve no traffic
+func gatewayRouteParentRefsMissing(resource K8sResource) (bool, string) {
+	if !isGatewayKind(resource, gatewayRouteKinds...) {
+		return false, ""
+	}
+	parentRefs, _ := resource.Spec["parentRefs"].([]interface{})
+	return len(parentRefs) == 0, ""
+}
+
+// gatewayHostWildcard flags wildcard hostnames on Gateway listeners and on
+// routes; the details list each hostname and where it is set
+func gatewayHostWildcard(resource K8sResource) (bool, string) {
+	var wildcards []string
+	switch {
+	case isGatewayKind(resource, "Gateway"):
+		for _, listener := range parseGatewayListeners(resource) {
+			if strings.HasPrefix(listener.Hostname, "*") {
+				wildcards = append(wildcards, fmt.Sprintf("%s (listener '%s')", listener.Hostname, listener.Name))
+			}
+		}
+	case isGatewayKind(resource, gatewayRouteKinds...):
+		for _, hostname := range getStringList(resource.Spec, "hostnames") {
+			if strings.HasPrefix(hostname, "*") && !containsString(wildcards, hostname) {
+				wildcards = append(wildcards, hostname)
+			}
+		}
+	}
+	return len(wildcards) > 0, strings.Join(wildcards, ", ")
+}
+
+// GatewayBackendRef is a Service a route forwards to
+type GatewayBackendRef struct {
+	Name      string
+	Namespace string
+}
+
+// gatewayRouteBackendRefs reads the Service backendRefs of every route rule,
+// defaulting the namespace to the route's own; other backend kinds are skipped
+func gatewayRouteBackendRefs(resource K8sResource) []GatewayBackendRef {
+	rules, _ := resource.Spec["rules"].([]interface{})
+
+	var refs []GatewayBackendRef
+	for _, r := range rules {
+		ruleMap, _ := r.(map[string]interface{})
+		backendRefs, _ := ruleMap["backendRefs"].([]interface{})
+		for _, b := range backendRefs {
+			refMap, ok := b.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			kind, group := getStringValue(refMap, "kind"), getStringValue(refMap, "group")
+			if (kind != "" && kind != "Service") || group != "" {
+				continue
+			}
+			ref := GatewayBackendRef{Name: getStringValue(refMap, "name"), Namespace: getStringValue(refMap, "namespace")}
+			if ref.Namespace == "" {
+				ref.Namespace = getResourceNamespace(resource)
+			}
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// gatewayBackendMissing flags routes whose backendRefs name a Service the
+// scan does not hold. A single-resource scan never holds the Services, so it
+// is skipped. The details list each missing Service once
+func gatewayBackendMissing(resource K8sResource, bundle *Bundle) (bool, string) {
+	if !isGatewayKind(resource, gatewayRouteKinds...) || len(bundle.Resources) < 2 {
+		return false, ""
+	}
+
+	var missing []string
+	for _, ref := range gatewayRouteBackendRefs(resource) {
+		found := false
+		for _, other := range bundle.Resources {
+			if other.Kind == "Service" && apiGroup(other.APIVersion) == "" &&
+				getResourceName(other) == ref.Name && getResourceNamespace(other) == ref.Namespace {
+				found = true
+				break
+			}
+		}
+		description := fmt.Sprintf("Service '%s' in namespace '%s'", ref.Name, ref.Namespace)
+		if !found && !containsString(missing, description) {
+			missing = append(missing, description)
+		}
+	}
+	return len(missing) > 0, strings.Join(missing, ", ")
+}
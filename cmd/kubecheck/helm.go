@@ -35,7 +35,7 @@ func processHelmChart(chartPath string) ([]string, error) {
 			files = append(files, path)
 		}
 		return nil
-	})
+	}, nil)
 
 	if err != nil {
 		return nil, err
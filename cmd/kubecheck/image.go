@@ -0,0 +1,139 @@
+package main
+
+import (
+	"strings"
+)
+
+// defaultRegistry is the registry used for images without a registry host
+const defaultRegistry = "docker.io"
+
+// defaultRegistryAliases are hosts that name the default registry explicitly
+var defaultRegistryAliases = []string{"docker.io", "index.docker.io"}
+
+// publicRegistries are registries that only serve public images, so images
+// pulled from them never need imagePullSecrets
+var publicRegistries = []string{"registry.k8s.io", "k8s.gcr.io", "public.ecr.aws", "mcr.microsoft.com"}
+
+// ImageReference is a container image reference split into its parts
+type ImageReference struct {
+	Registry   string // registry host, "" when the image relies on the default registry
+	Repository string // path within the registry, e.g. library/nginx
+	Tag        string
+	Digest     string // e.g. sha256:abc..., "" when the image is not pinned
+}
+
+// parseImageReference decomposes an image reference such as
+// registry.local:5000/team/app:1.2@sha256:abc
+func parseImageReference(image string) ImageReference {
+	var ref ImageReference
+
+	name := image
+	if at := strings.Index(name, "@"); at >= 0 {
+		ref.Digest = name[at+1:]
+		name = name[:at]
+	}
+
+	// A tag is a colon after the last slash; earlier colons belong to a registry port
+	lastSlash := strings.LastIndex(name, "/")
+	if colon := strings.LastIndex(name, ":"); colon > lastSlash {
+		ref.Tag = name[colon+1:]
+		name = name[:colon]
+	}
+
+	// The first component is a registry host if it looks like one
+	if slash := strings.Index(name, "/"); slash >= 0 {
+		first := name[:slash]
+		if strings.ContainsAny(first, ".:") || first == "localhost" {
+			ref.Registry = first
+			if containsString(defaultRegistryAliases, first) {
+				ref.Registry = defaultRegistry
+			}
+			name = name[slash+1:]
+		}
+	}
+
+	// Official images live under library/ whether or not the registry is spelled out
+	ref.Repository = name
+	if (ref.Registry == "" || ref.Registry == defaultRegistry) && !strings.Contains(name, "/") {
+		ref.Repository = "library/" + name
+	}
+
+	return ref
+}
+
+// Name returns the fully qualified repository name, e.g. docker.io/library/nginx
+func (ref ImageReference) Name() string {
+	registry := ref.Registry
+	if registry == "" {
+		registry = defaultRegistry
+	}
+	return registry + "/" + ref.Repository
+}
+
+// imageRegistryNotIn reports whether an image's repository falls outside
+// every allowed prefix
+func imageRegistryNotIn(image string, allowedPrefixes []string) (bool, string) {
+	if image == "" {
+		return false, ""
+	}
+
+	name := parseImageReference(image).Name()
+	for _, prefix := range allowedPrefixes {
+		if hasRepositoryPrefix(name, prefix) {
+			return false, ""
+		}
+	}
+
+	return true, name
+}
+
+// hasRepositoryPrefix reports whether a fully qualified repository name starts
+// with a prefix that ends on a path boundary, so ghcr.io/org does not match
+// ghcr.io/org-evil/app
+func hasRepositoryPrefix(name, prefix string) bool {
+	prefix = strings.TrimSuffix(strings.TrimSpace(prefix), "/")
+	if prefix == "" {
+		return false
+	}
+	return name == prefix || strings.HasPrefix(name, prefix+"/")
+}
+
+// imageNotFullyQualified reports whether an image relies on the default registry
+// because it names no registry host, returning the name it resolves to
+func imageNotFullyQualified(image string) (bool, string) {
+	if image == "" {
+		return false, ""
+	}
+
+	ref := parseImageReference(image)
+	if ref.Registry != "" {
+		return false, ""
+	}
+	return true, ref.Name()
+}
+
+// imagePrivateRegistry reports whether an image's repository starts with any
+// private prefix, returning its registry host
+func imagePrivateRegistry(image string, privatePrefixes []string) (string, bool) {
+	if image == "" {
+		return "", false
+	}
+
+	ref := parseImageReference(image)
+	registry := ref.Registry
+	if registry == "" {
+		registry = defaultRegistry
+	}
+	if containsString(publicRegistries, registry) {
+		return "", false
+	}
+
+	name := ref.Name()
+	for _, prefix := range privatePrefixes {
+		if hasRepositoryPrefix(name, prefix) {
+			return registry, true
+		}
+	}
+
+	return "", false
+}
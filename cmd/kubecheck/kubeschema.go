@@ -0,0 +1,360 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// schemaRule is the rule name of schema violations
+const schemaRule = "kubernetes-schema"
+
+// definitionsFile holds the shared definitions of non-standalone schema directories
+const definitionsFile = "_definitions.json"
+
+// SchemaStore loads the upstream Kubernetes JSON schemas of one version from a
+// local directory, in the layout of the kubernetes-json-schema project used by
+// kubeconform: v1.29.0-standalone-strict/deployment-apps-v1.json. A directory
+// that holds the schema files directly works too
+type SchemaStore struct {
+	dirs        []string
+	version     KubeVersion
+	cache       map[string]*Schema
+	definitions map[string]map[string]interface{}
+}
+
+// builtInAPIGroups are the API groups served by Kubernetes itself, whose
+// kinds must have a schema; kinds of other groups are custom resources. Each
+// group lists its cluster-scoped kinds, which have no namespace
+var builtInAPIGroups = map[string][]string{
+	"":                             {"Namespace", "Node", "PersistentVolume", "ComponentStatus"},
+	"admissionregistration.k8s.io": {"MutatingWebhookConfiguration", "ValidatingWebhookConfiguration", "ValidatingAdmissionPolicy", "ValidatingAdmissionPolicyBinding", "MutatingAdmissionPolicy", "MutatingAdmissionPolicyBinding"},
+	"apiextensions.k8s.io":         {"CustomResourceDefinition"},
+	"apiregistration.k8s.io":       {"APIService"},
+	"apps":                         nil,
+	"authentication.k8s.io":        {"TokenReview", "SelfSubjectReview"},
+	"authorization.k8s.io":         {"SubjectAccessReview", "SelfSubjectAccessReview", "SelfSubjectRulesReview"},
+	"autoscaling":                  nil,
+	"batch":                        nil,
+	"certificates.k8s.io":          {"CertificateSigningRequest", "ClusterTrustBundle"},
+	"coordination.k8s.io":          nil,
+	"discovery.k8s.io":             nil,
+	"events.k8s.io":                nil,
+	"extensions":                   {"PodSecurityPolicy"},
+	"flowcontrol.apiserver.k8s.io": {"FlowSchema", "PriorityLevelConfiguration"},
+	"networking.k8s.io":            {"IngressClass", "IPAddress", "ServiceCIDR"},
+	"node.k8s.io":                  {"RuntimeClass"},
+	"policy":                       {"PodSecurityPolicy"},
+	"rbac.authorization.k8s.io":    {"ClusterRole", "ClusterRoleBinding"},
+	"resource.k8s.io":              {"DeviceClass", "ResourceSlice"},
+	"scheduling.k8s.io":            {"PriorityClass"},
+	"storage.k8s.io":               {"StorageClass", "CSIDriver", "CSINode", "VolumeAttachment", "VolumeAttributesClass"},
+	"storagemigration.k8s.io":      {"StorageVersionMigration"},
+}
+
+// isBuiltInKind reports whether an apiVersion belongs to a built-in API group
+func isBuiltInKind(apiVersion string) bool {
+	group, _, ok := strings.Cut(apiVersion, "/")
+	if !ok {
+		group = ""
+	}
+	_, ok = builtInAPIGroups[group]
+	return ok
+}
+
+// NewSchemaStore finds the schema directories for the version under dir. It
+// fails when dir has neither a directory for the version nor schema files of
+// its own, so a missing download cannot pass every resource
+func NewSchemaStore(dir string, version KubeVersion) (*SchemaStore, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open schema directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("failed to open schema directory: %s is not a directory", dir)
+	}
+
+	matches, err := filepath.Glob(filepath.Join(dir, fmt.Sprintf("v%d.%d.*", version.Major, version.Minor)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schema directory: %w", err)
+	}
+	// Strict schemas reject unknown fields, so they are preferred over the
+	// others, and later patch releases over earlier ones
+	sort.SliceStable(matches, func(i, j int) bool {
+		si, sj := schemaDirRank(matches[i]), schemaDirRank(matches[j])
+		if si != sj {
+			return si < sj
+		}
+		return matches[i] > matches[j]
+	})
+
+	store := &SchemaStore{
+		version:     version,
+		cache:       make(map[string]*Schema),
+		definitions: make(map[string]map[string]interface{}),
+	}
+	for _, m := range matches {
+		if info, err := os.Stat(m); err == nil && info.IsDir() {
+			store.dirs = append(store.dirs, m)
+		}
+	}
+	if len(store.dirs) == 0 {
+		files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list schema directory: %w", err)
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no Kubernetes %s schemas in %s: expected a v%d.%d.* directory or schema files", version, dir, version.Major, version.Minor)
+		}
+	} else if schemaDirRank(store.dirs[0]) != 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %s is not a -standalone-strict schema directory, so unknown fields are not reported\n", store.dirs[0])
+	}
+	store.dirs = append(store.dirs, dir)
+	return store, nil
+}
+
+// schemaDirRank orders schema directory variants, lowest first
+func schemaDirRank(dir string) int {
+	switch {
+	case strings.HasSuffix(dir, "-standalone-strict"):
+		return 0
+	case strings.HasSuffix(dir, "-standalone"):
+		return 1
+	default:
+		return 2
+	}
+}
+
+// schemaFileName names the schema file of a kind, e.g. deployment-apps-v1.json
+// for apps/v1 and service-v1.json for the core group
+func schemaFileName(apiVersion, kind string) string {
+	name := strings.ToLower(kind)
+	group, version, ok := strings.Cut(apiVersion, "/")
+	if !ok {
+		return fmt.Sprintf("%s-%s.json", name, strings.ToLower(apiVersion))
+	}
+	group, _, _ = strings.Cut(group, ".")
+	return fmt.Sprintf("%s-%s-%s.json", name, strings.ToLower(group), strings.ToLower(version))
+}
+
+// Lookup returns the schema of a kind, or nil when the directory has none,
+// as for custom resources
+func (s *SchemaStore) Lookup(apiVersion, kind string) (*Schema, error) {
+	fileName := schemaFileName(apiVersion, kind)
+	if schema, ok := s.cache[fileName]; ok {
+		return schema, nil
+	}
+
+	var schema *Schema
+	for _, dir := range s.dirs {
+		path := filepath.Join(dir, fileName)
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read schema %s: %w", path, err)
+		}
+
+		var schemaMap map[string]interface{}
+		if err := yaml.Unmarshal(data, &schemaMap); err != nil {
+			return nil, fmt.Errorf("failed to parse schema %s: %w", path, err)
+		}
+		resolver := &jsonSchemaResolver{store: s, dir: dir, parsed: make(map[string]*Schema)}
+		if schema, err = resolver.parse(schemaMap, schemaMap); err != nil {
+			return nil, fmt.Errorf("failed to parse schema %s: %w", path, err)
+		}
+		break
+	}
+
+	s.cache[fileName] = schema
+	return schema, nil
+}
+
+// Validate checks a resource against the schema of its kind and returns one
+// violation per problem, each naming the JSON path of the field
+// Custom resources without a schema are skipped, but a built-in kind without
+// one is reported, since its apiVersion is unknown to the version
+func (s *SchemaStore) Validate(resource K8sResource) ([]Violation, error) {
+	if resource.APIVersion == "" || resource.Kind == "" {
+		return nil, nil
+	}
+	schema, err := s.Lookup(resource.APIVersion, resource.Kind)
+	if err != nil {
+		return nil, err
+	}
+	if schema == nil {
+		if !isBuiltInKind(resource.APIVersion) {
+			return nil, nil
+		}
+		return []Violation{{
+			Severity: "ERROR",
+			Rule:     schemaRule,
+			Message:  fmt.Sprintf("%s '%s' has no Kubernetes %s schema for %s", resource.Kind, getResourceName(resource), s.version, resource.APIVersion),
+			Help:     fmt.Sprintf("check that %s serves %s %s, and that --schema-dir holds its %s", s.version, resource.APIVersion, resource.Kind, schemaFileName(resource.APIVersion, resource.Kind)),
+		}}, nil
+	}
+
+	var violations []Violation
+	for _, problem := range schema.Validate(resource.Object, "") {
+		violations = append(violations, Violation{
+			Severity: "ERROR",
+			Rule:     schemaRule,
+			Message:  fmt.Sprintf("%s '%s' does not match the Kubernetes %s schema: %s", resource.Kind, getResourceName(resource), s.version, problem),
+			Help:     fmt.Sprintf("check the field against the %s %s API reference", resource.APIVersion, resource.Kind),
+		})
+	}
+	return violations, nil
+}
+
+// jsonSchemaResolver parses one schema file, following $ref into its own
+// definitions or the directory's _definitions.json
+type jsonSchemaResolver struct {
+	store  *SchemaStore
+	dir    string
+	parsed map[string]*Schema
+}
+
+// parse reads a JSON schema found in document, which local $refs point into
+// Unlike the structural schemas of CRDs, JSON schemas allow unknown fields
+// unless additionalProperties is false
+func (r *jsonSchemaResolver) parse(schemaMap, document map[string]interface{}) (*Schema, error) {
+	if ref := getStringValue(schemaMap, "$ref"); ref != "" {
+		return r.resolve(ref, document)
+	}
+
+	schema := &Schema{
+		Required:              getStringList(schemaMap, "required"),
+		PreserveUnknownFields: getBoolValue(schemaMap, "x-kubernetes-preserve-unknown-fields"),
+		IntOrString:           getBoolValue(schemaMap, "x-kubernetes-int-or-string") || getStringValue(schemaMap, "format") == "int-or-string",
+		EmbeddedResource:      getBoolValue(schemaMap, "x-kubernetes-embedded-resource"),
+		Nullable:              getBoolValue(schemaMap, "nullable"),
+		AllowAdditional:       true,
+	}
+	schema.Enum, _ = schemaMap["enum"].([]interface{})
+
+	// The kubernetes-json-schema files spell nullable fields as a type list
+	switch t := schemaMap["type"].(type) {
+	case string:
+		schema.Type = t
+	case []interface{}:
+		for _, v := range t {
+			if name, _ := v.(string); name == "null" {
+				schema.Nullable = true
+			} else if schema.Type == "" {
+				schema.Type = name
+			}
+		}
+	}
+	if oneOf, ok := schemaMap["oneOf"].([]interface{}); ok && isIntOrStringOneOf(oneOf) {
+		schema.IntOrString = true
+	}
+
+	if properties, ok := schemaMap["properties"].(map[string]interface{}); ok {
+		schema.Properties = make(map[string]*Schema, len(properties))
+		for name, p := range properties {
+			propertyMap, _ := p.(map[string]interface{})
+			property, err := r.parse(propertyMap, document)
+			if err != nil {
+				return nil, err
+			}
+			schema.Properties[name] = property
+		}
+	}
+	if items, ok := schemaMap["items"].(map[string]interface{}); ok {
+		itemSchema, err := r.parse(items, document)
+		if err != nil {
+			return nil, err
+		}
+		schema.Items = itemSchema
+	}
+	switch additional := schemaMap["additionalProperties"].(type) {
+	case bool:
+		schema.AllowAdditional = additional
+	case map[string]interface{}:
+		additionalSchema, err := r.parse(additional, document)
+		if err != nil {
+			return nil, err
+		}
+		schema.AdditionalProperties = additionalSchema
+	}
+
+	return schema, nil
+}
+
+// resolve parses the schema a $ref points at. Each definition is parsed once,
+// so recursive definitions such as JSONSchemaProps end up as cycles
+func (r *jsonSchemaResolver) resolve(ref string, document map[string]interface{}) (*Schema, error) {
+	file, pointer, _ := strings.Cut(ref, "#")
+	if file != "" {
+		var err error
+		if document, err = r.store.loadDefinitions(filepath.Join(r.dir, file)); err != nil {
+			return nil, err
+		}
+	}
+	// Local refs of the standalone files and of _definitions.json never mix
+	// within one schema, so the pointer alone identifies the definition
+	if schema, ok := r.parsed[pointer]; ok {
+		return schema, nil
+	}
+
+	var target interface{} = document
+	for _, part := range strings.Split(strings.Trim(pointer, "/"), "/") {
+		if part == "" {
+			continue
+		}
+		parent, _ := target.(map[string]interface{})
+		target = parent[strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")]
+	}
+	targetMap, ok := target.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("failed to resolve $ref %q", ref)
+	}
+
+	// Register the schema before parsing it so references back to it resolve
+	schema := &Schema{}
+	r.parsed[pointer] = schema
+	parsed, err := r.parse(targetMap, document)
+	if err != nil {
+		return nil, err
+	}
+	*schema = *parsed
+	return schema, nil
+}
+
+// loadDefinitions reads a shared definitions file once per scan
+func (s *SchemaStore) loadDefinitions(path string) (map[string]interface{}, error) {
+	if document, ok := s.definitions[path]; ok {
+		return document, nil
+	}
+	if filepath.Base(path) != definitionsFile {
+		return nil, fmt.Errorf("failed to resolve $ref to %s: only %s is supported", filepath.Base(path), definitionsFile)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema definitions: %w", err)
+	}
+	var document map[string]interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse schema definitions %s: %w", path, err)
+	}
+	s.definitions[path] = document
+	return document, nil
+}
+
+// isIntOrStringOneOf reports whether a oneOf lists exactly a string and an
+// integer type, which is how int-or-string fields are written in JSON schemas
+func isIntOrStringOneOf(oneOf []interface{}) bool {
+	var types []string
+	for _, option := range oneOf {
+		optionMap, _ := option.(map[string]interface{})
+		types = append(types, getStringValue(optionMap, "type"))
+	}
+	sort.Strings(types)
+	return len(types) == 2 && types[0] == "integer" && types[1] == "string"
+}
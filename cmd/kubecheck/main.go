@@ -1,10 +1,13 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 const (
@@ -20,7 +23,19 @@ type Config struct {
 func main() {
 	// Parse command line flags
 	verbose := flag.Bool("v", false, "Verbose output")
-	configFile := flag.String("config", "", "Path to kubecheck config file (default: ./kubecheck.yaml or ~/.kubecheck/config.yaml)")
+	var configFiles stringList
+	flag.Var(&configFiles, "config", "Path, directory, or HTTPS URL of a kubecheck config; repeat to merge several, later ones overriding earlier (default: ./kubecheck.yaml, ./.kubecheck/, or ~/.kubecheck/config.yaml)")
+	configSHA256 := flag.String("config-sha256", "", "Expected hex SHA-256 of the --config file; kubecheck stops when it differs")
+	kubeVersionFlag := flag.String("kube-version", defaultKubeVersion, "Kubernetes version to check apiVersions against")
+	assumeCompleteBundle := flag.Bool("assume-complete-bundle", false, "Treat the scan as holding every referenced ConfigMap, Secret, and ServiceAccount")
+	validateSchema := flag.Bool("validate-schema", false, "Validate resources against the Kubernetes JSON schemas for --kube-version")
+	schemaDir := flag.String("schema-dir", filepath.Join(os.Getenv("HOME"), ".kubecheck", "schemas"), "Directory holding the Kubernetes JSON schemas for --validate-schema")
+	noInlineIgnores := flag.Bool("no-inline-ignores", false, "Ignore kubecheck.io/ignore annotations and kubecheck-ignore comments, reporting every finding")
+	profileFlag := flag.String("profile", "", "Comma-separated built-in rule profiles to add (pss-baseline, pss-restricted)")
+	tagsFlag := flag.String("tags", "", "Comma-separated tags; only rules with one of them run (e.g. security,cost)")
+	skipTagsFlag := flag.String("skip-tags", "", "Comma-separated tags; rules with any of them are skipped")
+	failOnFlag := flag.String("fail-on", "", "Lowest severity that fails the scan: ERROR (warnings exit 0) or WARN; overrides exitCodes in the config")
+	exitZero := flag.Bool("exit-zero", false, "Exit 0 whatever is found; config and input errors still exit 2")
 	flag.Parse()
 
 	config := Config{
@@ -31,6 +46,8 @@ func main() {
 	args := flag.Args()
 	if len(args) == 0 {
 		fmt.Fprintln(os.Stderr, "Usage: kubecheck [options] <file|directory|helm-chart|->")
+		fmt.Fprintln(os.Stderr, "       kubecheck [options] rules")
+		fmt.Fprintln(os.Stderr, "       kubecheck [options] config validate [path...]")
 		fmt.Fprintln(os.Stderr, "Options:")
 		flag.PrintDefaults()
 		os.Exit(ExitError)
@@ -38,60 +55,126 @@ func main() {
 
 	input := args[0]
 
-	// Load rule configuration
+	failOn, err := ParseFailOn(*failOnFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(ExitError)
+	}
+
+	// Load rule configuration; configs fetched from a URL are cached for
+	// when the network is down
+	loadOptions := ConfigLoadOptions{
+		CacheDir: filepath.Join(os.Getenv("HOME"), ".kubecheck", "cache"),
+	}
 	var ruleConfig *RuleConfig
-	if *configFile != "" {
-		// User specified a config file
-		cfg, err := LoadRuleConfig(*configFile)
+	if *configSHA256 != "" && len(configFiles) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: --config-sha256 needs --config")
+		os.Exit(ExitError)
+	}
+	loadOptions.SHA256 = *configSHA256
+
+	// "config validate" checks a config and runs its rule tests instead of
+	// scanning; use ./config to scan a directory of that name
+	if input == "config" {
+		os.Exit(runConfigCommand(args[1:], configFiles, loadOptions, *profileFlag, *kubeVersionFlag))
+	}
+
+	if len(configFiles) > 0 {
+		// User specified config files
+		cfg, err := LoadRuleConfig(configFiles, loadOptions)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error loading config file: %v\n", err)
 			os.Exit(ExitError)
 		}
 		ruleConfig = cfg
 		if config.Verbose {
-			fmt.Printf("Using config file: %s\n", *configFile)
+			fmt.Printf("Using config file: %s\n", strings.Join(configFiles, ", "))
+		}
+	} else if path := defaultConfigPath(); path != "" {
+		cfg, err := LoadRuleConfig([]string{path}, loadOptions)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config file %s: %v\n", path, err)
+			os.Exit(ExitError)
+		}
+		ruleConfig = cfg
+		if config.Verbose {
+			fmt.Printf("Using config file: %s\n", path)
 		}
 	} else {
-		// Try default locations
-		configPaths := []string{
-			"./kubecheck.yaml",
-			"./kubecheck.yml",
-			filepath.Join(os.Getenv("HOME"), ".kubecheck", "config.yaml"),
-			filepath.Join(os.Getenv("HOME"), ".kubecheck", "config.yml"),
-		}
-
-		foundConfig := false
-		for _, path := range configPaths {
-			if _, err := os.Stat(path); err == nil {
-				cfg, err := LoadRuleConfig(path)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error loading config file %s: %v\n", path, err)
-					os.Exit(ExitError)
-				}
-				ruleConfig = cfg
-				foundConfig = true
-				if config.Verbose {
-					fmt.Printf("Using config file: %s\n", path)
-				}
-				break
-			}
+		// Use default built-in rules
+		ruleConfig = GetDefaultConfig()
+		if config.Verbose {
+			fmt.Println("Using built-in default rules")
 		}
+	}
 
-		if !foundConfig {
-			// Use default built-in rules
-			ruleConfig = GetDefaultConfig()
-			if config.Verbose {
-				fmt.Println("Using built-in default rules")
-			}
+	// Profiles from the flag add to those named in the config
+	profileNames := ruleConfig.Profiles
+	for _, name := range strings.Split(*profileFlag, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			profileNames = append(profileNames, name)
 		}
 	}
+	if err := ruleConfig.ApplyProfiles(profileNames); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(ExitError)
+	}
+
+	if err := ruleConfig.SelectTags(splitRuleNames(*tagsFlag), splitRuleNames(*skipTagsFlag)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(ExitError)
+	}
+
+	if *assumeCompleteBundle {
+		ruleConfig.AssumeCompleteBundle = true
+	}
+
+	// "rules" lists the configured rules instead of scanning; use ./rules
+	// to scan a directory of that name
+	if input == "rules" {
+		if err := PrintRules(os.Stdout, ruleConfig); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(ExitError)
+		}
+		os.Exit(ExitOK)
+	}
+
+	kubeVersion, err := ParseKubeVersion(*kubeVersionFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(ExitError)
+	}
 
 	// Create rule engine
-	ruleEngine := NewRuleEngine(ruleConfig)
+	ruleEngine, err := NewRuleEngine(ruleConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(ExitError)
+	}
+	ruleEngine.SetKubeVersion(kubeVersion)
+	ruleEngine.SetInlineIgnores(!*noInlineIgnores)
+
+	var schemaStore *SchemaStore
+	if *validateSchema {
+		schemaStore, err = NewSchemaStore(*schemaDir, kubeVersion)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(ExitError)
+		}
+	}
+
+	var regoPolicies *RegoPolicies
+	if len(ruleConfig.RegoPolicies) > 0 {
+		regoPolicies, err = LoadRegoPolicies(ruleConfig.RegoPolicies)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(ExitError)
+		}
+	}
 
 	// Process input
 	var files []string
-	var err error
+	var skipped []SkippedPath
 
 	if input == "-" {
 		// Read from stdin
@@ -101,7 +184,7 @@ func main() {
 		files, err = processHelmChart(input)
 	} else if isDirectory(input) {
 		// Directory
-		files, err = processDirectory(input)
+		files, skipped, err = processDirectory(input)
 	} else {
 		// Single file
 		files = []string{input}
@@ -115,6 +198,9 @@ func main() {
 	// Validate all files
 	maxSeverity := ExitOK
 	reporter := NewReporter(config.Verbose)
+	for _, s := range skipped {
+		reporter.AddSkippedPath(s)
+	}
 
 	// Enable directory mode if processing multiple files
 	if len(files) > 1 || isDirectory(input) {
@@ -124,26 +210,105 @@ func main() {
 		}
 	}
 
+	readFiles := 0
+	listed := isDirectory(input)
+	var scanned []scannedResource
 	for _, file := range files {
 		resources, err := parseYAMLFile(file)
 		if err != nil {
+			if listed && (errors.Is(err, fs.ErrPermission) || errors.Is(err, fs.ErrNotExist)) {
+				// File became unreadable or vanished after it was listed
+				reporter.AddSkippedPath(SkippedPath{Path: file, Err: err})
+				skipped = append(skipped, SkippedPath{Path: file, Err: err})
+				continue
+			}
 			fmt.Fprintf(os.Stderr, "Error parsing %s: %v\n", file, err)
 			continue
 		}
+		readFiles++
 
 		for _, resource := range resources {
+			displayName := file
+			if input == "-" {
+				displayName = stdinDisplayName(resource)
+			}
+			resource.File = displayName
+
 			// Use rule engine to evaluate
 			violations := ruleEngine.EvaluateResource(resource)
-
-			severity := reporter.ReportViolations(file, resource, violations)
-			if severity > maxSeverity {
-				maxSeverity = severity
+			if schemaStore != nil {
+				schemaViolations, err := schemaStore.Validate(resource)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					os.Exit(ExitError)
+				}
+				violations = append(violations, ruleEngine.ApplyIgnores(resource, schemaViolations)...)
 			}
+			scanned = append(scanned, scannedResource{
+				displayName: displayName,
+				resource:    resource,
+				violations:  violations,
+			})
+		}
+	}
+
+	bundle := &Bundle{}
+	for _, s := range scanned {
+		bundle.Resources = append(bundle.Resources, s.resource)
+	}
+
+	// Rego policies run in one opa process for the whole scan
+	if regoPolicies != nil {
+		regoViolations, err := regoPolicies.Evaluate(bundle.Resources)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(ExitError)
+		}
+		for i, violations := range regoViolations {
+			scanned[i].violations = append(scanned[i].violations, ruleEngine.ApplyIgnores(scanned[i].resource, violations)...)
+		}
+	}
+
+	// Cross-resource rules need the whole scan before they can run
+	for _, bv := range ruleEngine.EvaluateBundle(bundle) {
+		scanned[bv.Index].violations = append(scanned[bv.Index].violations, bv.Violation)
+	}
+
+	reporter.AddSuppressed(ruleEngine.Suppressed()...)
+
+	for _, s := range scanned {
+		severity := reporter.ReportViolations(s.displayName, s.resource, s.violations)
+		if severity > maxSeverity {
+			maxSeverity = severity
 		}
 	}
 
-	reporter.PrintSummary()
-	os.Exit(maxSeverity)
+	exitCode := ruleConfig.ExitCode(maxSeverity, failOn, *exitZero)
+	reporter.PrintSummary(exitCode)
+
+	if readFiles == 0 && len(skipped) > 0 {
+		fmt.Fprintf(os.Stderr, "Error processing input: nothing under %s could be read (%d path%s skipped)\n",
+			input, len(skipped), pluralize(len(skipped)))
+		os.Exit(ExitError)
+	}
+
+	os.Exit(exitCode)
+}
+
+// scannedResource is a parsed resource waiting to be reported
+type scannedResource struct {
+	displayName string
+	resource    K8sResource
+	violations  []Violation
+}
+
+// stdinDisplayName names a document read from stdin, preferring the
+// template path Helm records in its "# Source:" comment
+func stdinDisplayName(resource K8sResource) string {
+	if resource.Source != "" {
+		return resource.Source
+	}
+	return fmt.Sprintf("<stdin>#%d", resource.Document)
 }
 
 // isHelmChart checks if the path is a Helm chart directory
@@ -161,3 +326,42 @@ func isDirectory(path string) bool {
 	}
 	return info.IsDir()
 }
+
+// defaultConfigPath returns the first config found in the default
+// locations, or "" when there is none; .kubecheck is a directory of fragments
+func defaultConfigPath() string {
+	configPaths := []string{
+		"./kubecheck.yaml",
+		"./kubecheck.yml",
+		"./.kubecheck",
+		filepath.Join(os.Getenv("HOME"), ".kubecheck", "config.yaml"),
+		filepath.Join(os.Getenv("HOME"), ".kubecheck", "config.yml"),
+	}
+	for _, path := range configPaths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		// A directory without fragments, such as one holding only the
+		// cache, is not a config
+		if _, err := ConfigFiles([]string{path}); info.IsDir() && err != nil {
+			continue
+		}
+		return path
+	}
+	return ""
+}
+
+// stringList collects the values of a flag that can be repeated
+type stringList []string
+
+// String returns the values, comma-separated
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+// Set adds a value
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
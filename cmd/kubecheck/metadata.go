@@ -0,0 +1,274 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// nameFormat is one of the formats the API server validates metadata.name against
+type nameFormat struct {
+	Description string
+	MaxLength   int
+	AllowDots   bool // DNS subdomains are dot-separated DNS labels
+	StartLetter bool // DNS-1035 labels must start with a letter
+	PathSegment bool // any name that is safe in a URL path
+}
+
+var (
+	dnsSubdomainFormat = nameFormat{
+		Description: "DNS-1123 subdomain: lowercase letters, digits, '-', and '.', at most 253 characters",
+		MaxLength:   253,
+		AllowDots:   true,
+	}
+	dnsLabelFormat = nameFormat{
+		Description: "DNS-1123 label: lowercase letters, digits, and '-', at most 63 characters",
+		MaxLength:   63,
+	}
+	dns1035LabelFormat = nameFormat{
+		Description: "DNS-1035 label: lowercase letters, digits, and '-', starting with a letter, at most 63 characters",
+		MaxLength:   63,
+		StartLetter: true,
+	}
+	pathSegmentFormat = nameFormat{
+		Description: "path segment: no '/' or '%', and not '.' or '..'",
+		PathSegment: true,
+	}
+)
+
+// nameFormatFor returns the format a kind's names must follow. Services become
+// DNS labels, Namespaces become part of DNS names, and RBAC objects allow
+// names such as system:controller:job-controller
+func nameFormatFor(kind string) nameFormat {
+	switch kind {
+	case "Service":
+		return dns1035LabelFormat
+	case "Namespace":
+		return dnsLabelFormat
+	case "Role", "ClusterRole", "RoleBinding", "ClusterRoleBinding":
+		return pathSegmentFormat
+	default:
+		return dnsSubdomainFormat
+	}
+}
+
+// metadataNameInvalid flags resources whose metadata.name the API server would
+// reject. With generateName and no name, the prefix is checked instead, less
+// the trailing dashes the generated suffix follows
+// The details quote the name, the problem, and the format it breaks
+func metadataNameInvalid(resource K8sResource) (bool, string) {
+	field, value := "name", getStringValue(resource.Metadata, "name")
+	if value == "" {
+		generateName := getStringValue(resource.Metadata, "generateName")
+		if generateName == "" {
+			return true, "metadata.name is empty"
+		}
+		field, value = "generateName", strings.TrimRight(generateName, "-")
+		if value == "" {
+			return false, ""
+		}
+	}
+
+	format := nameFormatFor(resource.Kind)
+	problem := format.problem(value)
+	if problem == "" {
+		return false, ""
+	}
+	return true, fmt.Sprintf("%s '%s' %s (%s)", field, value, problem, format.Description)
+}
+
+// problem describes the first way a name breaks the format, or returns ""
+func (f nameFormat) problem(name string) string {
+	if f.PathSegment {
+		switch {
+		case name == "." || name == "..":
+			return "is a relative path"
+		case strings.ContainsAny(name, "/%"):
+			return fmt.Sprintf("contains '%c'", name[strings.IndexAny(name, "/%")])
+		}
+		return ""
+	}
+
+	if len(name) > f.MaxLength {
+		return fmt.Sprintf("is %d characters, longer than the %d allowed", len(name), f.MaxLength)
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '.' && f.AllowDots:
+		case unicode.IsUpper(r):
+			return fmt.Sprintf("contains uppercase '%c'", r)
+		default:
+			return fmt.Sprintf("contains '%c'", r)
+		}
+	}
+
+	part := ""
+	if strings.Contains(name, ".") {
+		part = " each part"
+	}
+	for _, label := range strings.Split(name, ".") {
+		switch {
+		case label == "":
+			return "has an empty part between dots"
+		case f.StartLetter && !isLowerAlpha(label[0]):
+			return fmt.Sprintf("must start with a lowercase letter, not '%c'", label[0])
+		case !isLowerAlphanumeric(label[0]):
+			return fmt.Sprintf("must start%s with a lowercase letter or digit, not '%c'", part, label[0])
+		case !isLowerAlphanumeric(label[len(label)-1]):
+			return fmt.Sprintf("must end%s with a lowercase letter or digit, not '%c'", part, label[len(label)-1])
+		}
+	}
+	return ""
+}
+
+// isLowerAlpha reports whether b is a lowercase ASCII letter
+func isLowerAlpha(b byte) bool {
+	return b >= 'a' && b <= 'z'
+}
+
+// isLowerAlphanumeric reports whether b is a lowercase ASCII letter or a digit
+func isLowerAlphanumeric(b byte) bool {
+	return isLowerAlpha(b) || (b >= '0' && b <= '9')
+}
+
+// maxLabelValueLength is the longest label value, and the longest name part of a key
+const maxLabelValueLength = 63
+
+// qualifiedNameProblem describes why a label or annotation key is not a
+// qualified name, an optional DNS subdomain prefix and "/" before a name of at
+// most 63 alphanumerics, '-', '_', and '.', or returns ""
+func qualifiedNameProblem(key string) string {
+	name := key
+	if prefix, rest, ok := strings.Cut(key, "/"); ok {
+		if prefix == "" {
+			return "has an empty prefix before '/'"
+		}
+		if strings.Contains(rest, "/") {
+			return "has more than one '/'"
+		}
+		if problem := dnsSubdomainFormat.problem(prefix); problem != "" {
+			return fmt.Sprintf("has prefix '%s' that %s (prefixes are DNS-1123 subdomains)", prefix, problem)
+		}
+		name = rest
+	}
+
+	if name == "" {
+		return "has an empty name"
+	}
+	if problem := labelTokenProblem(name); problem != "" {
+		return "has a name that " + problem
+	}
+	return ""
+}
+
+// labelValueProblem describes why a label value is invalid, or returns ""
+// Empty values are allowed
+func labelValueProblem(value string) string {
+	if value == "" {
+		return ""
+	}
+	return labelTokenProblem(value)
+}
+
+// labelTokenProblem checks the shared syntax of key names and label values:
+// at most 63 characters of alphanumerics, '-', '_', and '.', beginning and
+// ending with an alphanumeric
+func labelTokenProblem(token string) string {
+	if len(token) > maxLabelValueLength {
+		return fmt.Sprintf("is %d characters, longer than the %d allowed", len(token), maxLabelValueLength)
+	}
+	for _, r := range token {
+		if !isAlphanumeric(r) && r != '-' && r != '_' && r != '.' {
+			return fmt.Sprintf("contains '%c'", r)
+		}
+	}
+	if first := rune(token[0]); !isAlphanumeric(first) {
+		return fmt.Sprintf("must start with a letter or digit, not '%c'", first)
+	}
+	if last := rune(token[len(token)-1]); !isAlphanumeric(last) {
+		return fmt.Sprintf("must end with a letter or digit, not '%c'", last)
+	}
+	return ""
+}
+
+// isAlphanumeric reports whether r is an ASCII letter or digit
+func isAlphanumeric(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
+
+// labelsInvalid flags label keys and values the API server rejects, in
+// metadata.labels and in a workload's pod template labels
+// The details quote each offending label and the rule it breaks
+func labelsInvalid(resource K8sResource, specPaths map[string]string) (bool, string) {
+	problems := labelProblems("label", resource.Metadata)
+	if path, ok := podSpecPath(resource, specPaths); ok && path != "spec" {
+		template := lookupSpecPath(resource, strings.TrimSuffix(path, ".spec")+".metadata")
+		problems = append(problems, labelProblems("template label", template)...)
+	}
+	return len(problems) > 0, strings.Join(problems, "; ")
+}
+
+// labelProblems checks the labels of one metadata map, in key order
+// YAML turns unquoted values such as true or 1.0 into non-strings, which the
+// API server rejects too
+func labelProblems(what string, metadata map[string]interface{}) []string {
+	labels, _ := metadata["labels"].(map[string]interface{})
+
+	var problems []string
+	for _, key := range sortedKeys(labels) {
+		if problem := qualifiedNameProblem(key); problem != "" {
+			problems = append(problems, fmt.Sprintf("%s key '%s' %s", what, key, problem))
+		}
+		value, ok := labels[key].(string)
+		if !ok && labels[key] != nil {
+			problems = append(problems, fmt.Sprintf("%s '%s' has value %v that is not a string (quote it)", what, key, labels[key]))
+			continue
+		}
+		if problem := labelValueProblem(value); problem != "" {
+			problems = append(problems, fmt.Sprintf("%s '%s' has value '%s' that %s", what, key, value, problem))
+		}
+	}
+	return problems
+}
+
+// annotationKeysInvalid flags annotation keys that are not qualified names
+// Annotation values may hold anything. The details quote each key and the rule it breaks
+func annotationKeysInvalid(resource K8sResource) (bool, string) {
+	annotations, _ := resource.Metadata["annotations"].(map[string]interface{})
+
+	var problems []string
+	for _, key := range sortedKeys(annotations) {
+		if problem := qualifiedNameProblem(key); problem != "" {
+			problems = append(problems, fmt.Sprintf("'%s' %s", key, problem))
+		}
+	}
+	return len(problems) > 0, strings.Join(problems, "; ")
+}
+
+// annotationSizeExceeds flags annotations whose key and value together are
+// larger than maximum bytes. The API server caps all annotations of an object
+// at 256KiB combined, and large ones bloat every watch of the object
+// The details name each annotation and its size
+func annotationSizeExceeds(resource K8sResource, maximum float64) (bool, string) {
+	annotations, _ := resource.Metadata["annotations"].(map[string]interface{})
+
+	var large []string
+	for _, key := range sortedKeys(annotations) {
+		size := len(key) + len(fmt.Sprint(annotations[key]))
+		if float64(size) > maximum {
+			large = append(large, fmt.Sprintf("'%s' (%s)", key, formatBytes(size)))
+		}
+	}
+	return len(large) > 0, strings.Join(large, ", ")
+}
+
+// sortedKeys returns the keys of a decoded YAML map in order
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// externallyExposedServiceTypes are the Service types reachable from outside the cluster
+var externallyExposedServiceTypes = []string{"NodePort", "LoadBalancer"}
+
+// serviceType returns the Service's spec.type, treating an unset type as ClusterIP
+func serviceType(resource K8sResource) string {
+	if t := getStringValue(resource.Spec, "type"); t != "" {
+		return t
+	}
+	return "ClusterIP"
+}
+
+// serviceExternalTrafficPolicyMissing flags NodePort and LoadBalancer Services
+// that leave externalTrafficPolicy to its Cluster default
+func serviceExternalTrafficPolicyMissing(resource K8sResource) (bool, string) {
+	if resource.Kind != "Service" {
+		return false, ""
+	}
+	exposure := serviceType(resource)
+	if !containsString(externallyExposedServiceTypes, exposure) {
+		return false, ""
+	}
+	if getStringValue(resource.Spec, "externalTrafficPolicy") != "" {
+		return false, ""
+	}
+	return true, fmt.Sprintf("type %s", exposure)
+}
+
+// ingressRuleHosts returns the host of every Ingress rule, "" for host-less rules
+func ingressRuleHosts(resource K8sResource) []string {
+	rules, ok := resource.Spec["rules"].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var hosts []string
+	for _, r := range rules {
+		if ruleMap, ok := r.(map[string]interface{}); ok {
+			hosts = append(hosts, getStringValue(ruleMap, "host"))
+		}
+	}
+	return hosts
+}
+
+// ingressTLSMissing flags Ingresses without spec.tls, or with rule hosts that
+// no tls entry covers; the details list the uncovered hosts
+func ingressTLSMissing(resource K8sResource) (bool, string) {
+	if resource.Kind != "Ingress" {
+		return false, ""
+	}
+
+	tlsList, _ := resource.Spec["tls"].([]interface{})
+	if len(tlsList) == 0 {
+		var hosts []string
+		for _, host := range ingressRuleHosts(resource) {
+			if host != "" && !containsString(hosts, host) {
+				hosts = append(hosts, host)
+			}
+		}
+		if len(hosts) == 0 {
+			return true, "any host"
+		}
+		return true, strings.Join(hosts, ", ")
+	}
+
+	var tlsHosts []string
+	for _, t := range tlsList {
+		if tlsMap, ok := t.(map[string]interface{}); ok {
+			tlsHosts = append(tlsHosts, getStringList(tlsMap, "hosts")...)
+		}
+	}
+
+	var uncovered []string
+	for _, host := range ingressRuleHosts(resource) {
+		if host == "" || containsString(uncovered, host) {
+			continue
+		}
+		covered := false
+		for _, tlsHost := range tlsHosts {
+			if hostMatches(tlsHost, host) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			uncovered = append(uncovered, host)
+		}
+	}
+	return len(uncovered) > 0, strings.Join(uncovered, ", ")
+}
+
+// hostMatches reports whether a TLS host, possibly a "*.example.com" wildcard,
+// covers a rule host; a wildcard matches exactly one leading label
+func hostMatches(tlsHost, host string) bool {
+	if tlsHost == host {
+		return true
+	}
+	suffix, ok := strings.CutPrefix(tlsHost, "*.")
+	if !ok {
+		return false
+	}
+	label, rest, found := strings.Cut(host, ".")
+	return found && label != "" && label != "*" && rest == suffix
+}
+
+// ingressHostWildcard flags Ingress rules whose host is a wildcard
+func ingressHostWildcard(resource K8sResource) (bool, string) {
+	if resource.Kind != "Ingress" {
+		return false, ""
+	}
+
+	var hosts []string
+	for _, host := range ingressRuleHosts(resource) {
+		if strings.HasPrefix(host, "*") && !containsString(hosts, host) {
+			hosts = append(hosts, host)
+		}
+	}
+	return len(hosts) > 0, strings.Join(hosts, ", ")
+}
+
+// legacyIngressClassAnnotation selected the controller before spec.ingressClassName existed
+const legacyIngressClassAnnotation = "kubernetes.io/ingress.class"
+
+// ingressClassMissing flags Ingresses that name no controller at all
+func ingressClassMissing(resource K8sResource) (bool, string) {
+	if resource.Kind != "Ingress" || getStringValue(resource.Spec, "ingressClassName") != "" {
+		return false, ""
+	}
+	annotations := getStringMap(resource.Metadata, "annotations")
+	return annotations[legacyIngressClassAnnotation] == "", ""
+}
+
+// ingressLegacyClassAnnotation flags Ingresses that select their controller
+// through the deprecated annotation; the details quote it
+func ingressLegacyClassAnnotation(resource K8sResource) (bool, string) {
+	if resource.Kind != "Ingress" {
+		return false, ""
+	}
+	class, ok := getStringMap(resource.Metadata, "annotations")[legacyIngressClassAnnotation]
+	if !ok {
+		return false, ""
+	}
+	return true, fmt.Sprintf("%s: %q", legacyIngressClassAnnotation, class)
+}
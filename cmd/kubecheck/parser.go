@@ -18,8 +18,24 @@ type K8sResource struct {
 	Metadata   map[string]interface{} `json:"metadata" yaml:"metadata"`
 	Spec       map[string]interface{} `json:"spec" yaml:"spec"`
 	Data       map[string]interface{} `json:"data,omitempty" yaml:"data,omitempty"`
+
+	// Object is the whole document, for kinds whose fields live outside spec
+	// (RBAC rules, roleRef, subjects)
+	Object map[string]interface{} `json:"-" yaml:"-"`
+
+	// Source is the template path from a Helm "# Source:" comment, if any
+	Source string `json:"-" yaml:"-"`
+	// Document is the 1-based position of the document in its YAML stream
+	Document int `json:"-" yaml:"-"`
+	// File is the path the resource was read from, as reported
+	File string `json:"-" yaml:"-"`
+	// IgnoreComments are the "# kubecheck-ignore:" comments of the document
+	IgnoreComments []IgnoreDirective `json:"-" yaml:"-"`
 }
 
+// helmSourcePrefix marks the comment Helm writes above each rendered document
+const helmSourcePrefix = "# Source:"
+
 // parseYAMLFile parses a YAML file and returns Kubernetes resources
 func parseYAMLFile(filename string) ([]K8sResource, error) {
 	data, err := os.ReadFile(filename)
@@ -38,27 +54,64 @@ func parseYAML(data []byte) ([]K8sResource, error) {
 	// Split by document separator
 	decoder := yaml.NewDecoder(bytes.NewReader(data))
 
+	document := 0
 	for {
-		var resource K8sResource
-		err := decoder.Decode(&resource)
+		var node yaml.Node
+		err := decoder.Decode(&node)
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
 			return nil, fmt.Errorf("failed to decode YAML: %w", err)
 		}
+		document++
+
+		var resource K8sResource
+		if err := node.Decode(&resource); err != nil {
+			return nil, fmt.Errorf("failed to decode YAML: %w", err)
+		}
 
 		// Skip empty documents
 		if resource.Kind == "" {
 			continue
 		}
+		if err := node.Decode(&resource.Object); err != nil {
+			return nil, fmt.Errorf("failed to decode YAML: %w", err)
+		}
 
+		resource.Source = helmSourceComment(&node)
+		resource.IgnoreComments = ignoreComments(&node)
+		resource.Document = document
 		resources = append(resources, resource)
 	}
 
 	return resources, nil
 }
 
+// helmSourceComment returns the template path from a "# Source:" comment
+// attached to the start of a document, or "" when there is none
+func helmSourceComment(doc *yaml.Node) string {
+	comments := []string{doc.HeadComment}
+	if len(doc.Content) > 0 {
+		root := doc.Content[0]
+		comments = append(comments, root.HeadComment)
+		if len(root.Content) > 0 {
+			comments = append(comments, root.Content[0].HeadComment)
+		}
+	}
+
+	for _, comment := range comments {
+		for _, line := range strings.Split(comment, "\n") {
+			line = strings.TrimSpace(line)
+			if strings.HasPrefix(line, helmSourcePrefix) {
+				return strings.TrimSpace(strings.TrimPrefix(line, helmSourcePrefix))
+			}
+		}
+	}
+
+	return ""
+}
+
 // processStdin reads YAML from stdin
 func processStdin() ([]string, error) {
 	tmpFile, err := os.CreateTemp("", "kubecheck-*.yaml")
@@ -80,9 +133,17 @@ func processStdin() ([]string, error) {
 	return []string{tmpFile.Name()}, nil
 }
 
+// SkippedPath records a path that could not be read during a scan
+type SkippedPath struct {
+	Path string
+	Err  error
+}
+
 // processDirectory recursively finds YAML files in a directory
-func processDirectory(dir string) ([]string, error) {
+// Unreadable subdirectories and entries are returned as skipped paths
+func processDirectory(dir string) ([]string, []SkippedPath, error) {
 	var files []string
+	var skipped []SkippedPath
 
 	err := walkDir(dir, func(path string, info os.FileInfo) error {
 		if info.IsDir() {
@@ -95,17 +156,21 @@ func processDirectory(dir string) ([]string, error) {
 		}
 
 		return nil
+	}, func(path string, err error) {
+		skipped = append(skipped, SkippedPath{Path: path, Err: err})
 	})
 
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return files, nil
+	return files, skipped, nil
 }
 
 // walkDir walks a directory tree
-func walkDir(root string, fn func(string, os.FileInfo) error) error {
+// Directories that cannot be listed and entries that vanish mid-walk are
+// passed to onSkip (when non-nil) and the walk carries on
+func walkDir(root string, fn func(string, os.FileInfo) error, onSkip func(string, error)) error {
 	info, err := os.Stat(root)
 	if err != nil {
 		return err
@@ -117,19 +182,28 @@ func walkDir(root string, fn func(string, os.FileInfo) error) error {
 
 	entries, err := os.ReadDir(root)
 	if err != nil {
-		return err
+		if onSkip == nil {
+			return err
+		}
+		onSkip(root, err)
 	}
 
 	for _, entry := range entries {
 		path := root + string(os.PathSeparator) + entry.Name()
 		entryInfo, err := entry.Info()
 		if err != nil {
+			if onSkip != nil {
+				onSkip(path, err)
+			}
 			continue
 		}
 
 		if entry.IsDir() {
-			if err := walkDir(path, fn); err != nil {
-				return err
+			if err := walkDir(path, fn, onSkip); err != nil {
+				if onSkip == nil {
+					return err
+				}
+				onSkip(path, err)
 			}
 		} else {
 			if err := fn(path, entryInfo); err != nil {
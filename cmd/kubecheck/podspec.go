@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// duplicateContainerName flags pods where two containers share a name, which
+// the API server rejects across containers, initContainers, and ephemeralContainers
+// The details name each duplicate and the origins that use it
+func duplicateContainerName(podSpec *PodSpec) (bool, string) {
+	origins := make(map[string][]string)
+	var names []string
+	for _, c := range podSpec.Containers {
+		if _, seen := origins[c.Name]; !seen {
+			names = append(names, c.Name)
+		}
+		origins[c.Name] = append(origins[c.Name], c.Origin)
+	}
+
+	var duplicates []string
+	for _, name := range names {
+		if len(origins[name]) > 1 {
+			duplicates = append(duplicates, fmt.Sprintf("'%s' (%s)", name, strings.Join(origins[name], ", ")))
+		}
+	}
+	return len(duplicates) > 0, strings.Join(duplicates, ", ")
+}
+
+// conflictingHostPort flags pods where two containers, or one container twice,
+// bind the same hostPort and protocol. Init containers run before the others,
+// so they are only compared with each other
+// The details name each port and the containers that bind it
+func conflictingHostPort(podSpec *PodSpec) (bool, string) {
+	var conflicts []string
+	for _, origin := range []string{OriginContainer, OriginInitContainer} {
+		binders := make(map[string][]string)
+		var ports []string
+		for _, c := range podSpec.Containers {
+			if c.Origin != origin {
+				continue
+			}
+			for _, port := range c.Ports {
+				if port.HostPort == 0 {
+					continue
+				}
+				protocol := port.Protocol
+				if protocol == "" {
+					protocol = "TCP"
+				}
+				key := fmt.Sprintf("%d/%s", port.HostPort, protocol)
+				if _, seen := binders[key]; !seen {
+					ports = append(ports, key)
+				}
+				binders[key] = append(binders[key], "'"+c.Name+"'")
+			}
+		}
+
+		for _, port := range ports {
+			if len(binders[port]) > 1 {
+				conflicts = append(conflicts, fmt.Sprintf("hostPort %s (%s)", port, strings.Join(binders[port], ", ")))
+			}
+		}
+	}
+	return len(conflicts) > 0, strings.Join(conflicts, ", ")
+}
+
+// volumeMountUndefined flags volumeMounts and volumeDevices whose name matches
+// no entry of the pod's volumes or extra, which fails only when the pod is created
+// The details name each container, mount, and mountPath
+func volumeMountUndefined(podSpec *PodSpec, extra []string) (bool, string) {
+	declared := make(map[string]bool)
+	for _, v := range podSpec.Volumes {
+		declared[v.Name] = true
+	}
+	for _, name := range extra {
+		declared[name] = true
+	}
+
+	var undefined []string
+	for _, c := range podSpec.Containers {
+		for _, mount := range c.VolumeMounts {
+			if !declared[mount.Name] {
+				undefined = append(undefined, fmt.Sprintf("%s '%s' mounts '%s' at %s", c.Origin, c.Name, mount.Name, mount.MountPath))
+			}
+		}
+		for _, device := range c.VolumeDevices {
+			if !declared[device] {
+				undefined = append(undefined, fmt.Sprintf("%s '%s' uses '%s' as a device", c.Origin, c.Name, device))
+			}
+		}
+	}
+	return len(undefined) > 0, strings.Join(undefined, ", ")
+}
+
+// volumeUnused flags pod volumes that no container mounts or uses as a device
+// The details name the volumes
+func volumeUnused(podSpec *PodSpec) (bool, string) {
+	used := make(map[string]bool)
+	for _, c := range podSpec.Containers {
+		for _, mount := range c.VolumeMounts {
+			used[mount.Name] = true
+		}
+		for _, device := range c.VolumeDevices {
+			used[device] = true
+		}
+	}
+
+	var unused []string
+	for _, v := range podSpec.Volumes {
+		if !used[v.Name] {
+			unused = append(unused, "'"+v.Name+"'")
+		}
+	}
+	return len(unused) > 0, strings.Join(unused, ", ")
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// baselineCapabilities are the capabilities the baseline Pod Security Standard lets containers add
+const baselineCapabilities = "AUDIT_WRITE,CHOWN,DAC_OVERRIDE,FOWNER,FSETID,KILL,MKNOD,NET_BIND_SERVICE,SETFCAP,SETGID,SETPCAP,SETUID,SYS_CHROOT"
+
+// restrictedCapabilities are the capabilities the restricted Pod Security Standard lets containers add
+const restrictedCapabilities = "NET_BIND_SERVICE"
+
+// baselineSysctls are the sysctls the baseline Pod Security Standard allows
+const baselineSysctls = "kernel.shm_rmid_forced,net.ipv4.ip_local_port_range,net.ipv4.ip_unprivileged_port_start," +
+	"net.ipv4.tcp_syncookies,net.ipv4.ping_group_range,net.ipv4.ip_local_reserved_ports,net.ipv4.tcp_keepalive_time," +
+	"net.ipv4.tcp_fin_timeout,net.ipv4.tcp_keepalive_intvl,net.ipv4.tcp_keepalive_probes"
+
+// restrictedVolumeSources are the volume types the restricted Pod Security Standard allows
+const restrictedVolumeSources = "configMap,csi,downwardAPI,emptyDir,ephemeral,persistentVolumeClaim,projected,secret"
+
+// profiles maps each built-in profile to the rules it activates
+var profiles = map[string]func() []Rule{
+	"pss-baseline":   pssBaselineRules,
+	"pss-restricted": pssRestrictedRules,
+}
+
+// ProfileNames returns the built-in profile names, sorted
+func ProfileNames() []string {
+	var names []string
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyProfiles appends the rules of the named profiles to the config
+// A user rule with the same name as a profile rule replaces it, and when
+// profiles share a rule the one named last wins
+func (c *RuleConfig) ApplyProfiles(names []string) error {
+	userRules := make(map[string]bool)
+	for _, rule := range c.Rules {
+		userRules[rule.Name] = true
+	}
+
+	var profileRules []Rule
+	index := make(map[string]int)
+	for _, name := range names {
+		rulesFor, ok := profiles[name]
+		if !ok {
+			return fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(ProfileNames(), ", "))
+		}
+		for _, rule := range rulesFor() {
+			if userRules[rule.Name] {
+				continue
+			}
+			rule.Profile = name
+			// Profile rules are all Pod Security Standard controls
+			rule.Tags = appendUnique(rule.Tags, "security", "pss")
+			if i, ok := index[rule.Name]; ok {
+				profileRules[i] = rule
+				continue
+			}
+			index[rule.Name] = len(profileRules)
+			profileRules = append(profileRules, rule)
+		}
+	}
+
+	c.Rules = append(c.Rules, profileRules...)
+	return nil
+}
+
+// pssBaselineRules mirrors the controls of the baseline Pod Security Standard,
+// which blocks known privilege escalations
+func pssBaselineRules() []Rule {
+	return []Rule{
+		{
+			Name:        "pss-host-process",
+			Description: "Windows pods must not run HostProcess containers",
+			Severity:    "ERROR",
+			Type:        "security",
+			Conditions:  []string{"host_process_true"},
+			Message:     "{origin} '{container}' runs as a Windows HostProcess container",
+			Help:        "remove securityContext.windowsOptions.hostProcess",
+		},
+		{
+			Name:        "pss-host-namespaces",
+			Description: "Pods must not share host namespaces",
+			Severity:    "ERROR",
+			Type:        "security",
+			Conditions:  []string{"host_network_true", "host_pid_true", "host_ipc_true"},
+			Message:     "{kind} '{name}' shares a host namespace ({details})",
+			Help:        "remove hostNetwork, hostPID, and hostIPC from the pod spec",
+		},
+		{
+			Name:        "pss-privileged",
+			Description: "Containers must not run privileged",
+			Severity:    "ERROR",
+			Type:        "security",
+			Conditions:  []string{"privileged_true"},
+			Message:     "{origin} '{container}' is running in privileged mode",
+			Help:        "set securityContext.privileged: false or remove the field",
+		},
+		{
+			Name:        "pss-capabilities",
+			Description: "Containers may only add capabilities from the baseline set",
+			Severity:    "ERROR",
+			Type:        "security",
+			Conditions:  []string{"capabilities_added_not_in:" + baselineCapabilities},
+			Message:     "{origin} '{container}' adds capabilities outside the baseline set: {details}",
+			Help:        "remove them from securityContext.capabilities.add",
+		},
+		{
+			Name:        "pss-host-path-volumes",
+			Description: "Pods must not mount hostPath volumes",
+			Severity:    "ERROR",
+			Type:        "security",
+			Conditions:  []string{"volume_source_in:hostPath"},
+			Message:     "{kind} '{name}' mounts hostPath volumes: {details}",
+			Help:        "use a PersistentVolumeClaim, ConfigMap, or emptyDir instead of a hostPath volume",
+		},
+		{
+			Name:        "pss-host-ports",
+			Description: "Containers must not bind host ports",
+			Severity:    "ERROR",
+			Type:        "security",
+			Conditions:  []string{"host_port_set"},
+			Message:     "{origin} '{container}' binds host ports: {details}",
+			Help:        "remove ports[].hostPort and expose the container through a Service",
+		},
+		{
+			Name:        "pss-apparmor",
+			Description: "Containers must not run AppArmor Unconfined",
+			Severity:    "ERROR",
+			Type:        "security",
+			Conditions:  []string{"apparmor_profile_equals:Unconfined"},
+			Message:     "{origin} '{container}' sets appArmorProfile type {details}",
+			Help:        "use appArmorProfile type RuntimeDefault or Localhost, or remove it",
+		},
+		{
+			Name:        "pss-selinux",
+			Description: "Pods must not set a custom SELinux user or role, or an unexpected type",
+			Severity:    "ERROR",
+			Type:        "security",
+			Conditions:  []string{"selinux_options_unsafe"},
+			Message:     "{origin} '{container}' sets seLinuxOptions {details}",
+			Help:        "remove seLinuxOptions.user and role; type may only be container_t, container_init_t, container_kvm_t, or container_engine_t",
+		},
+		{
+			Name:        "pss-proc-mount",
+			Description: "Containers must use the default /proc mount",
+			Severity:    "ERROR",
+			Type:        "security",
+			Conditions:  []string{"proc_mount_unmasked"},
+			Message:     "{origin} '{container}' sets securityContext.procMount: Unmasked",
+			Help:        "remove procMount; Unmasked exposes host kernel details under /proc that the runtime normally hides",
+		},
+		{
+			Name:        "pss-seccomp",
+			Description: "Containers must not run seccomp Unconfined",
+			Severity:    "ERROR",
+			Type:        "security",
+			Conditions:  []string{"seccomp_profile_equals:Unconfined"},
+			Message:     "{origin} '{container}' sets seccompProfile type {details}",
+			Help:        "use seccompProfile type RuntimeDefault or Localhost",
+		},
+		{
+			Name:        "pss-sysctls",
+			Description: "Pods may only set sysctls from the baseline safe set",
+			Severity:    "ERROR",
+			Type:        "security",
+			Conditions:  []string{"sysctl_not_in:" + baselineSysctls},
+			Message:     "{kind} '{name}' sets unsafe sysctls: {details}",
+			Help:        "remove the sysctl; unsafe sysctls need kubelet allowlisting and can affect the whole node",
+		},
+	}
+}
+
+// pssRestrictedRules mirrors the restricted Pod Security Standard: every
+// baseline control, with stricter capability and seccomp rules, plus the
+// hardening controls restricted adds
+func pssRestrictedRules() []Rule {
+	var rules []Rule
+	for _, rule := range pssBaselineRules() {
+		switch rule.Name {
+		case "pss-capabilities":
+			rule.Description = "Containers may only add NET_BIND_SERVICE"
+			rule.Conditions = []string{"capabilities_added_not_in:" + restrictedCapabilities}
+			rule.Message = "{origin} '{container}' adds capabilities other than NET_BIND_SERVICE: {details}"
+		case "pss-seccomp":
+			rule.Description = "Containers must set seccompProfile to RuntimeDefault or Localhost"
+			rule.Conditions = []string{"seccomp_profile_not_in:RuntimeDefault,Localhost"}
+			rule.Message = "{origin} '{container}' has seccompProfile type {details}"
+			rule.Help = "set securityContext.seccompProfile.type: RuntimeDefault on the pod or container"
+		}
+		rules = append(rules, rule)
+	}
+
+	return append(rules,
+		Rule{
+			Name:        "pss-volume-types",
+			Description: "Pods may only use the volume types restricted allows",
+			Severity:    "ERROR",
+			Type:        "security",
+			Conditions:  []string{"volume_source_not_in:" + restrictedVolumeSources},
+			Message:     "{kind} '{name}' uses volume types restricted does not allow: {details}",
+			Help:        "use configMap, csi, downwardAPI, emptyDir, ephemeral, persistentVolumeClaim, projected, or secret volumes",
+		},
+		Rule{
+			Name:        "pss-drop-capabilities",
+			Description: "Containers must drop ALL capabilities",
+			Severity:    "ERROR",
+			Type:        "security",
+			Conditions:  []string{"capabilities_not_dropped_all"},
+			Message:     "{origin} '{container}' does not drop ALL capabilities",
+			Help:        "set securityContext.capabilities.drop: [ALL]",
+		},
+		Rule{
+			Name:        "pss-privilege-escalation",
+			Description: "Containers must set allowPrivilegeEscalation: false",
+			Severity:    "ERROR",
+			Type:        "security",
+			Conditions:  []string{"allow_privilege_escalation_not_false"},
+			Message:     "{origin} '{container}' does not set allowPrivilegeEscalation: false",
+			Help:        "set securityContext.allowPrivilegeEscalation: false",
+		},
+		Rule{
+			Name:        "pss-run-as-non-root",
+			Description: "Containers must set runAsNonRoot: true",
+			Severity:    "ERROR",
+			Type:        "security",
+			Conditions:  []string{"run_as_non_root_not_true"},
+			Message:     "{origin} '{container}' does not set runAsNonRoot: true",
+			Help:        "set securityContext.runAsNonRoot: true on the pod or container",
+		},
+		Rule{
+			Name:        "pss-run-as-user",
+			Description: "Containers must not set runAsUser: 0",
+			Severity:    "ERROR",
+			Type:        "security",
+			Conditions:  []string{"run_as_user_zero"},
+			Message:     "{origin} '{container}' sets runAsUser: 0",
+			Help:        "set runAsUser to a non-zero UID",
+		},
+		Rule{
+			Name:        "pss-share-process-namespace",
+			Description: "Pods must not share a process namespace between containers",
+			Severity:    "ERROR",
+			Type:        "security",
+			Conditions:  []string{"share_process_namespace_true"},
+			Message:     "{kind} '{name}' sets shareProcessNamespace: true",
+			Help:        "remove shareProcessNamespace unless a sidecar must signal the app; it lets every container see and signal the others' processes and read their /proc/<pid>/root",
+		},
+	)
+}
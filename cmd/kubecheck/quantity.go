@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+)
+
+// quantityPattern splits a Kubernetes quantity into its number and suffix
+var quantityPattern = regexp.MustCompile(`^([+-]?(?:[0-9]+(?:\.[0-9]*)?|\.[0-9]+))([a-zA-Z]*|[eE][+-]?[0-9]+)$`)
+
+// quantitySuffixes maps binary and decimal SI suffixes to their multipliers
+var quantitySuffixes = map[string]float64{
+	"":   1,
+	"n":  1e-9,
+	"u":  1e-6,
+	"m":  1e-3,
+	"k":  1e3,
+	"M":  1e6,
+	"G":  1e9,
+	"T":  1e12,
+	"P":  1e15,
+	"E":  1e18,
+	"Ki": 1 << 10,
+	"Mi": 1 << 20,
+	"Gi": 1 << 30,
+	"Ti": 1 << 40,
+	"Pi": 1 << 50,
+	"Ei": 1 << 60,
+}
+
+// ParseQuantity parses a Kubernetes resource quantity such as "500m", "2Gi", or "1e3"
+// into base units (cores for CPU, bytes for memory)
+func ParseQuantity(quantity string) (float64, error) {
+	match := quantityPattern.FindStringSubmatch(quantity)
+	if match == nil {
+		return 0, fmt.Errorf("invalid quantity %q", quantity)
+	}
+
+	number, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid quantity %q: %w", quantity, err)
+	}
+
+	suffix := match[2]
+	if len(suffix) > 1 && (suffix[0] == 'e' || suffix[0] == 'E') {
+		exponent, err := strconv.Atoi(suffix[1:])
+		if err != nil {
+			return 0, fmt.Errorf("invalid quantity %q: %w", quantity, err)
+		}
+		return number * math.Pow10(exponent), nil
+	}
+
+	multiplier, ok := quantitySuffixes[suffix]
+	if !ok {
+		return 0, fmt.Errorf("invalid quantity %q: unknown suffix %q", quantity, suffix)
+	}
+	return number * multiplier, nil
+}
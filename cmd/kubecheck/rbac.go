@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PolicyRule represents an entry of a Role or ClusterRole rules list
+type PolicyRule struct {
+	APIGroups     []string
+	Resources     []string
+	Verbs         []string
+	ResourceNames []string
+}
+
+// secretReadVerbs are the verbs that expose Secret contents
+var secretReadVerbs = []string{"get", "list", "watch"}
+
+// isRBACRole reports whether a kind carries a rules list
+func isRBACRole(kind string) bool {
+	return kind == "Role" || kind == "ClusterRole"
+}
+
+// parsePolicyRules parses the rules list of a Role or ClusterRole
+func parsePolicyRules(resource K8sResource) []PolicyRule {
+	ruleList, ok := resource.Object["rules"].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var rules []PolicyRule
+	for _, r := range ruleList {
+		ruleMap, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		rules = append(rules, PolicyRule{
+			APIGroups:     getStringList(ruleMap, "apiGroups"),
+			Resources:     getStringList(ruleMap, "resources"),
+			Verbs:         getStringList(ruleMap, "verbs"),
+			ResourceNames: getStringList(ruleMap, "resourceNames"),
+		})
+	}
+	return rules
+}
+
+// rbacWildcard flags Role and ClusterRole rules whose field contains "*"
+// field is one of verbs, resources, or apiGroups
+func rbacWildcard(resource K8sResource, field string) (bool, string) {
+	if !isRBACRole(resource.Kind) {
+		return false, ""
+	}
+
+	var indexes []string
+	for i, rule := range parsePolicyRules(resource) {
+		var values []string
+		switch field {
+		case "verbs":
+			values = rule.Verbs
+		case "resources":
+			values = rule.Resources
+		case "apiGroups":
+			values = rule.APIGroups
+		}
+		if containsString(values, "*") {
+			indexes = append(indexes, fmt.Sprintf("rules[%d]", i))
+		}
+	}
+	if len(indexes) == 0 {
+		return false, ""
+	}
+	return true, fmt.Sprintf("%s (%s)", field, strings.Join(indexes, ", "))
+}
+
+// rbacSecretsRead flags ClusterRole rules that let subjects read every Secret
+// Rules limited by resourceNames only expose the named secrets and are skipped
+func rbacSecretsRead(resource K8sResource) (bool, string) {
+	if resource.Kind != "ClusterRole" {
+		return false, ""
+	}
+
+	for i, rule := range parsePolicyRules(resource) {
+		if len(rule.ResourceNames) > 0 {
+			continue
+		}
+		if !containsString(rule.APIGroups, "") && !containsString(rule.APIGroups, "*") {
+			continue
+		}
+		if !containsString(rule.Resources, "secrets") && !containsString(rule.Resources, "*") {
+			continue
+		}
+
+		var verbs []string
+		for _, verb := range secretReadVerbs {
+			if containsString(rule.Verbs, verb) || containsString(rule.Verbs, "*") {
+				verbs = append(verbs, verb)
+			}
+		}
+		if len(verbs) > 0 {
+			return true, fmt.Sprintf("%s on secrets (rules[%d])", strings.Join(verbs, "/"), i)
+		}
+	}
+	return false, ""
+}
+
+// RoleRef represents the role a RoleBinding or ClusterRoleBinding grants
+type RoleRef struct {
+	Kind string
+	Name string
+}
+
+// Subject represents an entry of a binding's subjects list
+type Subject struct {
+	Kind      string // User, Group, or ServiceAccount
+	Name      string
+	Namespace string
+}
+
+// String renders the subject as Kind 'name', qualifying ServiceAccounts with their namespace
+func (s Subject) String() string {
+	if s.Kind == "ServiceAccount" && s.Namespace != "" {
+		return fmt.Sprintf("%s '%s/%s'", s.Kind, s.Namespace, s.Name)
+	}
+	return fmt.Sprintf("%s '%s'", s.Kind, s.Name)
+}
+
+// systemGroups are built-in groups that should never receive bindings
+// system:masters bypasses RBAC entirely, and the other two cover every caller
+var systemGroups = []string{"system:unauthenticated", "system:authenticated", "system:masters"}
+
+// isRBACBinding reports whether a kind carries a roleRef and subjects
+func isRBACBinding(kind string) bool {
+	return kind == "RoleBinding" || kind == "ClusterRoleBinding"
+}
+
+// parseBinding parses the roleRef and subjects of a RoleBinding or ClusterRoleBinding
+func parseBinding(resource K8sResource) (RoleRef, []Subject) {
+	var roleRef RoleRef
+	if refMap, ok := resource.Object["roleRef"].(map[string]interface{}); ok {
+		roleRef = RoleRef{
+			Kind: getStringValue(refMap, "kind"),
+			Name: getStringValue(refMap, "name"),
+		}
+	}
+
+	var subjects []Subject
+	if subjectList, ok := resource.Object["subjects"].([]interface{}); ok {
+		for _, s := range subjectList {
+			if subjectMap, ok := s.(map[string]interface{}); ok {
+				subjects = append(subjects, Subject{
+					Kind:      getStringValue(subjectMap, "kind"),
+					Name:      getStringValue(subjectMap, "name"),
+					Namespace: getStringValue(subjectMap, "namespace"),
+				})
+			}
+		}
+	}
+
+	return roleRef, subjects
+}
+
+// bindingClusterAdmin flags bindings to the cluster-admin ClusterRole
+// A RoleBinding in an allowed namespace passes, and so does a ClusterRoleBinding
+// whose subjects are all ServiceAccounts in allowed namespaces
+func bindingClusterAdmin(resource K8sResource, allowedNamespaces []string) (bool, string) {
+	if !isRBACBinding(resource.Kind) {
+		return false, ""
+	}
+	roleRef, subjects := parseBinding(resource)
+	if roleRef.Kind != "ClusterRole" || roleRef.Name != "cluster-admin" {
+		return false, ""
+	}
+	if resource.Kind == "RoleBinding" && containsString(allowedNamespaces, getResourceNamespace(resource)) {
+		return false, ""
+	}
+
+	var offending []string
+	for _, subject := range subjects {
+		if resource.Kind == "ClusterRoleBinding" && subject.Kind == "ServiceAccount" && containsString(allowedNamespaces, subject.Namespace) {
+			continue
+		}
+		offending = append(offending, subject.String())
+	}
+	if resource.Kind == "ClusterRoleBinding" && len(offending) == 0 && len(subjects) > 0 {
+		return false, ""
+	}
+	return true, bindingDetails(roleRef, offending)
+}
+
+// bindingSystemSubject flags bindings whose subjects include a built-in system group
+func bindingSystemSubject(resource K8sResource) (bool, string) {
+	if !isRBACBinding(resource.Kind) {
+		return false, ""
+	}
+	roleRef, subjects := parseBinding(resource)
+
+	var offending []string
+	for _, subject := range subjects {
+		if subject.Kind == "Group" && containsString(systemGroups, subject.Name) {
+			offending = append(offending, subject.String())
+		}
+	}
+	if len(offending) == 0 {
+		return false, ""
+	}
+	return true, bindingDetails(roleRef, offending)
+}
+
+// bindingDetails describes a binding as "ClusterRole/name to Kind 'subject', ..."
+func bindingDetails(roleRef RoleRef, subjects []string) string {
+	details := roleRef.Kind + "/" + roleRef.Name
+	if len(subjects) > 0 {
+		details += " to " + strings.Join(subjects, ", ")
+	}
+	return details
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// rootCAConfigMap is published by the cluster in every namespace, so it is never in a scan
+const rootCAConfigMap = "kube-root-ca.crt"
+
+// ConfigReference is a pod's reference to a ConfigMap or Secret, or to one key of it
+type ConfigReference struct {
+	Kind     string // ConfigMap or Secret
+	Name     string
+	Key      string // "" when the whole object is referenced
+	Optional bool
+	// From describes where the reference appears, e.g. "env LOG_LEVEL"
+	From string
+}
+
+// parseVolumeReferences reads the references of a secret, configMap, or projected
+// source: one per listed item key, or one for the whole object without items
+func parseVolumeReferences(kind, name string, sourceMap map[string]interface{}) []ConfigReference {
+	optional := getBoolValue(sourceMap, "optional")
+	items, _ := sourceMap["items"].([]interface{})
+	if len(items) == 0 {
+		return []ConfigReference{{Kind: kind, Name: name, Optional: optional}}
+	}
+
+	var refs []ConfigReference
+	for _, item := range items {
+		if itemMap, ok := item.(map[string]interface{}); ok {
+			refs = append(refs, ConfigReference{Kind: kind, Name: name, Key: getStringValue(itemMap, "key"), Optional: optional})
+		}
+	}
+	return refs
+}
+
+// podConfigReferences collects every ConfigMap and Secret reference of a pod:
+// env valueFrom keys, envFrom sources, and volumes
+func podConfigReferences(podSpec *PodSpec) []ConfigReference {
+	var refs []ConfigReference
+	for _, c := range podSpec.Containers {
+		for _, env := range c.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			ref := ConfigReference{Name: env.ValueFrom.Name, Key: env.ValueFrom.Key, Optional: env.ValueFrom.Optional}
+			switch env.ValueFrom.Type {
+			case "configMapKeyRef":
+				ref.Kind = "ConfigMap"
+			case "secretKeyRef":
+				ref.Kind = "Secret"
+			default:
+				continue
+			}
+			ref.From = fmt.Sprintf("env %s of %s '%s'", env.Name, c.Origin, c.Name)
+			refs = append(refs, ref)
+		}
+		for _, source := range c.EnvFrom {
+			kind := "ConfigMap"
+			if source.Type == "secretRef" {
+				kind = "Secret"
+			}
+			refs = append(refs, ConfigReference{
+				Kind:     kind,
+				Name:     source.Name,
+				Optional: source.Optional,
+				From:     fmt.Sprintf("envFrom of %s '%s'", c.Origin, c.Name),
+			})
+		}
+	}
+	for _, v := range podSpec.Volumes {
+		for _, ref := range v.References {
+			ref.From = fmt.Sprintf("volume '%s'", v.Name)
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// configReferenceMissing flags workloads that reference a ConfigMap or Secret,
+// or a key of one, that the scan does not hold in the same namespace
+// Optional references are skipped. The details list each unresolved reference
+func configReferenceMissing(resource K8sResource, bundle *Bundle, specPaths map[string]string) (bool, string) {
+	podSpec := extractPodSpec(resource, specPaths)
+	if podSpec == nil {
+		return false, ""
+	}
+
+	namespace := getResourceNamespace(resource)
+	var missing []string
+	for _, ref := range podConfigReferences(podSpec) {
+		if ref.Optional || ref.Name == "" || (ref.Kind == "ConfigMap" && ref.Name == rootCAConfigMap) {
+			continue
+		}
+
+		keys, found := referencedObjectKeys(ref, namespace, bundle)
+		switch {
+		case !found:
+			missing = append(missing, fmt.Sprintf("%s '%s' (%s)", ref.Kind, ref.Name, ref.From))
+		case ref.Key != "" && !containsString(keys, ref.Key):
+			missing = append(missing, fmt.Sprintf("key '%s' of %s '%s' (%s)", ref.Key, ref.Kind, ref.Name, ref.From))
+		}
+	}
+	return len(missing) > 0, strings.Join(missing, "; ")
+}
+
+// referencedObjectKeys returns the data keys of the object a reference names,
+// and false when the scan has no such object in the namespace
+func referencedObjectKeys(ref ConfigReference, namespace string, bundle *Bundle) ([]string, bool) {
+	for _, other := range bundle.Resources {
+		if other.Kind != ref.Kind || getResourceName(other) != ref.Name || getResourceNamespace(other) != namespace {
+			continue
+		}
+
+		entries := parseConfigMapEntries(other)
+		if other.Kind == "Secret" {
+			entries = parseSecretEntries(other)
+		}
+		var keys []string
+		for _, entry := range entries {
+			keys = append(keys, entry.Key)
+		}
+		return keys, true
+	}
+	return nil, false
+}
+
+// serviceAccountMissing flags workloads whose serviceAccountName names no
+// ServiceAccount in the same namespace of the scan; "default" always exists
+// The details are the ServiceAccount and its expected namespace
+func serviceAccountMissing(resource K8sResource, bundle *Bundle, specPaths map[string]string) (bool, string) {
+	podSpec := extractPodSpec(resource, specPaths)
+	if podSpec == nil || podSpec.ServiceAccountName == "" || podSpec.ServiceAccountName == "default" {
+		return false, ""
+	}
+
+	namespace := getResourceNamespace(resource)
+	for _, other := range bundle.Resources {
+		if other.Kind == "ServiceAccount" && getResourceName(other) == podSpec.ServiceAccountName && getResourceNamespace(other) == namespace {
+			return false, ""
+		}
+	}
+	return true, fmt.Sprintf("'%s' in namespace '%s'", podSpec.ServiceAccountName, namespace)
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// regoRulePrefix starts the rule name of Rego findings, followed by the
+// policy package, e.g. rego:kubernetes.security
+const regoRulePrefix = "rego:"
+
+// regoSeverities maps the rules a policy package may define to the
+// severity of their results; violation is the name some conftest
+// policies use for deny
+var regoSeverities = map[string]string{
+	"deny":      "ERROR",
+	"violation": "ERROR",
+	"warn":      "WARN",
+}
+
+// regoPackagePattern finds the package declaration of a Rego module
+var regoPackagePattern = regexp.MustCompile(`(?m)^\s*package\s+([A-Za-z0-9_.]+)`)
+
+// RegoPolicies evaluates the Rego modules of the config's regoPolicies with
+// the opa binary, the same way conftest policies run
+type RegoPolicies struct {
+	files    []string
+	packages []string
+}
+
+// LoadRegoPolicies collects the .rego files under the given files and
+// directories and compiles them with opa check, so policy errors fail
+// startup rather than the first evaluation. Test modules are skipped
+func LoadRegoPolicies(paths []string) (*RegoPolicies, error) {
+	if _, err := exec.LookPath("opa"); err != nil {
+		return nil, fmt.Errorf("regoPolicies needs the opa binary on PATH: %w", err)
+	}
+
+	policies := &RegoPolicies{}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Rego policies: %w", err)
+		}
+		if !info.IsDir() {
+			policies.files = append(policies.files, path)
+			continue
+		}
+		err = filepath.WalkDir(path, func(file string, entry os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !entry.IsDir() && strings.HasSuffix(file, ".rego") && !strings.HasSuffix(file, "_test.rego") {
+				policies.files = append(policies.files, file)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Rego policies: %w", err)
+		}
+	}
+	if len(policies.files) == 0 {
+		return nil, fmt.Errorf("no .rego files found in regoPolicies %s", strings.Join(paths, ", "))
+	}
+
+	for _, file := range policies.files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Rego policy: %w", err)
+		}
+		match := regoPackagePattern.FindSubmatch(data)
+		if match == nil {
+			return nil, fmt.Errorf("%s: no package declaration", file)
+		}
+		policies.packages = appendUnique(policies.packages, string(match[1]))
+	}
+	sort.Strings(policies.packages)
+
+	cmd := exec.Command("opa", append([]string{"check", "--format", "json"}, policies.files...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, regoCheckError(stdout.Bytes(), stderr.Bytes())
+	}
+	return policies, nil
+}
+
+// regoCheckError turns the JSON errors of opa check, on either stream, into
+// one error listing each problem as file:line:column: message
+func regoCheckError(stdout, stderr []byte) error {
+	var result struct {
+		Errors []struct {
+			Message  string `json:"message"`
+			Location *struct {
+				File string `json:"file"`
+				Row  int    `json:"row"`
+				Col  int    `json:"col"`
+			} `json:"location"`
+		} `json:"errors"`
+	}
+	if json.Unmarshal(stdout, &result) != nil || len(result.Errors) == 0 {
+		if json.Unmarshal(stderr, &result) != nil || len(result.Errors) == 0 {
+			output := strings.TrimSpace(string(stderr) + string(stdout))
+			return fmt.Errorf("failed to compile Rego policies: %s", output)
+		}
+	}
+
+	lines := make([]string, 0, len(result.Errors))
+	for _, e := range result.Errors {
+		if e.Location == nil {
+			lines = append(lines, e.Message)
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s:%d:%d: %s", e.Location.File, e.Location.Row, e.Location.Col, e.Message))
+	}
+	return fmt.Errorf("failed to compile Rego policies:\n  %s", strings.Join(lines, "\n  "))
+}
+
+// regoBatchQuery evaluates the policies once per resource of the input
+// array, with that resource as input, keyed by its index
+const regoBatchQuery = "{i: document | some i; resource := input[i]; document := data with input as resource}"
+
+// Evaluate runs the policies over all resources of a scan in one opa eval,
+// each resource as the input of its own evaluation, and returns the
+// violations of each resource by index: one per result of their deny,
+// violation, and warn rules
+func (p *RegoPolicies) Evaluate(resources []K8sResource) ([][]Violation, error) {
+	violations := make([][]Violation, len(resources))
+	if len(resources) == 0 {
+		return violations, nil
+	}
+
+	objects := make([]json.RawMessage, len(resources))
+	for i, resource := range resources {
+		object, err := json.Marshal(resource.Object)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s '%s' for Rego: %w", resource.Kind, getResourceName(resource), err)
+		}
+		objects[i] = object
+	}
+	input, err := json.Marshal(objects)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode resources for Rego: %w", err)
+	}
+
+	args := []string{"eval", "--format", "json", "--stdin-input"}
+	for _, file := range p.files {
+		args = append(args, "--data", file)
+	}
+	args = append(args, regoBatchQuery)
+
+	cmd := exec.Command("opa", args...)
+	cmd.Stdin = bytes.NewReader(input)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate Rego policies: %s", strings.TrimSpace(stderr.String()+string(output)))
+	}
+
+	var result struct {
+		Result []struct {
+			Expressions []struct {
+				Value map[string]map[string]interface{} `json:"value"`
+			} `json:"expressions"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("failed to read opa eval output: %w", err)
+	}
+	if len(result.Result) == 0 || len(result.Result[0].Expressions) == 0 {
+		return violations, nil
+	}
+	// opa writes the numeric keys of the result object as strings
+	for key, data := range result.Result[0].Expressions[0].Value {
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i >= len(resources) {
+			return nil, fmt.Errorf("failed to read opa eval output: unexpected resource index %q", key)
+		}
+		violations[i] = p.violations(data)
+	}
+	return violations, nil
+}
+
+// violations turns the data document one resource evaluated to into
+// violations
+func (p *RegoPolicies) violations(data map[string]interface{}) []Violation {
+	var violations []Violation
+	for _, pkg := range p.packages {
+		document := regoDocument(data, pkg)
+		for _, name := range []string{"deny", "violation", "warn"} {
+			for _, message := range regoMessages(document[name]) {
+				violations = append(violations, Violation{
+					Severity: regoSeverities[name],
+					Message:  message,
+					Rule:     regoRulePrefix + pkg,
+					Help:     "reported by Rego package " + pkg,
+				})
+			}
+		}
+	}
+	return violations
+}
+
+// regoDocument returns the document of a package, such as data.kubernetes.security
+func regoDocument(data map[string]interface{}, pkg string) map[string]interface{} {
+	document := data
+	for _, part := range strings.Split(pkg, ".") {
+		next, ok := document[part].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		document = next
+	}
+	return document
+}
+
+// regoMessages reads the results of a deny or warn rule: a set of strings,
+// or of objects with a msg field as conftest writes them
+func regoMessages(value interface{}) []string {
+	var results []interface{}
+	switch value := value.(type) {
+	case []interface{}:
+		results = value
+	case string, map[string]interface{}:
+		results = []interface{}{value}
+	}
+
+	var messages []string
+	for _, result := range results {
+		switch result := result.(type) {
+		case string:
+			messages = append(messages, result)
+		case map[string]interface{}:
+			if msg, ok := result["msg"].(string); ok {
+				messages = append(messages, msg)
+				continue
+			}
+			encoded, _ := json.Marshal(result)
+			messages = append(messages, string(encoded))
+		default:
+			encoded, _ := json.Marshal(result)
+			messages = append(messages, string(encoded))
+		}
+	}
+	return messages
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// remoteConfigTimeout bounds the whole fetch of a config URL
+const remoteConfigTimeout = 10 * time.Second
+
+// maxRemoteConfigSize caps the size of a fetched config
+const maxRemoteConfigSize = 1 << 20
+
+// maxRemoteConfigRedirects caps the redirects followed for a config URL, as
+// net/http does by default
+const maxRemoteConfigRedirects = 10
+
+// ConfigLoadOptions controls how LoadRuleConfig reads configs
+type ConfigLoadOptions struct {
+	// SHA256 pins the hex SHA-256 digest of the config named on the command
+	// line; configs it extends are not pinned
+	SHA256 string
+	// CacheDir holds fetched configs, so a URL still loads when the network
+	// is down
+	CacheDir string
+}
+
+// isConfigURL reports whether a config path is a URL rather than a file
+func isConfigURL(path string) bool {
+	return strings.HasPrefix(path, "https://") || strings.HasPrefix(path, "http://")
+}
+
+// resolveExtends returns the location of a config that the config at path
+// extends: URLs resolve against a URL config, and relative file paths
+// against the directory of a file config
+func resolveExtends(path, extends string) (string, error) {
+	if isConfigURL(path) {
+		base, err := url.Parse(path)
+		if err != nil {
+			return "", fmt.Errorf("invalid config URL %s: %w", path, err)
+		}
+		ref, err := url.Parse(extends)
+		if err != nil {
+			return "", fmt.Errorf("invalid extends %q in %s: %w", extends, path, err)
+		}
+		return base.ResolveReference(ref).String(), nil
+	}
+	if isConfigURL(extends) || filepath.IsAbs(extends) {
+		return extends, nil
+	}
+	return filepath.Join(filepath.Dir(path), extends), nil
+}
+
+// readConfig returns the contents of a config file or URL, checked against
+// the pinned digest when one is given
+func readConfig(path string, options ConfigLoadOptions) ([]byte, error) {
+	var data []byte
+	var err error
+	if isConfigURL(path) {
+		data, err = fetchConfig(path, options.CacheDir)
+	} else {
+		data, err = os.ReadFile(path)
+		if err != nil {
+			err = fmt.Errorf("failed to read config file: %w", err)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if options.SHA256 != "" {
+		sum := sha256.Sum256(data)
+		if digest := hex.EncodeToString(sum[:]); !strings.EqualFold(digest, options.SHA256) {
+			return nil, fmt.Errorf("config %s has SHA-256 %s, but --config-sha256 expects %s", path, digest, options.SHA256)
+		}
+	}
+	return data, nil
+}
+
+// fetchConfig downloads a config over HTTPS, revalidating the cached copy
+// with its ETag. When the server cannot be reached, or fails, the cached
+// copy is used instead with a warning
+func fetchConfig(rawURL, cacheDir string) ([]byte, error) {
+	if !strings.HasPrefix(rawURL, "https://") {
+		return nil, fmt.Errorf("config URL %s must use https", rawURL)
+	}
+
+	key := sha256.Sum256([]byte(rawURL))
+	cachePath := filepath.Join(cacheDir, hex.EncodeToString(key[:])+".yaml")
+	etagPath := strings.TrimSuffix(cachePath, ".yaml") + ".etag"
+	cached, cacheErr := os.ReadFile(cachePath)
+
+	data, etag, err := downloadConfig(rawURL, cachedETag(etagPath, cacheErr))
+	switch {
+	case err == nil && data == nil:
+		// 304 Not Modified
+		return cached, nil
+	case err == nil:
+		if err := writeConfigCache(cacheDir, cachePath, etagPath, data, etag); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		return data, nil
+	case errors.Is(err, errConfigUnavailable) && cacheErr == nil:
+		fmt.Fprintf(os.Stderr, "Warning: %v; using the cached copy\n", err)
+		return cached, nil
+	case errors.Is(err, errConfigUnavailable):
+		return nil, fmt.Errorf("%w, and there is no cached copy in %s", err, cacheDir)
+	default:
+		return nil, err
+	}
+}
+
+// errConfigUnavailable marks fetch failures the cache can stand in for: the
+// server could not be reached or answered with a server error
+var errConfigUnavailable = errors.New("config server unavailable")
+
+// cachedETag returns the ETag saved with the cached copy, or "" when there
+// is no cached copy to revalidate
+func cachedETag(etagPath string, cacheErr error) string {
+	if cacheErr != nil {
+		return ""
+	}
+	etag, err := os.ReadFile(etagPath)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(etag))
+}
+
+// errInsecureRedirect marks a config URL that redirects away from https. It
+// fails the fetch outright rather than falling back to the cached copy
+var errInsecureRedirect = errors.New("config URL redirects to a non-https URL")
+
+// newRemoteConfigClient returns the HTTP client configs are fetched with
+func newRemoteConfigClient() *http.Client {
+	return &http.Client{
+		Timeout:       remoteConfigTimeout,
+		CheckRedirect: rejectInsecureRedirect,
+	}
+}
+
+// rejectInsecureRedirect stops a config fetch from following a redirect to
+// anything but https, so --config-sha256 is not the only guard against a
+// downgraded fetch
+func rejectInsecureRedirect(request *http.Request, via []*http.Request) error {
+	if request.URL.Scheme != "https" {
+		return fmt.Errorf("%w: %s", errInsecureRedirect, request.URL.Redacted())
+	}
+	if len(via) >= maxRemoteConfigRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRemoteConfigRedirects)
+	}
+	return nil
+}
+
+// downloadConfig fetches a config URL, sending etag as If-None-Match. It
+// returns nil data when the server answers 304 Not Modified
+func downloadConfig(rawURL, etag string) ([]byte, string, error) {
+	return downloadConfigWith(newRemoteConfigClient(), rawURL, etag)
+}
+
+// downloadConfigWith is downloadConfig with the HTTP client passed in
+func downloadConfigWith(client *http.Client, rawURL, etag string) ([]byte, string, error) {
+	request, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid config URL %s: %w", rawURL, err)
+	}
+	if etag != "" {
+		request.Header.Set("If-None-Match", etag)
+	}
+
+	response, err := client.Do(request)
+	if errors.Is(err, errInsecureRedirect) {
+		return nil, "", fmt.Errorf("failed to fetch config %s: %w", rawURL, err)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch config %s: %w: %v", rawURL, errConfigUnavailable, err)
+	}
+	defer response.Body.Close()
+
+	switch {
+	case response.StatusCode == http.StatusNotModified && etag != "":
+		return nil, "", nil
+	case response.StatusCode >= 500:
+		return nil, "", fmt.Errorf("failed to fetch config %s: %w: %s", rawURL, errConfigUnavailable, response.Status)
+	case response.StatusCode != http.StatusOK:
+		return nil, "", fmt.Errorf("failed to fetch config %s: %s", rawURL, response.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(response.Body, maxRemoteConfigSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch config %s: %w: %v", rawURL, errConfigUnavailable, err)
+	}
+	if len(data) > maxRemoteConfigSize {
+		return nil, "", fmt.Errorf("config %s is larger than %d bytes", rawURL, maxRemoteConfigSize)
+	}
+	return data, response.Header.Get("ETag"), nil
+}
+
+// writeConfigCache saves a fetched config and its ETag
+func writeConfigCache(cacheDir, cachePath, etagPath string, data []byte, etag string) error {
+	if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+		return fmt.Errorf("failed to cache config: %w", err)
+	}
+	if err := os.WriteFile(cachePath, data, 0o644); err != nil {
+		return fmt.Errorf("failed to cache config: %w", err)
+	}
+	if etag == "" {
+		os.Remove(etagPath)
+		return nil
+	}
+	if err := os.WriteFile(etagPath, []byte(etag), 0o644); err != nil {
+		return fmt.Errorf("failed to cache config: %w", err)
+	}
+	return nil
+}
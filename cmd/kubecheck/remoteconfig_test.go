@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// tlsConfigClient returns the config client, trusting the test server's
+// certificate
+func tlsConfigClient(server *httptest.Server) *http.Client {
+	client := newRemoteConfigClient()
+	client.Transport = server.Client().Transport
+	return client
+}
+
+func TestDownloadConfigRejectsRedirectToHTTP(t *testing.T) {
+	insecure := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("rules: []\n"))
+	}))
+	defer insecure.Close()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, insecure.URL+"/kubecheck.yaml", http.StatusFound)
+	}))
+	defer server.Close()
+
+	_, _, err := downloadConfigWith(tlsConfigClient(server), server.URL+"/kubecheck.yaml", "")
+	if !errors.Is(err, errInsecureRedirect) {
+		t.Fatalf("expected an insecure redirect error, got %v", err)
+	}
+	if errors.Is(err, errConfigUnavailable) {
+		t.Fatalf("insecure redirect must not fall back to the cache: %v", err)
+	}
+}
+
+func TestDownloadConfigFollowsRedirectToHTTPS(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old.yaml" {
+			http.Redirect(w, r, server.URL+"/kubecheck.yaml", http.StatusMovedPermanently)
+			return
+		}
+		w.Write([]byte("rules: []\n"))
+	}))
+	defer server.Close()
+
+	data, _, err := downloadConfigWith(tlsConfigClient(server), server.URL+"/old.yaml", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != "rules: []\n" {
+		t.Fatalf("unexpected config %q", data)
+	}
+}
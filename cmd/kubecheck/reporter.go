@@ -1,7 +1,9 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"strings"
 )
 
@@ -54,6 +56,9 @@ type Violation struct {
 	Severity string `json:"severity"`
 	Message  string `json:"message"`
 	Rule     string `json:"rule"`
+	Help     string `json:"help,omitempty"`
+	// Profile names the built-in profile whose rule produced the violation
+	Profile string `json:"profile,omitempty"`
 }
 
 // Reporter handles output formatting and violation tracking
@@ -65,6 +70,8 @@ type Reporter struct {
 	errorFiles      int
 	totalViolations int
 	isDirectory     bool
+	skipped         []SkippedPath
+	suppressed      []Suppression
 }
 
 // NewReporter creates a new reporter
@@ -79,6 +86,17 @@ func (r *Reporter) SetDirectoryMode(enabled bool) {
 	r.isDirectory = enabled
 }
 
+// AddSkippedPath records a path that could not be read during the scan
+func (r *Reporter) AddSkippedPath(skipped SkippedPath) {
+	r.skipped = append(r.skipped, skipped)
+}
+
+// AddSuppressed records findings that were left out of the report, so the
+// summary can count them
+func (r *Reporter) AddSuppressed(suppressed ...Suppression) {
+	r.suppressed = append(r.suppressed, suppressed...)
+}
+
 // ReportViolations reports violations for a resource and returns the highest severity
 func (r *Reporter) ReportViolations(filename string, resource K8sResource, violations []Violation) int {
 	r.totalFiles++
@@ -243,6 +261,9 @@ func (r *Reporter) printDirectoryViolations(filename string, resource K8sResourc
 	for i, v := range violations {
 		isLast := i == len(violations)-1
 		resourceName := getResourceName(resource)
+		if v.Profile != "" {
+			v.Message = "[" + v.Profile + "] " + v.Message
+		}
 
 		if i == 0 {
 			fmt.Printf("     %s [%s] %s%s\n",
@@ -270,6 +291,10 @@ func (r *Reporter) printViolationDetail(v Violation, border string) {
 		label = "Resource Hygiene"
 	}
 
+	if v.Profile != "" {
+		label += " [" + v.Profile + "]"
+	}
+
 	// icon + label line
 	innerLabel := fmt.Sprintf("  %s  %s", symbol, label)
 	labelPad := max(0, boxInnerWidth-len([]rune(innerLabel)))
@@ -279,37 +304,42 @@ func (r *Reporter) printViolationDetail(v Violation, border string) {
 		strings.Repeat(" ", labelPad),
 		ColorCyan, BoxVertical, ColorReset)
 
-	// message line
-	innerMsg := fmt.Sprintf("     %s", v.Message)
-	msgPad := max(0, boxInnerWidth-len([]rune(innerMsg)))
-	fmt.Printf("  %s%s%s%s%s%s%s\n",
-		ColorCyan, border,
-		ColorBold+innerMsg+ColorReset,
-		strings.Repeat(" ", msgPad),
-		ColorCyan, BoxVertical, ColorReset)
-
-	// help line
-	if v.Rule == "no-latest-image" {
-		innerHelp := fmt.Sprintf("     %s use a specific version or digest", SymbolPointer+"───")
-		helpPad := max(0, boxInnerWidth-len([]rune(innerHelp)))
-		fmt.Printf("  %s%s%s%s%s%s%s\n",
-			ColorCyan, border,
-			ColorGray+innerHelp+ColorReset,
-			strings.Repeat(" ", helpPad),
-			ColorCyan, BoxVertical, ColorReset)
-	} else if v.Rule == "no-root-containers" {
-		innerHelp := "     help: set 'runAsNonRoot: true' to improve pod security"
-		helpPad := max(0, boxInnerWidth-len([]rune(innerHelp)))
+	// message lines, wrapped to leave a space before the border
+	for _, line := range wrapText(v.Message, boxInnerWidth-6) {
+		innerMsg := fmt.Sprintf("     %s", line)
+		msgPad := max(0, boxInnerWidth-len([]rune(innerMsg)))
 		fmt.Printf("  %s%s%s%s%s%s%s\n",
 			ColorCyan, border,
-			ColorGray+innerHelp+ColorReset,
-			strings.Repeat(" ", helpPad),
+			ColorBold+innerMsg+ColorReset,
+			strings.Repeat(" ", msgPad),
 			ColorCyan, BoxVertical, ColorReset)
 	}
+
+	// help lines, wrapped to leave a space before the border
+	if v.Help != "" {
+		prefix := fmt.Sprintf("     %s ", SymbolPointer+"───")
+		indent := strings.Repeat(" ", len([]rune(prefix)))
+		for i, line := range wrapText(v.Help, boxInnerWidth-len([]rune(prefix))-1) {
+			innerHelp := indent + line
+			if i == 0 {
+				innerHelp = prefix + line
+			}
+			helpPad := max(0, boxInnerWidth-len([]rune(innerHelp)))
+			fmt.Printf("  %s%s%s%s%s%s%s\n",
+				ColorCyan, border,
+				ColorGray+innerHelp+ColorReset,
+				strings.Repeat(" ", helpPad),
+				ColorCyan, BoxVertical, ColorReset)
+		}
+	}
 }
 
-// PrintSummary prints the final summary
-func (r *Reporter) PrintSummary() {
+// PrintSummary prints the final summary, with the exit code the process is
+// about to return
+func (r *Reporter) PrintSummary(exitCode int) {
+	r.printSkippedPaths()
+	r.printSuppressed()
+
 	if r.totalFiles == 0 {
 		return
 	}
@@ -339,17 +369,7 @@ func (r *Reporter) PrintSummary() {
 		}
 		fmt.Println()
 
-		// Final status
-		if r.errorFiles > 0 {
-			fmt.Printf("  Status  %s %sFAILED%s Exit code: 2\n",
-				SymbolArrow, ColorRed+ColorBold, ColorReset)
-		} else if r.warnFiles > 0 {
-			fmt.Printf("  Status  %s %sPASSED WITH WARNINGS%s Exit code: 1\n",
-				SymbolArrow, ColorYellow+ColorBold, ColorReset)
-		} else {
-			fmt.Printf("  Status  %s %sPASSED%s Exit code: 0\n",
-				SymbolArrow, ColorGreen+ColorBold, ColorReset)
-		}
+		r.printStatus(exitCode)
 
 		fmt.Printf("\n  %s\n", strings.Repeat(BoxDivider, 70))
 	} else {
@@ -357,6 +377,98 @@ func (r *Reporter) PrintSummary() {
 		fmt.Printf("\n  Summary %s %d file checked. %s%d violation%s found.%s\n",
 			SymbolArrow, r.totalFiles,
 			ColorBold, r.totalViolations, pluralize(r.totalViolations), ColorReset)
+		r.printStatus(exitCode)
+	}
+}
+
+// printStatus prints the final status line. Whether the scan passed follows
+// the exit code, which --fail-on and exitCodes may decouple from severities
+func (r *Reporter) printStatus(exitCode int) {
+	switch {
+	case exitCode != 0:
+		fmt.Printf("  Status  %s %sFAILED%s Exit code: %d\n",
+			SymbolArrow, ColorRed+ColorBold, ColorReset, exitCode)
+	case r.errorFiles > 0:
+		fmt.Printf("  Status  %s %sPASSED WITH ERRORS%s Exit code: %d\n",
+			SymbolArrow, ColorYellow+ColorBold, ColorReset, exitCode)
+	case r.warnFiles > 0:
+		fmt.Printf("  Status  %s %sPASSED WITH WARNINGS%s Exit code: %d\n",
+			SymbolArrow, ColorYellow+ColorBold, ColorReset, exitCode)
+	default:
+		fmt.Printf("  Status  %s %sPASSED%s Exit code: %d\n",
+			SymbolArrow, ColorGreen+ColorBold, ColorReset, exitCode)
+	}
+}
+
+// printSkippedPaths prints warnings for paths that could not be read
+func (r *Reporter) printSkippedPaths() {
+	if len(r.skipped) == 0 {
+		return
+	}
+
+	permissionCount := 0
+	vanishedCount := 0
+	otherCount := 0
+	for _, s := range r.skipped {
+		switch {
+		case errors.Is(s.Err, fs.ErrPermission):
+			permissionCount++
+		case errors.Is(s.Err, fs.ErrNotExist):
+			vanishedCount++
+		default:
+			otherCount++
+		}
+	}
+
+	fmt.Println()
+	if permissionCount > 0 {
+		fmt.Printf("  %s%s %d path%s skipped due to permission errors%s\n",
+			ColorYellow, SymbolWarning, permissionCount, pluralize(permissionCount), ColorReset)
+	}
+	if vanishedCount > 0 {
+		fmt.Printf("  %s%s %d path%s skipped because they disappeared during the scan%s\n",
+			ColorYellow, SymbolWarning, vanishedCount, pluralize(vanishedCount), ColorReset)
+	}
+	if otherCount > 0 {
+		fmt.Printf("  %s%s %d path%s skipped due to read errors%s\n",
+			ColorYellow, SymbolWarning, otherCount, pluralize(otherCount), ColorReset)
+	}
+
+	if r.verbose {
+		for _, s := range r.skipped {
+			fmt.Printf("     %s%s %s: %v%s\n", ColorGray, SymbolTree, s.Path, s.Err, ColorReset)
+		}
+	}
+}
+
+// printSuppressed counts the findings left out of the report by each kind of
+// suppression, and lists them in verbose mode
+func (r *Reporter) printSuppressed() {
+	if len(r.suppressed) == 0 {
+		return
+	}
+
+	var reasons []string
+	counts := make(map[string]int)
+	for _, s := range r.suppressed {
+		if counts[s.Reason] == 0 {
+			reasons = append(reasons, s.Reason)
+		}
+		counts[s.Reason]++
+	}
+
+	fmt.Println()
+	for _, reason := range reasons {
+		fmt.Printf("  %s%s %d finding%s suppressed by %s%s\n",
+			ColorYellow, SymbolWarning, counts[reason], pluralize(counts[reason]), reason, ColorReset)
+		if !r.verbose {
+			continue
+		}
+		for _, s := range r.suppressed {
+			if s.Reason == reason {
+				fmt.Printf("     %s%s %s: %s%s\n", ColorGray, SymbolTree, s.Resource, s.Violation.Rule, ColorReset)
+			}
+		}
 	}
 }
 
@@ -387,6 +499,27 @@ func max(a, b int) int {
 	return b
 }
 
+// wrapText splits text into lines of at most width runes, breaking on spaces
+func wrapText(text string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && len([]rune(line))+1+len([]rune(word)) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line == "" {
+			line = word
+		} else {
+			line += " " + word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
 func pluralize(count int) string {
 	if count == 1 {
 		return ""
@@ -1,111 +1,905 @@
 package main
 
 import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // RuleEngine evaluates YAML-defined rules against Kubernetes resources
 type RuleEngine struct {
 	config *RuleConfig
+	// rules are the enabled rules of the config
+	rules       []Rule
+	kubeVersion KubeVersion
+	// suppressed collects the findings that exclusions and ignores kept out of the report
+	suppressed []Suppression
+	// inlineIgnores honors kubecheck.io/ignore annotations and kubecheck-ignore comments
+	inlineIgnores bool
 }
 
-// NewRuleEngine creates a new rule engine with the given config
-func NewRuleEngine(config *RuleConfig) *RuleEngine {
-	return &RuleEngine{
-		config: config,
+// NewRuleEngine creates a new rule engine with the given config. It parses
+// the conditions and compiles the CEL programs of the enabled rules up front
+// rather than once per container, failing on any that do not parse
+func NewRuleEngine(config *RuleConfig) (*RuleEngine, error) {
+	kubeVersion, _ := ParseKubeVersion(defaultKubeVersion)
+	re := &RuleEngine{
+		config:        config,
+		kubeVersion:   kubeVersion,
+		inlineIgnores: true,
 	}
+
+	for _, rule := range config.Rules {
+		if config.RuleEnabled(rule) {
+			re.rules = append(re.rules, rule)
+		}
+	}
+
+	for i, rule := range re.rules {
+		conditions, err := config.parseConditions(rule)
+		if err != nil {
+			return nil, err
+		}
+		re.rules[i].parsed = conditions
+		if rule.Type == celRuleType {
+			if re.rules[i].program, err = compileCEL(rule.Expression); err != nil {
+				return nil, fmt.Errorf("rule %q: %w", rule.Name, err)
+			}
+		}
+		if rule.When != nil {
+			if err := config.parseWhen(rule); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return re, nil
+}
+
+// SetKubeVersion sets the Kubernetes version apiVersion conditions target
+func (re *RuleEngine) SetKubeVersion(version KubeVersion) {
+	re.kubeVersion = version
+}
+
+// SetInlineIgnores turns annotation and comment ignores on or off; strict
+// pipelines turn them off so nothing can be silenced from a manifest
+func (re *RuleEngine) SetInlineIgnores(enabled bool) {
+	re.inlineIgnores = enabled
+}
+
+// appliesTo reports whether a rule evaluates a resource at all: its kind,
+// file, namespace, and labels must all be in the rule's scope
+func (re *RuleEngine) appliesTo(rule Rule, resource K8sResource) bool {
+	return rule.AppliesToKind(resource.APIVersion, resource.Kind) &&
+		rule.AppliesToPath(resource.File) &&
+		rule.AppliesToScope(resource, re.config.IsClusterScoped(resource.Kind), re.config.PodSpecPaths)
 }
 
 // EvaluateResource evaluates all rules against a Kubernetes resource
 func (re *RuleEngine) EvaluateResource(resource K8sResource) []Violation {
 	var violations []Violation
 
-	// Extract containers from the resource
-	containers := extractContainersFromResource(resource)
+	// Extract the pod spec and its containers from the resource
+	podSpec := extractPodSpec(resource, re.config.PodSpecPaths)
+	var containers []Container
+	if podSpec != nil {
+		containers = podSpec.Containers
+	}
 
 	// Evaluate each rule
-	for _, rule := range re.config.Rules {
+	for _, rule := range re.rules {
+		if !re.appliesTo(rule, resource) {
+			continue
+		}
+
+		violations = append(violations, re.ApplyIgnores(resource, re.evaluateResourceRule(rule, resource, podSpec))...)
+
 		for _, container := range containers {
-			containerViolations := re.evaluateRule(rule, container)
+			if !rule.AppliesToOrigin(container.Origin) {
+				continue
+			}
+			containerViolations := re.evaluateRule(rule, resource, podSpec, container)
 			violations = append(violations, containerViolations...)
 		}
 	}
 
+	violations = append(violations, re.unknownIgnores(resource)...)
 	return violations
 }
 
 // evaluateRule evaluates a single rule against a container
-func (re *RuleEngine) evaluateRule(rule Rule, container Container) []Violation {
+func (re *RuleEngine) evaluateRule(rule Rule, resource K8sResource, podSpec *PodSpec, container Container) []Violation {
 	var violations []Violation
 
-	for _, condition := range rule.Conditions {
-		if re.checkCondition(condition, container) {
-			// Replace {container} placeholder in message
-			message := strings.ReplaceAll(rule.Message, "{container}", container.Name)
+	// Excluded and ignored containers are still evaluated so the summary can
+	// count what the suppression hides
+	suppressedBy := re.containerSuppression(rule, resource, container)
 
-			violation := Violation{
-				Severity: rule.Severity,
-				Message:  message,
-				Rule:     rule.Name,
-			}
+	// Env vars on the rule's allowlist are invisible to its conditions
+	if len(rule.AllowEnv) > 0 {
+		container = container.withoutEnv(rule.AllowEnv)
+	}
+
+	if matched, details := re.matchContainer(rule, resource, podSpec, container); matched {
+		// Replace {origin}, {container} and {details} placeholders in message
+		message := strings.ReplaceAll(rule.Message, "{origin}", originLabel(container.Origin))
+		message = strings.ReplaceAll(message, "{container}", container.Name)
+		message = strings.ReplaceAll(message, "{details}", details)
+
+		violation := Violation{
+			Severity: rule.Severity,
+			Message:  message,
+			Rule:     rule.Name,
+			Help:     rule.Help,
+			Profile:  rule.Profile,
+		}
+		if suppressedBy != "" {
+			re.suppress(violation, containerSubject(resource, container), suppressedBy)
+		} else {
 			violations = append(violations, violation)
-			break // Only report one violation per rule per container
 		}
 	}
 
 	return violations
 }
 
+// matchContainer reports whether a rule matches a container: its when
+// expression if it holds container conditions, otherwise the first of its
+// conditions that matches. Only one violation is reported per container
+func (re *RuleEngine) matchContainer(rule Rule, resource K8sResource, podSpec *PodSpec, container Container) (bool, string) {
+	if rule.When != nil {
+		if rule.When.layer() != containerLayer {
+			return false, ""
+		}
+		return re.evaluateWhen(rule.When, whenScope{resource: resource, podSpec: podSpec, container: &container})
+	}
+	for _, condition := range rule.parsed {
+		if matched, details := re.checkCondition(condition, container); matched {
+			return true, details
+		}
+	}
+	return false, ""
+}
+
+// evaluateResourceRule evaluates the resource-level conditions of a rule against a resource and its pod spec
+// Violations are attributed to the resource rather than to a container
+func (re *RuleEngine) evaluateResourceRule(rule Rule, resource K8sResource, podSpec *PodSpec) []Violation {
+	if rule.program != nil {
+		return re.evaluateCELRule(rule, resource)
+	}
+
+	var violations []Violation
+	for _, match := range re.matchResource(rule, resource, podSpec) {
+		message := strings.ReplaceAll(rule.Message, "{kind}", resource.Kind)
+		message = strings.ReplaceAll(message, "{name}", getResourceName(resource))
+		message = strings.ReplaceAll(message, "{details}", match.details)
+		message = strings.ReplaceAll(message, "{value}", match.value)
+
+		violations = append(violations, Violation{
+			Severity: rule.Severity,
+			Message:  message,
+			Rule:     rule.Name,
+			Help:     rule.Help,
+			Profile:  rule.Profile,
+		})
+	}
+	return violations
+}
+
+// resourceMatch is one finding of a rule on a resource; value is the field
+// value of field pattern conditions
+type resourceMatch struct {
+	details string
+	value   string
+}
+
+// matchResource returns the findings of a rule on a resource: its when
+// expression if it holds only resource conditions, otherwise the first of
+// its conditions that matches. That is one finding, except for field
+// pattern conditions, which report each matching field
+func (re *RuleEngine) matchResource(rule Rule, resource K8sResource, podSpec *PodSpec) []resourceMatch {
+	if rule.When != nil {
+		if rule.When.layer() != resourceLayer {
+			return nil
+		}
+		if matched, details := re.evaluateWhen(rule.When, whenScope{resource: resource, podSpec: podSpec}); matched {
+			return []resourceMatch{{details: details}}
+		}
+		return nil
+	}
+	for _, condition := range rule.parsed {
+		if isFieldPatternCondition(condition.Type) {
+			var matches []resourceMatch
+			for _, v := range fieldPatternCondition(condition, resource) {
+				matches = append(matches, resourceMatch{details: v.path, value: fieldValueString(v.value)})
+			}
+			if len(matches) > 0 {
+				return matches
+			}
+			continue
+		}
+		if matched, details := re.checkResourceCondition(condition, resource, podSpec); matched {
+			return []resourceMatch{{details: details}}
+		}
+	}
+	return nil
+}
+
+// checkResourceCondition evaluates a single resource-level condition
+// Container-level conditions never match here, and pod-level ones need a pod spec
+func (re *RuleEngine) checkResourceCondition(condition Condition, resource K8sResource, podSpec *PodSpec) (bool, string) {
+	conditionType, conditionValue := condition.Type, condition.Value
+
+	switch conditionType {
+	case "replicas_less_than":
+		return replicasLessThan(resource, condition.number)
+	case "api_version_removed":
+		return apiVersionRemoved(resource, re.kubeVersion)
+	case "api_version_deprecated":
+		return apiVersionDeprecated(resource, re.kubeVersion)
+	case "security_annotation_removed":
+		return securityAnnotationsIn(resource, re.kubeVersion, true, re.config.PodSpecPaths)
+	case "security_annotation_deprecated":
+		return securityAnnotationsIn(resource, re.kubeVersion, false, re.config.PodSpecPaths)
+	case "job_restart_policy_invalid":
+		return jobRestartPolicyInvalid(resource, podSpec, re.config.PodSpecPaths)
+	case "job_backoff_limit_missing":
+		return jobFieldMissing(resource, "backoffLimit")
+	case "job_active_deadline_missing":
+		return jobFieldMissing(resource, "activeDeadlineSeconds")
+	case "invalid_cron_schedule":
+		return invalidCronSchedule(resource)
+	case "cron_schedule_every_minute":
+		return cronScheduleEveryMinute(resource)
+	case "concurrency_policy_missing_or_allow":
+		return concurrencyPolicyMissingOrAllow(resource)
+	case "history_limit_missing":
+		return historyLimitMissing(resource)
+	case "starting_deadline_missing":
+		return startingDeadlineMissing(resource)
+	case "kind_in":
+		for _, kind := range condition.list {
+			if kind == resource.Kind {
+				return true, resource.APIVersion + " " + resource.Kind
+			}
+		}
+		return false, ""
+	case "metadata_name_invalid":
+		return metadataNameInvalid(resource)
+	case "label_syntax_invalid":
+		return labelsInvalid(resource, re.config.PodSpecPaths)
+	case "annotation_key_invalid":
+		return annotationKeysInvalid(resource)
+	case "annotation_size_exceeds":
+		return annotationSizeExceeds(resource, condition.quantity)
+	case "namespace_missing":
+		if re.config.IsClusterScoped(resource.Kind) {
+			return false, ""
+		}
+		namespace, _ := resource.Metadata["namespace"].(string)
+		return namespace == "", ""
+	case "namespace_equals":
+		if re.config.IsClusterScoped(resource.Kind) {
+			return false, ""
+		}
+		namespace, _ := resource.Metadata["namespace"].(string)
+		return namespace == conditionValue, namespace
+	case "rbac_wildcard_verb":
+		return rbacWildcard(resource, "verbs")
+	case "rbac_wildcard_resource":
+		return rbacWildcard(resource, "resources")
+	case "rbac_wildcard_apigroup":
+		return rbacWildcard(resource, "apiGroups")
+	case "rbac_secrets_read":
+		return rbacSecretsRead(resource)
+	case "service_type_equals":
+		if resource.Kind != "Service" {
+			return false, ""
+		}
+		actual := serviceType(resource)
+		return actual == conditionValue, actual
+	case "service_external_traffic_policy_missing":
+		return serviceExternalTrafficPolicyMissing(resource)
+	case "ingress_tls_missing":
+		return ingressTLSMissing(resource)
+	case "ingress_host_wildcard":
+		return ingressHostWildcard(resource)
+	case "ingress_default_backend_only":
+		return resource.Kind == "Ingress" && len(ingressRuleHosts(resource)) == 0, ""
+	case "ingress_class_missing":
+		return ingressClassMissing(resource)
+	case "ingress_legacy_class_annotation":
+		return ingressLegacyClassAnnotation(resource)
+	case "gateway_listener_tls_missing":
+		return gatewayListenerTLSMissing(resource)
+	case "gateway_route_parent_refs_missing":
+		return gatewayRouteParentRefsMissing(resource)
+	case "gateway_host_wildcard":
+		return gatewayHostWildcard(resource)
+	case "secret_data_credential":
+		return secretDataCredential(resource)
+	case "secret_data_invalid_base64":
+		return secretDataInvalidBase64(resource)
+	case "secret_size_exceeds":
+		return secretSizeExceeds(resource, condition.quantity)
+	case "selector_not_matching_template":
+		return selectorNotMatchingTemplate(resource, re.config.PodSpecPaths)
+	case "selector_match_expressions":
+		return selectorMatchExpressionsSet(resource)
+	case "revision_history_exceeds":
+		return revisionHistoryExceeds(resource, condition.number)
+	case "daemonset_on_delete_strategy":
+		return daemonSetOnDeleteStrategy(resource)
+	case "daemonset_max_unavailable_exceeds":
+		return daemonSetMaxUnavailableExceeds(resource, condition.number)
+	case "statefulset_service_name_missing":
+		return statefulSetServiceNameMissing(resource)
+	case "volume_claim_template_storage_class_missing":
+		// Without a default StorageClass only explicitly classed claims bind
+		if !re.config.NoDefaultStorageClass {
+			return false, ""
+		}
+		return volumeClaimTemplateStorageClassMissing(resource)
+	case "pvc_storage_request_missing":
+		return pvcStorageRequestMissing(resource)
+	case "pvc_storage_request_invalid":
+		return pvcStorageRequestInvalid(resource)
+	case "storage_class_not_in":
+		return storageClassNotIn(resource, condition.list)
+	case "hpa_replica_range_invalid":
+		return hpaReplicaRangeInvalid(resource)
+	case "hpa_min_replicas_one":
+		return hpaMinReplicasOne(resource, condition.list)
+	case "hpa_metrics_missing":
+		hpa := parseHPASpec(resource)
+		return hpa != nil && len(hpa.Metrics) == 0, ""
+	case "hpa_cpu_target_out_of_range":
+		return hpaCPUTargetOutOfRange(resource, condition.low, condition.high)
+	case "configmap_size_exceeds":
+		return configMapSizeExceeds(resource, condition.quantity)
+	case "configmap_credential":
+		return configMapCredential(resource)
+	case "configmap_invalid_key":
+		return configMapInvalidKey(resource)
+	case "tls_cert_invalid":
+		return tlsCertInvalid(resource)
+	case "tls_cert_expired":
+		return tlsCertExpiresBefore(resource, time.Now())
+	case "tls_cert_expires_within":
+		// Already expired certificates are left to tls_cert_expired
+		if expired, _ := tlsCertExpiresBefore(resource, time.Now()); expired {
+			return false, ""
+		}
+		return tlsCertExpiresBefore(resource, time.Now().Add(condition.window))
+	case "binding_cluster_admin":
+		return bindingClusterAdmin(resource, condition.list)
+	case "binding_system_subject":
+		return bindingSystemSubject(resource)
+	case "missing_label":
+		return missingLabels(resource, condition.list, re.config.PodSpecPaths)
+	case "missing_annotation":
+		return missingAnnotations(resource, condition.list)
+	case "annotation_not_matching":
+		return annotationNotMatching(resource, condition.key, condition.pattern)
+	case "field_missing":
+		return fieldMissing(resource, condition.path)
+	case "field_equals", "field_not_equals":
+		return fieldEquals(resource, condition.path, condition.operand, conditionType == "field_not_equals")
+	case "field_matches", "field_not_matches":
+		var matched []string
+		for _, v := range fieldPatternCondition(condition, resource) {
+			matched = append(matched, v.path+"="+fieldValueString(v.value))
+		}
+		return len(matched) > 0, strings.Join(matched, ", ")
+	}
+
+	if podSpec == nil {
+		return false, ""
+	}
+
+	switch conditionType {
+	case "host_network_true":
+		return podSpec.HostNetwork, "hostNetwork"
+	case "host_pid_true":
+		return podSpec.HostPID, "hostPID"
+	case "host_ipc_true":
+		return podSpec.HostIPC, "hostIPC"
+	case "host_network_without_cluster_first_dns":
+		if !podSpec.HostNetwork || podSpec.DNSPolicy == "ClusterFirstWithHostNet" {
+			return false, ""
+		}
+		if podSpec.DNSPolicy == "" {
+			return true, "dnsPolicy unset"
+		}
+		return true, "dnsPolicy: " + podSpec.DNSPolicy
+	case "dns_policy_equals":
+		// An unset dnsPolicy defaults to ClusterFirst
+		dnsPolicy := podSpec.DNSPolicy
+		if dnsPolicy == "" {
+			dnsPolicy = "ClusterFirst"
+		}
+		return dnsPolicy == conditionValue, dnsPolicy
+	case "missing_spread_constraints":
+		return missingSpreadConstraints(resource, podSpec)
+	case "share_process_namespace_true":
+		return podSpec.ShareProcessNamespace, "shareProcessNamespace"
+	case "sysctl_not_in":
+		return sysctlNotIn(podSpec.Sysctls, condition.list)
+	case "tolerates_taint":
+		for _, t := range podSpec.Tolerations {
+			// A toleration without a key tolerates every taint
+			if t.Key == conditionValue || (t.Key == "" && t.Operator == "Exists") {
+				return true, t.String()
+			}
+		}
+		return false, ""
+	case "toleration_operator_exists_all":
+		for _, t := range podSpec.Tolerations {
+			if t.Key == "" && t.Operator == "Exists" {
+				return true, t.String()
+			}
+		}
+		return false, ""
+	case "node_selector_contains":
+		if actual, ok := podSpec.NodeSelector[condition.key]; ok && (!condition.hasOperand || actual == condition.operand) {
+			return true, fmt.Sprintf("%s: %q", condition.key, actual)
+		}
+		return false, ""
+	case "priority_class_missing":
+		return podSpec.PriorityClassName == "", ""
+	case "priority_class_equals":
+		if podSpec.PriorityClassName == "" {
+			return false, ""
+		}
+		return containsString(condition.list, podSpec.PriorityClassName), podSpec.PriorityClassName
+	case "termination_grace_period_zero":
+		grace := podSpec.TerminationGracePeriodSeconds
+		return grace != nil && *grace == 0, "terminationGracePeriodSeconds: 0"
+	case "termination_grace_period_exceeds":
+		grace := podSpec.TerminationGracePeriodSeconds
+		if grace == nil || *grace <= condition.number {
+			return false, ""
+		}
+		return true, fmt.Sprintf("terminationGracePeriodSeconds: %d (above %d)", *grace, condition.number)
+	case "service_account_default":
+		return podSpec.ServiceAccountName == "" || podSpec.ServiceAccountName == "default", ""
+	case "emptydir_missing_sizelimit":
+		return emptyDirsWhere(podSpec, func(e *EmptyDirSource) bool {
+			return e.SizeLimit == ""
+		})
+	case "emptydir_memory_missing_sizelimit":
+		return emptyDirsWhere(podSpec, func(e *EmptyDirSource) bool {
+			return e.Medium == "Memory" && e.SizeLimit == ""
+		})
+	case "emptydir_sizelimit_exceeds":
+		return emptyDirSizeLimitExceeds(podSpec, condition.quantity)
+	case "duplicate_container_name":
+		return duplicateContainerName(podSpec)
+	case "conflicting_host_port":
+		return conflictingHostPort(podSpec)
+	case "volume_mount_undefined":
+		// A StatefulSet's volumeClaimTemplates also provide volumes by name
+		var templates []string
+		if resource.Kind == "StatefulSet" {
+			for _, claim := range parsePersistentVolumeClaims(resource) {
+				templates = append(templates, claim.Name)
+			}
+		}
+		return volumeMountUndefined(podSpec, templates)
+	case "volume_unused":
+		return volumeUnused(podSpec)
+	case "volume_source_in":
+		return volumesWhere(podSpec, func(v Volume) bool { return containsString(condition.list, v.Source) })
+	case "volume_source_not_in":
+		return volumesWhere(podSpec, func(v Volume) bool { return !containsString(condition.list, v.Source) })
+	default:
+		return false, ""
+	}
+}
+
+// emptyDirsWhere returns the emptyDir volumes matching the predicate
+func emptyDirsWhere(podSpec *PodSpec, match func(*EmptyDirSource) bool) (bool, string) {
+	var names []string
+	for _, v := range podSpec.Volumes {
+		if v.EmptyDir != nil && match(v.EmptyDir) {
+			names = append(names, "'"+v.Name+"'")
+		}
+	}
+	return len(names) > 0, "emptyDir " + strings.Join(names, ", ")
+}
+
+// volumesWhere returns the volumes matching the predicate, with their sources
+func volumesWhere(podSpec *PodSpec, match func(Volume) bool) (bool, string) {
+	var volumes []string
+	for _, v := range podSpec.Volumes {
+		if match(v) {
+			volumes = append(volumes, fmt.Sprintf("'%s' (%s)", v.Name, v.Source))
+		}
+	}
+	return len(volumes) > 0, strings.Join(volumes, ", ")
+}
+
+// emptyDirSizeLimitExceeds flags emptyDir volumes whose sizeLimit is above maximum
+// Unparseable limits are left to the API server
+func emptyDirSizeLimitExceeds(podSpec *PodSpec, maximum float64) (bool, string) {
+	var volumes []string
+	for _, v := range podSpec.Volumes {
+		if v.EmptyDir == nil || v.EmptyDir.SizeLimit == "" {
+			continue
+		}
+		size, err := ParseQuantity(v.EmptyDir.SizeLimit)
+		if err == nil && size > maximum {
+			volumes = append(volumes, fmt.Sprintf("'%s' (sizeLimit %s)", v.Name, v.EmptyDir.SizeLimit))
+		}
+	}
+	return len(volumes) > 0, "emptyDir " + strings.Join(volumes, ", ")
+}
+
 // checkCondition evaluates a single condition
-func (re *RuleEngine) checkCondition(condition string, container Container) bool {
-	parts := strings.Split(condition, ":")
-	conditionType := parts[0]
-	var conditionValue string
-	if len(parts) > 1 {
-		conditionValue = parts[1]
+// It also returns details about the match for the {details} message placeholder
+func (re *RuleEngine) checkCondition(condition Condition, container Container) (bool, string) {
+	conditionType, conditionValue := condition.Type, condition.Value
+
+	// An ImageChange trigger sets the image from an ImageStreamTag on deploy, so
+	// tag conditions check the tag it follows, and the conditions about the
+	// final reference are left to the digest-pinned image OpenShift resolves
+	if container.ImageStreamTag != "" {
+		switch conditionType {
+		case "image_tag_equals", "image_tag_missing", "image_tag_not_matching":
+			container.Image = container.ImageStreamTag
+		case "image_digest_missing", "image_digest_present", "image_not_fully_qualified",
+			"image_registry_not_in", "private_image_without_pull_secret":
+			return false, ""
+		}
 	}
 
 	switch conditionType {
 	case "image_tag_equals":
-		return imageTagEquals(container.Image, conditionValue)
+		return imageTagEquals(container.Image, conditionValue), ""
 	case "image_tag_missing":
-		return imageTagMissing(container.Image)
+		return imageTagMissing(container.Image), ""
+	case "image_digest_missing":
+		return imageDigestMissing(container.Image), ""
+	case "image_digest_present":
+		return !imageDigestMissing(container.Image), parseImageReference(container.Image).Digest
+	case "image_not_fully_qualified":
+		return imageNotFullyQualified(container.Image)
+	case "image_tag_not_matching":
+		return imageTagNotMatching(container.Image, condition.pattern)
+	case "container_name_not_matching":
+		return !condition.pattern.MatchString(container.Name), container.Name
+	case "container_name_in":
+		return containsString(condition.list, container.Name), container.Name
+	case "image_registry_not_in":
+		return imageRegistryNotIn(container.Image, condition.list)
+	case "private_image_without_pull_secret":
+		if len(container.ImagePullSecrets) > 0 {
+			return false, ""
+		}
+		registry, ok := imagePrivateRegistry(container.Image, condition.list)
+		if !ok {
+			return false, ""
+		}
+		// The ServiceAccount's own imagePullSecrets are invisible from the manifest
+		if container.ServiceAccountName != "" {
+			return true, fmt.Sprintf("%s (unless ServiceAccount '%s' provides imagePullSecrets)", registry, container.ServiceAccountName)
+		}
+		return true, registry
+	case "cpu_request_exceeds_limit":
+		return requestExceedsLimit(container, "cpu")
+	case "memory_request_exceeds_limit":
+		return requestExceedsLimit(container, "memory")
+	case "invalid_quantity":
+		return invalidQuantity(container)
+	case "cpu_limit_set":
+		if missingCPULimits(container) {
+			return false, ""
+		}
+		return true, container.Resources.Limits.CPU
+	case "cpu_limit_exceeds":
+		return cpuLimitExceeds(container, condition.quantity)
+	case "memory_limit_ratio_exceeds":
+		return memoryLimitRatioExceeds(container, condition.factor)
 	case "missing_cpu_requests":
-		return missingCPURequests(container)
+		return missingCPURequests(container), ""
 	case "missing_memory_requests":
-		return missingMemoryRequests(container)
+		return missingMemoryRequests(container), ""
 	case "missing_cpu_limits":
-		return missingCPULimits(container)
+		return missingCPULimits(container), ""
 	case "missing_memory_limits":
-		return missingMemoryLimits(container)
+		return missingMemoryLimits(container), ""
 	case "missing_security_context":
-		return missingSecurityContext(container)
+		return missingSecurityContext(container), ""
 	case "run_as_non_root_false":
-		return runAsNonRootFalse(container)
+		return runAsNonRootFalse(container), ""
 	case "run_as_user_zero":
-		return runAsUserZero(container)
+		return runAsUserZero(container), ""
+	case "run_as_non_root_not_true":
+		runAsNonRoot := container.effectiveRunAsNonRoot()
+		return runAsNonRoot == nil || !*runAsNonRoot, ""
+	case "allow_privilege_escalation_not_false":
+		return allowPrivilegeEscalationNotFalse(container), ""
+	case "seccomp_profile_equals":
+		profile := container.effectiveSeccompProfile()
+		return profile == conditionValue, profile
+	case "seccomp_profile_not_in":
+		return profileTypeNotIn(container.effectiveSeccompProfile(), condition.list)
+	case "apparmor_profile_equals":
+		profile := container.effectiveAppArmorProfile()
+		return profile == conditionValue, profile
+	case "selinux_options_unsafe":
+		return seLinuxOptionsUnsafe(container)
+	case "host_process_true":
+		return hostProcessTrue(container), ""
 	case "missing_liveness_probe":
-		return missingLivenessProbe(container)
+		return missingLivenessProbe(container), ""
 	case "missing_readiness_probe":
-		return missingReadinessProbe(container)
+		return missingReadinessProbe(container), ""
+	case "missing_startup_probe_with_high_initial_delay":
+		return missingStartupProbeWithHighInitialDelay(container, condition.number)
+	case "liveness_equals_readiness":
+		return livenessEqualsReadiness(container), ""
+	case "plaintext_secret_env":
+		return plaintextSecretEnv(container, condition.pattern)
+	case "secret_env_exposure":
+		return secretEnvExposure(container)
 	case "privileged_true":
-		return privilegedTrue(container)
-	case "missing_image_pull_policy":
-		return missingImagePullPolicy(container)
+		return privilegedTrue(container), ""
+	case "missing_image_pull_policy", "image_pull_policy_missing":
+		return missingImagePullPolicy(container), ""
+	case "image_pull_policy_equals":
+		return container.ImagePullPolicy == conditionValue, container.ImagePullPolicy
+	case "image_pull_policy_always_with_digest":
+		return imagePullPolicyAlwaysWithDigest(container), ""
+	case "image_pull_policy_stale_latest":
+		return imagePullPolicyStaleLatest(container), pullPolicyLabel(container.ImagePullPolicy)
+	case "ephemeral_container":
+		return isEphemeralContainer(container), ""
+	case "capabilities_not_dropped_all":
+		return capabilitiesNotDroppedAll(container), ""
+	case "capabilities_added":
+		return capabilitiesAdded(container, condition.list)
+	case "capabilities_added_not_in":
+		return capabilitiesAddedNotIn(container, condition.list)
+	case "host_port_set":
+		return hostPortBelow(container, 65536)
+	case "host_port_below":
+		return hostPortBelow(container, condition.number)
+	case "proc_mount_unmasked":
+		return container.SecurityContext != nil && container.SecurityContext.ProcMount == "Unmasked", ""
+	case "mount_not_readonly":
+		return mountNotReadOnly(container, condition.list)
+	case "secret_volume_not_readonly":
+		return secretVolumeNotReadOnly(container)
+	case "missing_prestop_hook":
+		return len(container.Ports) > 0 && container.PreStop == "", ""
+	case "port_name_missing":
+		return portNameMissing(container)
+	case "port_below":
+		return containerPortBelow(container, condition.number)
+	case "duplicate_container_port":
+		return duplicateContainerPort(container)
+	case "read_only_root_filesystem_not_true":
+		return readOnlyRootFilesystemNotTrue(container), ""
 	default:
-		return false
+		return false, ""
+	}
+}
+
+// PodSpec represents the pod-level settings of a workload
+type PodSpec struct {
+	HostNetwork bool
+	HostPID     bool
+	HostIPC     bool
+	// ShareProcessNamespace lets containers see and signal each other's processes
+	ShareProcessNamespace bool
+	ServiceAccountName    string
+	RestartPolicy         string
+	DNSPolicy             string
+	PriorityClassName     string
+	// AntiAffinityTopologyKeys lists the topologyKey of every podAntiAffinity term, required or preferred
+	AntiAffinityTopologyKeys []string
+	// TopologySpreadKeys lists the topologyKey of every topologySpreadConstraints entry
+	TopologySpreadKeys []string
+	NodeSelector       map[string]string
+	Tolerations        []Toleration
+	// Sysctls lists the names from securityContext.sysctls
+	Sysctls []string
+	// TerminationGracePeriodSeconds is nil when unset (the API default is 30)
+	TerminationGracePeriodSeconds *int
+	SecurityContext               *SecurityContext
+	Volumes                       []Volume
+	// ImagePullSecrets lists the secret names from imagePullSecrets
+	ImagePullSecrets []string
+	// Containers holds every container of the pod, of all origins, for
+	// conditions that compare containers with each other
+	Containers []Container
+}
+
+// Volume represents an entry of a pod's volumes list
+type Volume struct {
+	Name string
+	// Source is the volume source field, such as secret, configMap, or emptyDir
+	Source string
+	// ProjectedSources lists the source kinds of a projected volume
+	ProjectedSources []string
+	// EmptyDir is set for emptyDir volumes
+	EmptyDir *EmptyDirSource
+	// ClaimName is the PersistentVolumeClaim of a persistentVolumeClaim volume
+	ClaimName string
+	// References lists the ConfigMaps and Secrets the volume reads, directly
+	// or through projected sources
+	References []ConfigReference
+}
+
+// EmptyDirSource represents the settings of an emptyDir volume
+type EmptyDirSource struct {
+	Medium    string // "" (node disk) or Memory
+	SizeLimit string
+}
+
+// exposesConfigData reports whether the volume serves Secret or ConfigMap data
+func (v Volume) exposesConfigData() bool {
+	switch v.Source {
+	case "secret", "configMap":
+		return true
+	case "projected":
+		return containsString(v.ProjectedSources, "secret") || containsString(v.ProjectedSources, "configMap")
+	}
+	return false
+}
+
+// Toleration represents an entry of a pod's tolerations list
+type Toleration struct {
+	Key      string
+	Operator string // Equal (the default) or Exists
+	Value    string
+	Effect   string
+}
+
+// String renders the toleration the way it would appear in a manifest
+func (t Toleration) String() string {
+	var fields []string
+	if t.Key != "" {
+		fields = append(fields, "key: "+t.Key)
+	}
+	if t.Operator != "" {
+		fields = append(fields, "operator: "+t.Operator)
+	}
+	if t.Value != "" {
+		fields = append(fields, "value: "+t.Value)
+	}
+	if t.Effect != "" {
+		fields = append(fields, "effect: "+t.Effect)
+	}
+	return "{" + strings.Join(fields, ", ") + "}"
+}
+
+// Container origins, i.e. which pod spec list a container was declared in
+const (
+	OriginContainer          = "container"
+	OriginInitContainer      = "initContainer"
+	OriginEphemeralContainer = "ephemeralContainer"
+)
+
+// originLabel returns the message label for a container origin
+func originLabel(origin string) string {
+	if origin == OriginContainer {
+		return "Container"
 	}
+	return origin
 }
 
 // Container represents a Kubernetes container spec
 type Container struct {
 	Name            string
+	Origin          string
 	Image           string
 	Resources       *Resources
 	SecurityContext *SecurityContext
-	LivenessProbe   bool
-	ReadinessProbe  bool
-	ImagePullPolicy string
+	// PodSecurityContext is the pod-level securityContext, which fills in
+	// any field the container leaves unset
+	PodSecurityContext *SecurityContext
+	LivenessProbe      *Probe
+	ReadinessProbe     *Probe
+	StartupProbe       *Probe
+	ImagePullPolicy    string
+	Ports              []ContainerPort
+	Env                []EnvVar
+	EnvFrom            []EnvFromSource
+	VolumeMounts       []VolumeMount
+	// VolumeDevices lists the volumes the container uses as raw block devices
+	VolumeDevices []string
+	// Volumes are the pod's volumes, which VolumeMounts refer to by name
+	Volumes []Volume
+	// ImagePullSecrets and ServiceAccountName are copied from the pod spec,
+	// since both decide whether the image can be pulled
+	ImagePullSecrets   []string
+	ServiceAccountName string
+	// PreStop is the lifecycle.preStop handler type (exec, httpGet,
+	// tcpSocket, or sleep), empty when no preStop hook is set
+	PreStop string
+	// ImageStreamTag is the name:tag an OpenShift ImageChange trigger sets
+	// the image from, empty for containers without one
+	ImageStreamTag string
+}
+
+// EnvVar represents an entry of a container's env list
+type EnvVar struct {
+	Name      string
+	Value     string
+	ValueFrom *EnvVarSource // nil for literal values
+}
+
+// EnvVarSource represents an env valueFrom reference
+type EnvVarSource struct {
+	Type     string // secretKeyRef, configMapKeyRef, fieldRef, or resourceFieldRef
+	Name     string // referenced object, for secretKeyRef and configMapKeyRef
+	Key      string
+	Optional bool
+}
+
+// EnvFromSource represents an entry of a container's envFrom list
+type EnvFromSource struct {
+	Type     string // secretRef or configMapRef
+	Name     string
+	Prefix   string
+	Optional bool
+}
+
+// withoutEnv returns a copy of the container without the named env vars
+func (c Container) withoutEnv(names []string) Container {
+	var env []EnvVar
+	for _, e := range c.Env {
+		allowed := false
+		for _, name := range names {
+			if e.Name == name {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			env = append(env, e)
+		}
+	}
+	c.Env = env
+	return c
+}
+
+// Probe represents a liveness, readiness, or startup probe
+// Timing fields are 0 when unset
+type Probe struct {
+	InitialDelaySeconds int
+	PeriodSeconds       int
+	TimeoutSeconds      int
+	FailureThreshold    int
+	// Handler holds the probe action (httpGet, tcpSocket, exec, or grpc)
+	Handler map[string]interface{}
+}
+
+// probeHandlerKeys lists the probe fields that define what the probe does
+var probeHandlerKeys = []string{"httpGet", "tcpSocket", "exec", "grpc"}
+
+// lifecycleHandlerKeys lists the lifecycle hook fields that define what the hook does
+var lifecycleHandlerKeys = []string{"exec", "httpGet", "sleep", "tcpSocket"}
+
+// VolumeMount represents an entry of a container's volumeMounts list
+type VolumeMount struct {
+	Name      string
+	MountPath string
+	ReadOnly  bool
+}
+
+// ContainerPort represents an entry of a container's ports list
+type ContainerPort struct {
+	Name          string
+	ContainerPort int
+	HostPort      int
+	Protocol      string
 }
 
 // Resources represents resource requirements
@@ -125,19 +919,161 @@ type SecurityContext struct {
 	RunAsNonRoot *bool
 	RunAsUser    *int
 	Privileged   *bool
+	Capabilities *Capabilities
+
+	ReadOnlyRootFilesystem *bool
+	ProcMount              string // Default or Unmasked
+
+	AllowPrivilegeEscalation *bool
+	SeccompProfile           string // seccompProfile.type: RuntimeDefault, Localhost, or Unconfined
+	AppArmorProfile          string // appArmorProfile.type: RuntimeDefault, Localhost, or Unconfined
+	SELinuxOptions           *SELinuxOptions
+	// HostProcess is windowsOptions.hostProcess
+	HostProcess *bool
+}
+
+// SELinuxOptions represents the seLinuxOptions of a securityContext
+type SELinuxOptions struct {
+	User  string
+	Role  string
+	Type  string
+	Level string
+}
+
+// Capabilities represents added and dropped Linux capabilities
+type Capabilities struct {
+	Add  []string
+	Drop []string
 }
 
 // Condition evaluation functions
 func imageTagEquals(image, tag string) bool {
-	if !strings.Contains(image, ":") {
+	ref := parseImageReference(image)
+	if ref.Tag == "" && ref.Digest == "" {
 		return tag == "latest" // No tag means implicit :latest
 	}
-	parts := strings.Split(image, ":")
-	return len(parts) == 2 && parts[1] == tag
+	return ref.Tag == tag
 }
 
+// imageTagMissing reports images with neither a tag nor a digest
+// A digest pins the image more strictly than any tag
 func imageTagMissing(image string) bool {
-	return !strings.Contains(image, ":")
+	ref := parseImageReference(image)
+	return ref.Tag == "" && ref.Digest == ""
+}
+
+// imageTagNotMatching flags image tags that do not match a pattern
+// Digest-pinned images always pass, and a missing tag is checked as "latest"
+func imageTagNotMatching(image string, pattern *regexp.Regexp) (bool, string) {
+	ref := parseImageReference(image)
+	if ref.Digest != "" {
+		return false, ""
+	}
+	tag := ref.Tag
+	if tag == "" {
+		tag = "latest"
+	}
+	return !pattern.MatchString(tag), tag
+}
+
+func imageDigestMissing(image string) bool {
+	return parseImageReference(image).Digest == ""
+}
+
+// quantity returns the CPU or memory quantity of a resource spec
+func (r *ResourceSpec) quantity(resource string) string {
+	if r == nil {
+		return ""
+	}
+	if resource == "cpu" {
+		return r.CPU
+	}
+	return r.Memory
+}
+
+// requestExceedsLimit flags a CPU or memory request larger than its limit
+// Unparseable values are left to invalid_quantity
+func requestExceedsLimit(c Container, resource string) (bool, string) {
+	if c.Resources == nil {
+		return false, ""
+	}
+	request := c.Resources.Requests.quantity(resource)
+	limit := c.Resources.Limits.quantity(resource)
+	if request == "" || limit == "" {
+		return false, ""
+	}
+
+	requestValue, err := ParseQuantity(request)
+	if err != nil {
+		return false, ""
+	}
+	limitValue, err := ParseQuantity(limit)
+	if err != nil {
+		return false, ""
+	}
+
+	return requestValue > limitValue, fmt.Sprintf("%s request %s > limit %s", resource, request, limit)
+}
+
+// cpuLimitExceeds flags a CPU limit above the given number of cores
+func cpuLimitExceeds(c Container, maximum float64) (bool, string) {
+	if missingCPULimits(c) {
+		return false, ""
+	}
+	limit, err := ParseQuantity(c.Resources.Limits.CPU)
+	if err != nil {
+		return false, ""
+	}
+	return limit > maximum, c.Resources.Limits.CPU
+}
+
+// memoryLimitRatioExceeds flags a memory limit more than factor times the request
+// Missing or unparseable values are left to the missing_* and invalid_quantity conditions
+func memoryLimitRatioExceeds(c Container, factor float64) (bool, string) {
+	if c.Resources == nil {
+		return false, ""
+	}
+	request := c.Resources.Requests.quantity("memory")
+	limit := c.Resources.Limits.quantity("memory")
+	if request == "" || limit == "" {
+		return false, ""
+	}
+
+	requestValue, err := ParseQuantity(request)
+	if err != nil || requestValue <= 0 {
+		return false, ""
+	}
+	limitValue, err := ParseQuantity(limit)
+	if err != nil {
+		return false, ""
+	}
+
+	ratio := limitValue / requestValue
+	return ratio > factor, fmt.Sprintf("limit %s is %.1fx request %s", limit, ratio, request)
+}
+
+// invalidQuantity flags CPU and memory requests or limits that are not valid quantities
+func invalidQuantity(c Container) (bool, string) {
+	if c.Resources == nil {
+		return false, ""
+	}
+
+	var invalid []string
+	for _, entry := range []struct {
+		field string
+		spec  *ResourceSpec
+	}{{"requests", c.Resources.Requests}, {"limits", c.Resources.Limits}} {
+		for _, resource := range []string{"cpu", "memory"} {
+			value := entry.spec.quantity(resource)
+			if value == "" {
+				continue
+			}
+			if parsed, err := ParseQuantity(value); err != nil || parsed < 0 {
+				invalid = append(invalid, fmt.Sprintf("%s.%s %q", entry.field, resource, value))
+			}
+		}
+	}
+	return len(invalid) > 0, strings.Join(invalid, ", ")
 }
 
 func missingCPURequests(c Container) bool {
@@ -157,120 +1093,1046 @@ func missingMemoryLimits(c Container) bool {
 }
 
 func missingSecurityContext(c Container) bool {
-	return c.SecurityContext == nil
+	return c.SecurityContext == nil && c.PodSecurityContext == nil
 }
 
 func runAsNonRootFalse(c Container) bool {
-	return c.SecurityContext != nil && c.SecurityContext.RunAsNonRoot != nil && !*c.SecurityContext.RunAsNonRoot
+	runAsNonRoot := c.effectiveRunAsNonRoot()
+	return runAsNonRoot != nil && !*runAsNonRoot
 }
 
 func runAsUserZero(c Container) bool {
-	return c.SecurityContext != nil && c.SecurityContext.RunAsUser != nil && *c.SecurityContext.RunAsUser == 0
+	runAsUser := c.effectiveRunAsUser()
+	return runAsUser != nil && *runAsUser == 0
 }
 
-func missingLivenessProbe(c Container) bool {
-	return !c.LivenessProbe
+// effectiveRunAsNonRoot returns runAsNonRoot from the container, falling back to the pod
+func (c Container) effectiveRunAsNonRoot() *bool {
+	if c.SecurityContext != nil && c.SecurityContext.RunAsNonRoot != nil {
+		return c.SecurityContext.RunAsNonRoot
+	}
+	if c.PodSecurityContext != nil {
+		return c.PodSecurityContext.RunAsNonRoot
+	}
+	return nil
 }
 
-func missingReadinessProbe(c Container) bool {
-	return !c.ReadinessProbe
+// effectiveSeccompProfile returns the seccomp profile type from the container,
+// falling back to the pod; "" means unset, which most kubelets run as Unconfined
+func (c Container) effectiveSeccompProfile() string {
+	if c.SecurityContext != nil && c.SecurityContext.SeccompProfile != "" {
+		return c.SecurityContext.SeccompProfile
+	}
+	if c.PodSecurityContext != nil {
+		return c.PodSecurityContext.SeccompProfile
+	}
+	return ""
 }
 
-func privilegedTrue(c Container) bool {
-	return c.SecurityContext != nil && c.SecurityContext.Privileged != nil && *c.SecurityContext.Privileged
+// effectiveAppArmorProfile returns the AppArmor profile type from the container, falling back to the pod
+func (c Container) effectiveAppArmorProfile() string {
+	if c.SecurityContext != nil && c.SecurityContext.AppArmorProfile != "" {
+		return c.SecurityContext.AppArmorProfile
+	}
+	if c.PodSecurityContext != nil {
+		return c.PodSecurityContext.AppArmorProfile
+	}
+	return ""
 }
 
-func missingImagePullPolicy(c Container) bool {
-	return c.ImagePullPolicy == ""
+// allowPrivilegeEscalationNotFalse flags containers that do not set
+// allowPrivilegeEscalation: false, which defaults to true
+func allowPrivilegeEscalationNotFalse(c Container) bool {
+	return c.SecurityContext == nil || c.SecurityContext.AllowPrivilegeEscalation == nil || *c.SecurityContext.AllowPrivilegeEscalation
 }
 
-// extractContainersFromResource extracts containers from a K8s resource
-func extractContainersFromResource(resource K8sResource) []Container {
-	var containers []Container
-
-	// Navigate through the spec to find containers
-	if resource.Spec == nil {
-		return containers
-	}
-
-	// Try to find containers in spec.template.spec.containers (Deployment, StatefulSet, etc.)
-	if template, ok := resource.Spec["template"].(map[string]interface{}); ok {
-		if spec, ok := template["spec"].(map[string]interface{}); ok {
-			if containerList, ok := spec["containers"].([]interface{}); ok {
-				containers = parseContainers(containerList)
-				return containers
-			}
-		}
+// profileTypeNotIn flags a seccomp or AppArmor profile type outside a list;
+// an unset profile never matches the list
+func profileTypeNotIn(profile string, allowed []string) (bool, string) {
+	if containsString(allowed, profile) {
+		return false, ""
 	}
-
-	// Try to find containers directly in spec.containers (Pod)
-	if containerList, ok := resource.Spec["containers"].([]interface{}); ok {
-		containers = parseContainers(containerList)
-		return containers
+	if profile == "" {
+		return true, "unset"
 	}
-
-	return containers
+	return true, profile
 }
 
-// parseContainers converts interface{} to Container structs
-func parseContainers(containerList []interface{}) []Container {
-	var containers []Container
+// seLinuxTypes are the SELinux types containers may set under the baseline Pod Security Standard
+var seLinuxTypes = []string{"", "container_t", "container_init_t", "container_kvm_t", "container_engine_t"}
 
-	for _, c := range containerList {
-		containerMap, ok := c.(map[string]interface{})
-		if !ok {
+// seLinuxOptionsUnsafe flags container or pod seLinuxOptions that set a user
+// or role, or a type outside seLinuxTypes
+func seLinuxOptionsUnsafe(c Container) (bool, string) {
+	for _, sc := range []*SecurityContext{c.SecurityContext, c.PodSecurityContext} {
+		if sc == nil || sc.SELinuxOptions == nil {
 			continue
 		}
-
-		container := Container{
-			Name:  getStringValue(containerMap, "name"),
-			Image: getStringValue(containerMap, "image"),
+		options := sc.SELinuxOptions
+		switch {
+		case options.User != "":
+			return true, "user " + options.User
+		case options.Role != "":
+			return true, "role " + options.Role
+		case !containsString(seLinuxTypes, options.Type):
+			return true, "type " + options.Type
 		}
+	}
+	return false, ""
+}
 
-		// Parse resources
-		if resourcesMap, ok := containerMap["resources"].(map[string]interface{}); ok {
-			container.Resources = parseResources(resourcesMap)
+// hostProcessTrue flags Windows HostProcess containers, set on the container or the pod
+func hostProcessTrue(c Container) bool {
+	for _, sc := range []*SecurityContext{c.SecurityContext, c.PodSecurityContext} {
+		if sc != nil && sc.HostProcess != nil && *sc.HostProcess {
+			return true
 		}
+	}
+	return false
+}
 
-		// Parse security context
-		if securityMap, ok := containerMap["securityContext"].(map[string]interface{}); ok {
-			container.SecurityContext = parseSecurityContext(securityMap)
-		}
+// effectiveRunAsUser returns runAsUser from the container, falling back to the pod
+func (c Container) effectiveRunAsUser() *int {
+	if c.SecurityContext != nil && c.SecurityContext.RunAsUser != nil {
+		return c.SecurityContext.RunAsUser
+	}
+	if c.PodSecurityContext != nil {
+		return c.PodSecurityContext.RunAsUser
+	}
+	return nil
+}
 
-		// Parse liveness probe
-		if _, ok := containerMap["livenessProbe"]; ok {
-			container.LivenessProbe = true
-		}
+func missingLivenessProbe(c Container) bool {
+	return c.LivenessProbe == nil
+}
 
-		// Parse readiness probe
-		if _, ok := containerMap["readinessProbe"]; ok {
-			container.ReadinessProbe = true
-		}
+func missingReadinessProbe(c Container) bool {
+	return c.ReadinessProbe == nil
+}
 
-		// Parse image pull policy
-		container.ImagePullPolicy = getStringValue(containerMap, "imagePullPolicy")
+// missingStartupProbeWithHighInitialDelay flags liveness probes that wait
+// longer than the threshold instead of relying on a startupProbe
+func missingStartupProbeWithHighInitialDelay(c Container, threshold int) (bool, string) {
+	if c.LivenessProbe == nil || c.StartupProbe != nil {
+		return false, ""
+	}
+	delay := c.LivenessProbe.InitialDelaySeconds
+	return delay > threshold, strconv.Itoa(delay)
+}
 
-		containers = append(containers, container)
+// livenessEqualsReadiness flags containers whose liveness and readiness probes
+// run the same action; timing fields are ignored since they are tuned separately
+func livenessEqualsReadiness(c Container) bool {
+	if c.LivenessProbe == nil || c.ReadinessProbe == nil || len(c.LivenessProbe.Handler) == 0 {
+		return false
 	}
+	return reflect.DeepEqual(c.LivenessProbe.Handler, c.ReadinessProbe.Handler)
+}
 
-	return containers
+// replicatedKinds are the kinds whose spec.replicas defaults to 1 when absent
+var replicatedKinds = map[string]bool{
+	"Deployment":            true,
+	"StatefulSet":           true,
+	"ReplicaSet":            true,
+	"ReplicationController": true,
+	"DeploymentConfig":      true,
 }
 
-// parseResources parses resource requirements
-func parseResources(resourcesMap map[string]interface{}) *Resources {
+// workloadReplicas returns spec.replicas of a replicated workload, counting an absent field as 1
+// It returns false for kinds without replicas
+func workloadReplicas(resource K8sResource) (int, bool) {
+	if !replicatedKinds[resource.Kind] {
+		return 0, false
+	}
+	replicas, ok := getIntValue(resource.Spec, "replicas")
+	if !ok {
+		replicas = 1
+	}
+	return replicas, true
+}
+
+// replicasLessThan flags replicated workloads running fewer than threshold replicas
+func replicasLessThan(resource K8sResource, threshold int) (bool, string) {
+	replicas, ok := workloadReplicas(resource)
+	if !ok {
+		return false, ""
+	}
+	return replicas < threshold, strconv.Itoa(replicas)
+}
+
+// missingSpreadConstraints flags replicated workloads with 2+ replicas that neither
+// spread with topologySpreadConstraints nor use podAntiAffinity on the node hostname
+func missingSpreadConstraints(resource K8sResource, podSpec *PodSpec) (bool, string) {
+	replicas, ok := workloadReplicas(resource)
+	if !ok {
+		return false, ""
+	}
+	if replicas < 2 {
+		return false, ""
+	}
+
+	if len(podSpec.TopologySpreadKeys) > 0 || containsString(podSpec.AntiAffinityTopologyKeys, "kubernetes.io/hostname") {
+		return false, ""
+	}
+	return true, strconv.Itoa(replicas)
+}
+
+// safeSysctls is the namespaced sysctl set Kubernetes allows without kubelet allowlisting
+const safeSysctls = "kernel.shm_rmid_forced,net.ipv4.ip_local_port_range,net.ipv4.tcp_syncookies,net.ipv4.ping_group_range"
+
+// sysctlNotIn flags sysctls outside an allowlist
+func sysctlNotIn(sysctls []string, allowed []string) (bool, string) {
+	var forbidden []string
+	for _, name := range sysctls {
+		if !containsString(allowed, name) {
+			forbidden = append(forbidden, name)
+		}
+	}
+	return len(forbidden) > 0, strings.Join(forbidden, ", ")
+}
+
+// jobSpecPaths maps the kinds that run Jobs to the path of their Job spec
+var jobSpecPaths = map[string]string{
+	"Job":     "spec",
+	"CronJob": "spec.jobTemplate.spec",
+}
+
+// jobRestartPolicyInvalid flags Job pod templates whose restartPolicy is not Never or OnFailure
+// An absent restartPolicy defaults to Always, which the API rejects for Jobs
+func jobRestartPolicyInvalid(resource K8sResource, podSpec *PodSpec, specPaths map[string]string) (bool, string) {
+	path, ok := podSpecPath(resource, specPaths)
+	if _, isJob := jobSpecPaths[resource.Kind]; !isJob || !ok || podSpec == nil {
+		return false, ""
+	}
+
+	switch podSpec.RestartPolicy {
+	case "Never", "OnFailure":
+		return false, ""
+	case "":
+		return true, path + ".restartPolicy (unset, defaults to Always)"
+	default:
+		return true, fmt.Sprintf("%s.restartPolicy: %s", path, podSpec.RestartPolicy)
+	}
+}
+
+// jobFieldMissing flags Jobs and CronJobs whose Job spec does not set a field
+// Details are the full field path
+func jobFieldMissing(resource K8sResource, field string) (bool, string) {
+	path, ok := jobSpecPaths[resource.Kind]
+	if !ok {
+		return false, ""
+	}
+	jobSpec := lookupSpecPath(resource, path)
+	if jobSpec == nil {
+		return false, ""
+	}
+	if _, ok := getIntValue(jobSpec, field); ok {
+		return false, ""
+	}
+	return true, path + "." + field
+}
+
+// missingLabels flags required label keys absent from metadata.labels, or for
+// workloads from the pod template labels that Services select on
+func missingLabels(resource K8sResource, keys []string, specPaths map[string]string) (bool, string) {
+	labels := getStringMap(resource.Metadata, "labels")
+	var templateLabels map[string]string
+	path, _ := podSpecPath(resource, specPaths)
+	checkTemplate := path != "" && path != "spec"
+	if checkTemplate {
+		templateLabels = podTemplateLabels(resource, specPaths)
+	}
+
+	var missing []string
+	for _, key := range keys {
+		if _, ok := labels[key]; !ok {
+			missing = append(missing, key)
+		} else if _, ok := templateLabels[key]; checkTemplate && !ok {
+			missing = append(missing, key+" (pod template)")
+		}
+	}
+	return len(missing) > 0, strings.Join(missing, ", ")
+}
+
+// missingAnnotations flags required annotation keys absent from metadata.annotations
+func missingAnnotations(resource K8sResource, keys []string) (bool, string) {
+	annotations := getStringMap(resource.Metadata, "annotations")
+
+	var missing []string
+	for _, key := range keys {
+		if _, ok := annotations[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	return len(missing) > 0, strings.Join(missing, ", ")
+}
+
+// annotationNotMatching flags an annotation whose value does not match a pattern
+// An absent annotation is left to missing_annotation
+func annotationNotMatching(resource K8sResource, key string, pattern *regexp.Regexp) (bool, string) {
+	value, ok := getStringMap(resource.Metadata, "annotations")[key]
+	if !ok || pattern.MatchString(value) {
+		return false, ""
+	}
+	return true, fmt.Sprintf("%s=%q", key, value)
+}
+
+// plaintextSecretEnv flags literal env values whose name or content looks like a credential
+// Details name the variables and why they matched, never the values
+func plaintextSecretEnv(c Container, namePattern *regexp.Regexp) (bool, string) {
+	var findings []string
+	for _, env := range c.Env {
+		if env.ValueFrom != nil || env.Value == "" {
+			continue
+		}
+		if family := detectCredential(env.Value); family != "" {
+			findings = append(findings, fmt.Sprintf("%s (%s)", env.Name, family))
+		} else if namePattern.MatchString(env.Name) {
+			findings = append(findings, env.Name)
+		}
+	}
+	return len(findings) > 0, strings.Join(findings, ", ")
+}
+
+// secretEnvExposure flags Secrets injected through env or envFrom rather than a volume
+func secretEnvExposure(c Container) (bool, string) {
+	var exposures []string
+	for _, env := range c.Env {
+		if env.ValueFrom != nil && env.ValueFrom.Type == "secretKeyRef" {
+			exposures = append(exposures, fmt.Sprintf("%s from secret '%s' key '%s'", env.Name, env.ValueFrom.Name, env.ValueFrom.Key))
+		}
+	}
+	for _, source := range c.EnvFrom {
+		if source.Type == "secretRef" {
+			exposures = append(exposures, fmt.Sprintf("all keys of secret '%s'", source.Name))
+		}
+	}
+	return len(exposures) > 0, strings.Join(exposures, ", ")
+}
+
+func privilegedTrue(c Container) bool {
+	return c.SecurityContext != nil && c.SecurityContext.Privileged != nil && *c.SecurityContext.Privileged
+}
+
+func capabilitiesNotDroppedAll(c Container) bool {
+	if c.SecurityContext == nil || c.SecurityContext.Capabilities == nil {
+		return true
+	}
+	for _, capability := range c.SecurityContext.Capabilities.Drop {
+		if normalizeCapability(capability) == "ALL" {
+			return false
+		}
+	}
+	return true
+}
+
+func readOnlyRootFilesystemNotTrue(c Container) bool {
+	return c.SecurityContext == nil || c.SecurityContext.ReadOnlyRootFilesystem == nil || !*c.SecurityContext.ReadOnlyRootFilesystem
+}
+
+// capabilitiesAdded matches added capabilities against a list and returns
+// every capability that matched
+func capabilitiesAdded(c Container, capabilities []string) (bool, string) {
+	if c.SecurityContext == nil || c.SecurityContext.Capabilities == nil {
+		return false, ""
+	}
+
+	forbidden := make(map[string]bool)
+	for _, capability := range capabilities {
+		forbidden[normalizeCapability(capability)] = true
+	}
+
+	var matched []string
+	for _, capability := range c.SecurityContext.Capabilities.Add {
+		if forbidden[normalizeCapability(capability)] {
+			matched = append(matched, normalizeCapability(capability))
+		}
+	}
+
+	return len(matched) > 0, strings.Join(matched, ", ")
+}
+
+// capabilitiesAddedNotIn flags added capabilities outside an allowlist and
+// returns every capability that is not allowed
+func capabilitiesAddedNotIn(c Container, capabilities []string) (bool, string) {
+	if c.SecurityContext == nil || c.SecurityContext.Capabilities == nil {
+		return false, ""
+	}
+
+	allowed := make(map[string]bool)
+	for _, capability := range capabilities {
+		allowed[normalizeCapability(capability)] = true
+	}
+
+	var matched []string
+	for _, capability := range c.SecurityContext.Capabilities.Add {
+		if !allowed[normalizeCapability(capability)] {
+			matched = append(matched, normalizeCapability(capability))
+		}
+	}
+
+	return len(matched) > 0, strings.Join(matched, ", ")
+}
+
+// normalizeCapability upper-cases a capability name and strips the CAP_ prefix
+func normalizeCapability(capability string) string {
+	capability = strings.ToUpper(strings.TrimSpace(capability))
+	return strings.TrimPrefix(capability, "CAP_")
+}
+
+// hostPortBelow returns the host ports the container binds below the threshold
+func hostPortBelow(c Container, threshold int) (bool, string) {
+	var ports []string
+	for _, port := range c.Ports {
+		if port.HostPort > 0 && port.HostPort < threshold {
+			ports = append(ports, strconv.Itoa(port.HostPort))
+		}
+	}
+	return len(ports) > 0, strings.Join(ports, ", ")
+}
+
+// mountNotReadOnly flags writable mounts at or below any of the path prefixes
+func mountNotReadOnly(c Container, prefixes []string) (bool, string) {
+	var mounts []string
+	for _, mount := range c.VolumeMounts {
+		if mount.ReadOnly {
+			continue
+		}
+		for _, prefix := range prefixes {
+			if underPath(mount.MountPath, prefix) {
+				mounts = append(mounts, fmt.Sprintf("volume '%s' at %s", mount.Name, mount.MountPath))
+				break
+			}
+		}
+	}
+	return len(mounts) > 0, strings.Join(mounts, ", ")
+}
+
+// secretVolumeNotReadOnly flags writable mounts of Secret and ConfigMap volumes
+func secretVolumeNotReadOnly(c Container) (bool, string) {
+	var mounts []string
+	for _, mount := range c.VolumeMounts {
+		if mount.ReadOnly {
+			continue
+		}
+		for _, volume := range c.Volumes {
+			if volume.Name == mount.Name && volume.exposesConfigData() {
+				mounts = append(mounts, fmt.Sprintf("%s volume '%s' at %s", volume.Source, mount.Name, mount.MountPath))
+				break
+			}
+		}
+	}
+	return len(mounts) > 0, strings.Join(mounts, ", ")
+}
+
+// underPath reports whether path equals prefix or lies beneath it
+func underPath(path, prefix string) bool {
+	if prefix == "/" {
+		return true
+	}
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix == "" {
+		return false
+	}
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// portNameMissing flags ports without a name, which Services cannot target by name
+func portNameMissing(c Container) (bool, string) {
+	var ports []string
+	for _, port := range c.Ports {
+		if port.Name == "" {
+			ports = append(ports, strconv.Itoa(port.ContainerPort))
+		}
+	}
+	return len(ports) > 0, "unnamed port " + strings.Join(ports, ", ")
+}
+
+// containerPortBelow flags containerPorts below a threshold, such as the privileged range
+func containerPortBelow(c Container, threshold int) (bool, string) {
+	var ports []string
+	for _, port := range c.Ports {
+		number := strconv.Itoa(port.ContainerPort)
+		if port.ContainerPort > 0 && port.ContainerPort < threshold && !containsString(ports, number) {
+			ports = append(ports, number)
+		}
+	}
+	return len(ports) > 0, fmt.Sprintf("port %s below %d", strings.Join(ports, ", "), threshold)
+}
+
+// duplicateContainerPort flags a containerPort and protocol declared more than once
+func duplicateContainerPort(c Container) (bool, string) {
+	seen := make(map[string]bool)
+	var duplicates []string
+	for _, port := range c.Ports {
+		protocol := port.Protocol
+		if protocol == "" {
+			protocol = "TCP"
+		}
+		key := fmt.Sprintf("%d/%s", port.ContainerPort, protocol)
+		if seen[key] && !containsString(duplicates, key) {
+			duplicates = append(duplicates, key)
+		}
+		seen[key] = true
+	}
+	return len(duplicates) > 0, "duplicate port " + strings.Join(duplicates, ", ")
+}
+
+func missingImagePullPolicy(c Container) bool {
+	return c.ImagePullPolicy == ""
+}
+
+// imagePullPolicyAlwaysWithDigest flags Always on digest-pinned images,
+// which can never change and so never need to be re-pulled
+func imagePullPolicyAlwaysWithDigest(c Container) bool {
+	return c.ImagePullPolicy == "Always" && parseImageReference(c.Image).Digest != ""
+}
+
+// imagePullPolicyStaleLatest flags :latest images whose pull policy is
+// missing or IfNotPresent, so nodes may keep running an old image
+func imagePullPolicyStaleLatest(c Container) bool {
+	if !imageTagEquals(c.Image, "latest") {
+		return false
+	}
+	return c.ImagePullPolicy == "" || c.ImagePullPolicy == "IfNotPresent"
+}
+
+// pullPolicyLabel describes a pull policy for messages
+func pullPolicyLabel(policy string) string {
+	if policy == "" {
+		return "unset"
+	}
+	return policy
+}
+
+func isEphemeralContainer(c Container) bool {
+	return c.Origin == OriginEphemeralContainer
+}
+
+// podContainers builds the containers of a pod spec map, giving each the
+// pod-level settings already parsed into pod
+func podContainers(resource K8sResource, podSpec map[string]interface{}, pod *PodSpec) []Container {
+	var containers []Container
+	if containerList, ok := podSpec["containers"].([]interface{}); ok {
+		containers = append(containers, parseContainers(containerList, OriginContainer)...)
+	}
+	if containerList, ok := podSpec["initContainers"].([]interface{}); ok {
+		containers = append(containers, parseContainers(containerList, OriginInitContainer)...)
+	}
+	if containerList, ok := podSpec["ephemeralContainers"].([]interface{}); ok {
+		containers = append(containers, parseContainers(containerList, OriginEphemeralContainer)...)
+	}
+
+	triggers := imageChangeTriggers(resource)
+	for i := range containers {
+		containers[i].PodSecurityContext = pod.SecurityContext
+		containers[i].Volumes = pod.Volumes
+		containers[i].ImagePullSecrets = pod.ImagePullSecrets
+		containers[i].ServiceAccountName = pod.ServiceAccountName
+		containers[i].ImageStreamTag = triggers[containers[i].Name]
+	}
+
+	return containers
+}
+
+// podSpecPaths maps workload kinds to the dotted path of their pod spec
+var podSpecPaths = map[string]string{
+	"Pod":                   "spec",
+	"Deployment":            "spec.template.spec",
+	"StatefulSet":           "spec.template.spec",
+	"DaemonSet":             "spec.template.spec",
+	"ReplicaSet":            "spec.template.spec",
+	"ReplicationController": "spec.template.spec",
+	"Job":                   "spec.template.spec",
+	"CronJob":               "spec.jobTemplate.spec.template.spec",
+	"DeploymentConfig":      "spec.template.spec",
+}
+
+// podSpecPath returns the dotted path of a resource's pod spec. The config's
+// podSpecPaths, keyed by "apiVersion/Kind" or by kind, come first, in that
+// order, then the built-in workload kinds
+func podSpecPath(resource K8sResource, specPaths map[string]string) (string, bool) {
+	if path, ok := specPaths[resource.APIVersion+"/"+resource.Kind]; ok {
+		return path, true
+	}
+	if path, ok := specPaths[resource.Kind]; ok {
+		return path, true
+	}
+	path, ok := podSpecPaths[resource.Kind]
+	return path, ok
+}
+
+// extractPodSpec parses the pod-level settings of a K8s resource
+// It returns nil for resources without a pod spec
+func extractPodSpec(resource K8sResource, specPaths map[string]string) *PodSpec {
+	podSpec := findPodSpec(resource, specPaths)
+	if podSpec == nil {
+		return nil
+	}
+	pod := parsePodSpec(podSpec)
+	pod.Containers = podContainers(resource, podSpec, pod)
+	return pod
+}
+
+// parsePodSpec parses pod-level settings from a pod spec map
+func parsePodSpec(podSpecMap map[string]interface{}) *PodSpec {
+	podSpec := &PodSpec{
+		HostNetwork: getBoolValue(podSpecMap, "hostNetwork"),
+		HostPID:     getBoolValue(podSpecMap, "hostPID"),
+		HostIPC:     getBoolValue(podSpecMap, "hostIPC"),
+	}
+
+	podSpec.ShareProcessNamespace = getBoolValue(podSpecMap, "shareProcessNamespace")
+
+	podSpec.RestartPolicy = getStringValue(podSpecMap, "restartPolicy")
+	podSpec.DNSPolicy = getStringValue(podSpecMap, "dnsPolicy")
+	podSpec.PriorityClassName = getStringValue(podSpecMap, "priorityClassName")
+	podSpec.AntiAffinityTopologyKeys = parseAntiAffinityTopologyKeys(podSpecMap)
+	podSpec.NodeSelector = getStringMap(podSpecMap, "nodeSelector")
+	if tolerations, ok := podSpecMap["tolerations"].([]interface{}); ok {
+		for _, t := range tolerations {
+			if tolerationMap, ok := t.(map[string]interface{}); ok {
+				podSpec.Tolerations = append(podSpec.Tolerations, Toleration{
+					Key:      getStringValue(tolerationMap, "key"),
+					Operator: getStringValue(tolerationMap, "operator"),
+					Value:    getStringValue(tolerationMap, "value"),
+					Effect:   getStringValue(tolerationMap, "effect"),
+				})
+			}
+		}
+	}
+	if constraints, ok := podSpecMap["topologySpreadConstraints"].([]interface{}); ok {
+		for _, c := range constraints {
+			if constraintMap, ok := c.(map[string]interface{}); ok {
+				podSpec.TopologySpreadKeys = append(podSpec.TopologySpreadKeys, getStringValue(constraintMap, "topologyKey"))
+			}
+		}
+	}
+	if secrets, ok := podSpecMap["imagePullSecrets"].([]interface{}); ok {
+		for _, secret := range secrets {
+			if secretMap, ok := secret.(map[string]interface{}); ok {
+				podSpec.ImagePullSecrets = append(podSpec.ImagePullSecrets, getStringValue(secretMap, "name"))
+			}
+		}
+	}
+	if volumeList, ok := podSpecMap["volumes"].([]interface{}); ok {
+		podSpec.Volumes = parseVolumes(volumeList)
+	}
+	if grace, ok := getIntValue(podSpecMap, "terminationGracePeriodSeconds"); ok {
+		podSpec.TerminationGracePeriodSeconds = &grace
+	}
+
+	// serviceAccount is the deprecated alias of serviceAccountName
+	podSpec.ServiceAccountName = getStringValue(podSpecMap, "serviceAccountName")
+	if podSpec.ServiceAccountName == "" {
+		podSpec.ServiceAccountName = getStringValue(podSpecMap, "serviceAccount")
+	}
+
+	if securityMap, ok := podSpecMap["securityContext"].(map[string]interface{}); ok {
+		podSpec.SecurityContext = parseSecurityContext(securityMap)
+
+		if sysctls, ok := securityMap["sysctls"].([]interface{}); ok {
+			for _, sysctl := range sysctls {
+				if sysctlMap, ok := sysctl.(map[string]interface{}); ok {
+					podSpec.Sysctls = append(podSpec.Sysctls, getStringValue(sysctlMap, "name"))
+				}
+			}
+		}
+	}
+
+	return podSpec
+}
+
+// parseAntiAffinityTopologyKeys collects the topology keys of required and preferred podAntiAffinity terms
+func parseAntiAffinityTopologyKeys(podSpecMap map[string]interface{}) []string {
+	affinityMap, _ := podSpecMap["affinity"].(map[string]interface{})
+	antiAffinityMap, ok := affinityMap["podAntiAffinity"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	var keys []string
+	if required, ok := antiAffinityMap["requiredDuringSchedulingIgnoredDuringExecution"].([]interface{}); ok {
+		for _, term := range required {
+			if termMap, ok := term.(map[string]interface{}); ok {
+				keys = append(keys, getStringValue(termMap, "topologyKey"))
+			}
+		}
+	}
+	if preferred, ok := antiAffinityMap["preferredDuringSchedulingIgnoredDuringExecution"].([]interface{}); ok {
+		for _, weighted := range preferred {
+			weightedMap, _ := weighted.(map[string]interface{})
+			if termMap, ok := weightedMap["podAffinityTerm"].(map[string]interface{}); ok {
+				keys = append(keys, getStringValue(termMap, "topologyKey"))
+			}
+		}
+	}
+	return keys
+}
+
+// findPodSpec finds the pod spec map inside a K8s resource
+func findPodSpec(resource K8sResource, specPaths map[string]string) map[string]interface{} {
+	if resource.Spec == nil {
+		return nil
+	}
+
+	if path, ok := podSpecPath(resource, specPaths); ok {
+		return lookupSpecPath(resource, path)
+	}
+
+	// Unknown kind: best-effort search for a map holding a containers array
+	return findContainersParent(resource.Spec)
+}
+
+// lookupSpecPath walks a dotted path starting at "spec" and returns the map found there
+func lookupSpecPath(resource K8sResource, path string) map[string]interface{} {
+	parts := strings.Split(path, ".")
+	if parts[0] != "spec" {
+		return nil
+	}
+
+	current := resource.Spec
+	for _, part := range parts[1:] {
+		next, ok := current[part].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = next
+	}
+
+	return current
+}
+
+// findContainersParent searches breadth-first for the shallowest map with a containers array
+func findContainersParent(root map[string]interface{}) map[string]interface{} {
+	queue := []map[string]interface{}{root}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if _, ok := current["containers"].([]interface{}); ok {
+			return current
+		}
+
+		// Visit keys in sorted order so the result is deterministic
+		keys := make([]string, 0, len(current))
+		for key := range current {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if child, ok := current[key].(map[string]interface{}); ok {
+				queue = append(queue, child)
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseContainers converts interface{} to Container structs
+func parseContainers(containerList []interface{}, origin string) []Container {
+	var containers []Container
+
+	for _, c := range containerList {
+		containerMap, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		container := Container{
+			Name:   getStringValue(containerMap, "name"),
+			Origin: origin,
+			Image:  getStringValue(containerMap, "image"),
+		}
+
+		// Parse resources
+		if resourcesMap, ok := containerMap["resources"].(map[string]interface{}); ok {
+			container.Resources = parseResources(resourcesMap)
+		}
+
+		// Parse security context
+		if securityMap, ok := containerMap["securityContext"].(map[string]interface{}); ok {
+			container.SecurityContext = parseSecurityContext(securityMap)
+		}
+
+		// Parse probes
+		container.LivenessProbe = parseProbe(containerMap, "livenessProbe")
+		container.ReadinessProbe = parseProbe(containerMap, "readinessProbe")
+		container.StartupProbe = parseProbe(containerMap, "startupProbe")
+
+		// Parse image pull policy
+		container.ImagePullPolicy = getStringValue(containerMap, "imagePullPolicy")
+
+		// Parse env
+		if envList, ok := containerMap["env"].([]interface{}); ok {
+			container.Env = parseEnv(envList)
+		}
+		if envFromList, ok := containerMap["envFrom"].([]interface{}); ok {
+			container.EnvFrom = parseEnvFrom(envFromList)
+		}
+
+		// Parse ports
+		if portList, ok := containerMap["ports"].([]interface{}); ok {
+			container.Ports = parsePorts(portList)
+		}
+
+		// Parse volume mounts
+		if mountList, ok := containerMap["volumeMounts"].([]interface{}); ok {
+			for _, m := range mountList {
+				if mountMap, ok := m.(map[string]interface{}); ok {
+					container.VolumeMounts = append(container.VolumeMounts, VolumeMount{
+						Name:      getStringValue(mountMap, "name"),
+						MountPath: getStringValue(mountMap, "mountPath"),
+						ReadOnly:  getBoolValue(mountMap, "readOnly"),
+					})
+				}
+			}
+		}
+
+		if deviceList, ok := containerMap["volumeDevices"].([]interface{}); ok {
+			for _, d := range deviceList {
+				if deviceMap, ok := d.(map[string]interface{}); ok {
+					container.VolumeDevices = append(container.VolumeDevices, getStringValue(deviceMap, "name"))
+				}
+			}
+		}
+
+		// Parse lifecycle hooks
+		if lifecycleMap, ok := containerMap["lifecycle"].(map[string]interface{}); ok {
+			container.PreStop = parseLifecycleHandler(lifecycleMap, "preStop")
+		}
+
+		containers = append(containers, container)
+	}
+
+	return containers
+}
+
+// parseProbe parses a probe field, returning nil when the field is absent
+func parseProbe(containerMap map[string]interface{}, key string) *Probe {
+	if _, ok := containerMap[key]; !ok {
+		return nil
+	}
+
+	probe := &Probe{}
+	probeMap, ok := containerMap[key].(map[string]interface{})
+	if !ok {
+		return probe
+	}
+
+	probe.InitialDelaySeconds, _ = getIntValue(probeMap, "initialDelaySeconds")
+	probe.PeriodSeconds, _ = getIntValue(probeMap, "periodSeconds")
+	probe.TimeoutSeconds, _ = getIntValue(probeMap, "timeoutSeconds")
+	probe.FailureThreshold, _ = getIntValue(probeMap, "failureThreshold")
+
+	probe.Handler = make(map[string]interface{})
+	for _, key := range probeHandlerKeys {
+		if handler, ok := probeMap[key]; ok {
+			probe.Handler[key] = handler
+		}
+	}
+
+	return probe
+}
+
+// parseLifecycleHandler returns the handler type of a lifecycle hook, or ""
+// when the hook is absent or sets no handler
+func parseLifecycleHandler(lifecycleMap map[string]interface{}, key string) string {
+	hookMap, ok := lifecycleMap[key].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	for _, handler := range lifecycleHandlerKeys {
+		if _, ok := hookMap[handler]; ok {
+			return handler
+		}
+	}
+	return ""
+}
+
+// parseEnv parses a container's env list
+func parseEnv(envList []interface{}) []EnvVar {
+	var env []EnvVar
+
+	for _, e := range envList {
+		envMap, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		envVar := EnvVar{
+			Name:  getStringValue(envMap, "name"),
+			Value: getStringValue(envMap, "value"),
+		}
+
+		if valueFromMap, ok := envMap["valueFrom"].(map[string]interface{}); ok {
+			envVar.ValueFrom = &EnvVarSource{}
+			for _, sourceType := range []string{"secretKeyRef", "configMapKeyRef", "fieldRef", "resourceFieldRef"} {
+				if sourceMap, ok := valueFromMap[sourceType].(map[string]interface{}); ok {
+					envVar.ValueFrom.Type = sourceType
+					envVar.ValueFrom.Name = getStringValue(sourceMap, "name")
+					envVar.ValueFrom.Key = getStringValue(sourceMap, "key")
+					envVar.ValueFrom.Optional = getBoolValue(sourceMap, "optional")
+					break
+				}
+			}
+		}
+
+		env = append(env, envVar)
+	}
+
+	return env
+}
+
+// parseEnvFrom parses a container's envFrom list
+func parseEnvFrom(envFromList []interface{}) []EnvFromSource {
+	var sources []EnvFromSource
+
+	for _, e := range envFromList {
+		envFromMap, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		for _, sourceType := range []string{"secretRef", "configMapRef"} {
+			if refMap, ok := envFromMap[sourceType].(map[string]interface{}); ok {
+				sources = append(sources, EnvFromSource{
+					Type:     sourceType,
+					Name:     getStringValue(refMap, "name"),
+					Prefix:   getStringValue(envFromMap, "prefix"),
+					Optional: getBoolValue(refMap, "optional"),
+				})
+				break
+			}
+		}
+	}
+
+	return sources
+}
+
+// parsePorts parses a container's ports list
+func parsePorts(portList []interface{}) []ContainerPort {
+	var ports []ContainerPort
+
+	for _, p := range portList {
+		portMap, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		port := ContainerPort{
+			Name:     getStringValue(portMap, "name"),
+			Protocol: getStringValue(portMap, "protocol"),
+		}
+		port.ContainerPort, _ = getIntValue(portMap, "containerPort")
+		port.HostPort, _ = getIntValue(portMap, "hostPort")
+
+		ports = append(ports, port)
+	}
+
+	return ports
+}
+
+// volumeSources are the source fields of a volume, in the order of the
+// Kubernetes VolumeSource type
+var volumeSources = []string{
+	"hostPath", "emptyDir", "gcePersistentDisk", "awsElasticBlockStore", "gitRepo",
+	"secret", "nfs", "iscsi", "glusterfs", "persistentVolumeClaim", "rbd",
+	"flexVolume", "cinder", "cephfs", "flocker", "downwardAPI", "fc", "azureFile",
+	"configMap", "vsphereVolume", "quobyte", "azureDisk", "photonPersistentDisk",
+	"projected", "portworxVolume", "scaleIO", "storageos", "csi", "ephemeral", "image",
+}
+
+// volumeSource returns the source field a volume sets. A volume sets exactly
+// one, but should a malformed one set several, the first known source wins,
+// then the first unknown key in sorted order, so the result is stable
+func volumeSource(volumeMap map[string]interface{}) string {
+	for _, source := range volumeSources {
+		if _, ok := volumeMap[source]; ok {
+			return source
+		}
+	}
+	var keys []string
+	for key := range volumeMap {
+		if key != "name" {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.Strings(keys)
+	return keys[0]
+}
+
+// parseVolumes parses a pod's volumes list
+func parseVolumes(volumeList []interface{}) []Volume {
+	var volumes []Volume
+
+	for _, v := range volumeList {
+		volumeMap, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		volume := Volume{Name: getStringValue(volumeMap, "name"), Source: volumeSource(volumeMap)}
+		if volume.Source == "emptyDir" {
+			volume.EmptyDir = &EmptyDirSource{}
+			if emptyDirMap, ok := volumeMap["emptyDir"].(map[string]interface{}); ok {
+				volume.EmptyDir.Medium = getStringValue(emptyDirMap, "medium")
+				volume.EmptyDir.SizeLimit = getQuantityValue(emptyDirMap, "sizeLimit")
+			}
+		}
+		if claimMap, ok := volumeMap["persistentVolumeClaim"].(map[string]interface{}); ok {
+			volume.ClaimName = getStringValue(claimMap, "claimName")
+		}
+		if secretMap, ok := volumeMap["secret"].(map[string]interface{}); ok {
+			volume.References = append(volume.References, parseVolumeReferences("Secret", getStringValue(secretMap, "secretName"), secretMap)...)
+		}
+		if configMapMap, ok := volumeMap["configMap"].(map[string]interface{}); ok {
+			volume.References = append(volume.References, parseVolumeReferences("ConfigMap", getStringValue(configMapMap, "name"), configMapMap)...)
+		}
+		if projected, ok := volumeMap["projected"].(map[string]interface{}); ok {
+			if sources, ok := projected["sources"].([]interface{}); ok {
+				for _, src := range sources {
+					if sourceMap, ok := src.(map[string]interface{}); ok {
+						for key := range sourceMap {
+							volume.ProjectedSources = append(volume.ProjectedSources, key)
+						}
+						if secretMap, ok := sourceMap["secret"].(map[string]interface{}); ok {
+							volume.References = append(volume.References, parseVolumeReferences("Secret", getStringValue(secretMap, "name"), secretMap)...)
+						}
+						if configMapMap, ok := sourceMap["configMap"].(map[string]interface{}); ok {
+							volume.References = append(volume.References, parseVolumeReferences("ConfigMap", getStringValue(configMapMap, "name"), configMapMap)...)
+						}
+					}
+				}
+			}
+		}
+
+		volumes = append(volumes, volume)
+	}
+
+	return volumes
+}
+
+// parseResources parses resource requirements
+func parseResources(resourcesMap map[string]interface{}) *Resources {
 	resources := &Resources{}
 
 	if requestsMap, ok := resourcesMap["requests"].(map[string]interface{}); ok {
 		resources.Requests = &ResourceSpec{
-			CPU:    getStringValue(requestsMap, "cpu"),
-			Memory: getStringValue(requestsMap, "memory"),
+			CPU:    getQuantityValue(requestsMap, "cpu"),
+			Memory: getQuantityValue(requestsMap, "memory"),
 		}
 	}
 
 	if limitsMap, ok := resourcesMap["limits"].(map[string]interface{}); ok {
 		resources.Limits = &ResourceSpec{
-			CPU:    getStringValue(limitsMap, "cpu"),
-			Memory: getStringValue(limitsMap, "memory"),
+			CPU:    getQuantityValue(limitsMap, "cpu"),
+			Memory: getQuantityValue(limitsMap, "memory"),
 		}
 	}
 
@@ -293,6 +2155,42 @@ func parseSecurityContext(securityMap map[string]interface{}) *SecurityContext {
 		sc.Privileged = &privileged
 	}
 
+	if readOnlyRootFilesystem, ok := securityMap["readOnlyRootFilesystem"].(bool); ok {
+		sc.ReadOnlyRootFilesystem = &readOnlyRootFilesystem
+	}
+
+	sc.ProcMount = getStringValue(securityMap, "procMount")
+
+	if allowPrivilegeEscalation, ok := securityMap["allowPrivilegeEscalation"].(bool); ok {
+		sc.AllowPrivilegeEscalation = &allowPrivilegeEscalation
+	}
+	if seccompMap, ok := securityMap["seccompProfile"].(map[string]interface{}); ok {
+		sc.SeccompProfile = getStringValue(seccompMap, "type")
+	}
+	if appArmorMap, ok := securityMap["appArmorProfile"].(map[string]interface{}); ok {
+		sc.AppArmorProfile = getStringValue(appArmorMap, "type")
+	}
+	if seLinuxMap, ok := securityMap["seLinuxOptions"].(map[string]interface{}); ok {
+		sc.SELinuxOptions = &SELinuxOptions{
+			User:  getStringValue(seLinuxMap, "user"),
+			Role:  getStringValue(seLinuxMap, "role"),
+			Type:  getStringValue(seLinuxMap, "type"),
+			Level: getStringValue(seLinuxMap, "level"),
+		}
+	}
+	if windowsMap, ok := securityMap["windowsOptions"].(map[string]interface{}); ok {
+		if hostProcess, ok := windowsMap["hostProcess"].(bool); ok {
+			sc.HostProcess = &hostProcess
+		}
+	}
+
+	if capabilitiesMap, ok := securityMap["capabilities"].(map[string]interface{}); ok {
+		sc.Capabilities = &Capabilities{
+			Add:  getStringList(capabilitiesMap, "add"),
+			Drop: getStringList(capabilitiesMap, "drop"),
+		}
+	}
+
 	return sc
 }
 
@@ -303,3 +2201,59 @@ func getStringValue(m map[string]interface{}, key string) string {
 	}
 	return ""
 }
+
+// getQuantityValue gets a resource quantity as written, since unquoted
+// quantities such as "cpu: 2" decode as numbers
+func getQuantityValue(m map[string]interface{}, key string) string {
+	switch val := m[key].(type) {
+	case string:
+		return val
+	case int:
+		return strconv.Itoa(val)
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
+
+// getIntValue safely gets an integer value from a map
+// YAML decodes integers as int, while JSON input yields float64
+func getIntValue(m map[string]interface{}, key string) (int, bool) {
+	switch val := m[key].(type) {
+	case int:
+		return val, true
+	case int64:
+		return int(val), true
+	case float64:
+		return int(val), true
+	default:
+		return 0, false
+	}
+}
+
+// getBoolValue safely gets a bool value from a map, defaulting to false
+func getBoolValue(m map[string]interface{}, key string) bool {
+	if val, ok := m[key].(bool); ok {
+		return val
+	}
+	return false
+}
+
+// getStringList safely gets a list of strings from a map
+func getStringList(m map[string]interface{}, key string) []string {
+	list, ok := m[key].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var values []string
+	for _, item := range list {
+		if val, ok := item.(string); ok {
+			values = append(values, val)
+		}
+	}
+	return values
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// PrintRules lists every rule of the config, enabled or not, for `kubecheck rules`
+func PrintRules(w io.Writer, config *RuleConfig) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSEVERITY\tTYPE\tTAGS\tSTATUS\tSOURCE\tSCOPE\tDESCRIPTION")
+
+	enabled := 0
+	for _, rule := range config.Rules {
+		status := "disabled"
+		if config.RuleEnabled(rule) {
+			status = "enabled"
+			enabled++
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", rule.Name, rule.Severity, rule.Type, ruleTags(rule), status, ruleSource(rule), ruleScope(rule), rule.Description)
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write rules: %w", err)
+	}
+	if err := printVars(w, config); err != nil {
+		return err
+	}
+	if err := printPodSpecKinds(w, config); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "\n%d rules, %d enabled\n", len(config.Rules), enabled)
+	return err
+}
+
+// printVars lists the vars of the config with the values conditions see in
+// place of "$name" and the rules that reference them
+func printVars(w io.Writer, config *RuleConfig) error {
+	if len(config.Vars) == 0 {
+		return nil
+	}
+
+	users := make(map[string][]string)
+	for _, rule := range config.Rules {
+		for _, name := range ruleVars(rule) {
+			users[name] = append(users[name], rule.Name)
+		}
+	}
+	names := make([]string, 0, len(config.Vars))
+	for name := range config.Vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VAR\tVALUES\tUSED BY")
+	for _, name := range names {
+		usedBy := "-"
+		if len(users[name]) > 0 {
+			usedBy = strings.Join(users[name], ",")
+		}
+		fmt.Fprintf(tw, "$%s\t%s\t%s\n", name, strings.Join(config.Vars[name], ","), usedBy)
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write vars: %w", err)
+	}
+	return nil
+}
+
+// printPodSpecKinds lists the kinds whose containers the rules check and
+// the path of their pod spec: the built-in workload kinds and those the
+// config maps with podSpecPaths, which take precedence
+func printPodSpecKinds(w io.Writer, config *RuleConfig) error {
+	paths := make(map[string]string, len(podSpecPaths)+len(config.PodSpecPaths))
+	sources := make(map[string]string, len(paths))
+	for kind, path := range podSpecPaths {
+		paths[kind], sources[kind] = path, "built-in"
+	}
+	for kind, path := range config.PodSpecPaths {
+		paths[kind], sources[kind] = path, "podSpecPaths"
+	}
+	kinds := make([]string, 0, len(paths))
+	for kind := range paths {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tPOD SPEC\tSOURCE")
+	for _, kind := range kinds {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", kind, paths[kind], sources[kind])
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write pod spec kinds: %w", err)
+	}
+	return nil
+}
+
+// ruleVars returns the vars that a rule's conditions and when expression
+// reference
+func ruleVars(rule Rule) []string {
+	texts := append([]string{}, rule.Conditions...)
+	var walk func(e *ConditionExpr)
+	walk = func(e *ConditionExpr) {
+		if e.op == "" {
+			texts = append(texts, e.Condition)
+		}
+		for _, child := range e.children() {
+			walk(child)
+		}
+	}
+	if rule.When != nil {
+		walk(rule.When)
+	}
+
+	var names []string
+	for _, text := range texts {
+		condition, err := parseCondition(text)
+		if err != nil {
+			continue
+		}
+		if name, ok := varReference(condition.Value); ok {
+			names = appendUnique(names, name)
+		}
+	}
+	return names
+}
+
+// ruleSource names the config layer a rule comes from: the file that last
+// defined or changed it, a profile, or the built-in defaults
+func ruleSource(rule Rule) string {
+	switch {
+	case rule.Source != "":
+		return rule.Source
+	case rule.Profile != "":
+		return "profile " + rule.Profile
+	default:
+		return defaultExtends
+	}
+}
+
+// ruleTags lists a rule's tags, comma-separated, or "-" for none
+func ruleTags(rule Rule) string {
+	if len(rule.Tags) == 0 {
+		return "-"
+	}
+	return strings.Join(rule.Tags, ",")
+}
+
+// ruleScope summarizes the kinds, namespaces, and labels a rule is limited
+// to, e.g. "kinds=Deployment,StatefulSet namespaces=prod-*", or "-" for none
+func ruleScope(rule Rule) string {
+	var parts []string
+	if len(rule.Kinds) > 0 {
+		parts = append(parts, "kinds="+strings.Join(rule.Kinds, ","))
+	}
+	if len(rule.ExcludeKinds) > 0 {
+		parts = append(parts, "excludeKinds="+strings.Join(rule.ExcludeKinds, ","))
+	}
+	if len(rule.Namespaces) > 0 {
+		parts = append(parts, "namespaces="+strings.Join(rule.Namespaces, ","))
+	}
+	if len(rule.ExcludeNamespaces) > 0 {
+		parts = append(parts, "excludeNamespaces="+strings.Join(rule.ExcludeNamespaces, ","))
+	}
+	if rule.Selector != nil {
+		parts = append(parts, "selector="+strings.ReplaceAll(rule.Selector.String(), ", ", ","))
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, " ")
+}
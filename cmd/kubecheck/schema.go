@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Schema is the subset of an OpenAPI v3 schema kubecheck validates against:
+// types, required fields, enums, and which fields an object may hold
+type Schema struct {
+	Type       string
+	Properties map[string]*Schema
+	Required   []string
+	Enum       []interface{}
+	Items      *Schema
+	// AdditionalProperties is the schema of map values, nil unless set
+	AdditionalProperties *Schema
+	// AllowAdditional is set by additionalProperties: true
+	AllowAdditional bool
+	// PreserveUnknownFields is x-kubernetes-preserve-unknown-fields: fields the
+	// schema does not list are kept instead of rejected
+	PreserveUnknownFields bool
+	// IntOrString is x-kubernetes-int-or-string, as used by ports and percentages
+	IntOrString bool
+	// EmbeddedResource is x-kubernetes-embedded-resource, which allows
+	// apiVersion, kind, and metadata next to the listed properties
+	EmbeddedResource bool
+	Nullable         bool
+}
+
+// parseSchema reads a schema from its decoded YAML or JSON form
+func parseSchema(schemaMap map[string]interface{}) *Schema {
+	if schemaMap == nil {
+		return nil
+	}
+
+	schema := &Schema{
+		Type:                  getStringValue(schemaMap, "type"),
+		Required:              getStringList(schemaMap, "required"),
+		PreserveUnknownFields: getBoolValue(schemaMap, "x-kubernetes-preserve-unknown-fields"),
+		IntOrString:           getBoolValue(schemaMap, "x-kubernetes-int-or-string"),
+		EmbeddedResource:      getBoolValue(schemaMap, "x-kubernetes-embedded-resource"),
+		Nullable:              getBoolValue(schemaMap, "nullable"),
+	}
+	schema.Enum, _ = schemaMap["enum"].([]interface{})
+
+	if properties, ok := schemaMap["properties"].(map[string]interface{}); ok {
+		schema.Properties = make(map[string]*Schema, len(properties))
+		for name, p := range properties {
+			propertyMap, _ := p.(map[string]interface{})
+			schema.Properties[name] = parseSchema(propertyMap)
+		}
+	}
+	if items, ok := schemaMap["items"].(map[string]interface{}); ok {
+		schema.Items = parseSchema(items)
+	}
+	switch additional := schemaMap["additionalProperties"].(type) {
+	case bool:
+		schema.AllowAdditional = additional
+	case map[string]interface{}:
+		schema.AdditionalProperties = parseSchema(additional)
+	}
+
+	return schema
+}
+
+// Validate checks a value against the schema and returns one message per
+// violation, each starting with the path of the offending field
+func (s *Schema) Validate(value interface{}, path string) []string {
+	if s == nil {
+		return nil
+	}
+	if value == nil {
+		if s.Nullable || s.Type == "" {
+			return nil
+		}
+		return []string{fmt.Sprintf("%s: expected %s, got null", path, s.Type)}
+	}
+
+	if actual := schemaTypeOf(value); !s.acceptsType(actual) {
+		return []string{fmt.Sprintf("%s: expected %s, got %s", path, s.describeType(), actual)}
+	}
+	if len(s.Enum) > 0 && !s.enumContains(value) {
+		return []string{fmt.Sprintf("%s: %v is not one of %s", path, value, s.describeEnum())}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return s.validateObject(v, path)
+	case []interface{}:
+		var violations []string
+		for i, item := range v {
+			violations = append(violations, s.Items.Validate(item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return violations
+	}
+	return nil
+}
+
+// validateObject checks required fields, then each field against its property
+// schema. Fields the schema does not list are rejected unless the schema keeps
+// unknown fields or lists no properties at all
+func (s *Schema) validateObject(object map[string]interface{}, path string) []string {
+	var violations []string
+	for _, name := range s.Required {
+		if _, ok := object[name]; !ok {
+			violations = append(violations, fmt.Sprintf("%s: required field is missing", joinSchemaPath(path, name)))
+		}
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fieldPath := joinSchemaPath(path, name)
+		if property, ok := s.Properties[name]; ok {
+			violations = append(violations, property.Validate(object[name], fieldPath)...)
+			continue
+		}
+		switch {
+		case s.AdditionalProperties != nil:
+			violations = append(violations, s.AdditionalProperties.Validate(object[name], fieldPath)...)
+		case s.EmbeddedResource && (name == "apiVersion" || name == "kind" || name == "metadata"):
+		case s.AllowAdditional || s.PreserveUnknownFields || len(s.Properties) == 0:
+		default:
+			violations = append(violations, fmt.Sprintf("%s: unknown field", fieldPath))
+		}
+	}
+	return violations
+}
+
+// schemaTypeOf names the schema type of a decoded value
+func schemaTypeOf(value interface{}) string {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int, int64:
+		return "integer"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// acceptsType reports whether a value of the given type satisfies the schema
+func (s *Schema) acceptsType(actual string) bool {
+	if s.IntOrString && (actual == "integer" || actual == "string") {
+		return true
+	}
+	switch s.Type {
+	case "":
+		return !s.IntOrString
+	case "number":
+		return actual == "number" || actual == "integer"
+	default:
+		return s.Type == actual
+	}
+}
+
+// describeType names the type the schema expects, for messages
+func (s *Schema) describeType() string {
+	if s.IntOrString {
+		return "integer or string"
+	}
+	return s.Type
+}
+
+// enumContains reports whether the value is one of the schema's enum values
+func (s *Schema) enumContains(value interface{}) bool {
+	for _, allowed := range s.Enum {
+		if fmt.Sprint(allowed) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// describeEnum lists the enum values for messages
+func (s *Schema) describeEnum() string {
+	values := make([]string, len(s.Enum))
+	for i, v := range s.Enum {
+		values[i] = fmt.Sprint(v)
+	}
+	return strings.Join(values, ", ")
+}
+
+// joinSchemaPath appends a field name to a path such as spec.ports[0]
+func joinSchemaPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// defaultSecretNamePattern matches environment variable names that usually hold credentials
+const defaultSecretNamePattern = `(?i)(PASSWORD|PASSWD|TOKEN|SECRET|API_?KEY|PRIVATE_?KEY|CREDENTIALS?)`
+
+// credentialPattern is a family of values that look like committed credentials
+type credentialPattern struct {
+	Family string
+	Regexp *regexp.Regexp
+}
+
+// credentialPatterns lists value formats that are credentials regardless of the key they are stored under
+var credentialPatterns = []credentialPattern{
+	{Family: "AWS access key ID", Regexp: regexp.MustCompile(`^(AKIA|ASIA)[A-Z0-9]{16}$`)},
+	{Family: "JSON Web Token", Regexp: regexp.MustCompile(`^eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+$`)},
+	{Family: "GitHub token", Regexp: regexp.MustCompile(`^(ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36}$|^github_pat_[A-Za-z0-9_]{22,}$`)},
+	{Family: "PEM private key", Regexp: regexp.MustCompile(`-----BEGIN ([A-Z]+ )?PRIVATE KEY-----`)},
+}
+
+// Thresholds for treating an opaque value as a random secret
+const (
+	highEntropyMinLength = 24
+	highEntropyMinBits   = 4.2
+)
+
+// detectCredential returns the pattern family a value matches, or "" when
+// the value does not look like a credential
+func detectCredential(value string) string {
+	trimmed := strings.TrimSpace(value)
+	for _, pattern := range credentialPatterns {
+		if pattern.Regexp.MatchString(trimmed) {
+			return pattern.Family
+		}
+	}
+
+	if isHighEntropy(trimmed) {
+		return "high-entropy string"
+	}
+
+	return ""
+}
+
+// isHighEntropy reports whether a single-token value is long and random enough to be a key
+func isHighEntropy(value string) bool {
+	if len(value) < highEntropyMinLength || strings.ContainsAny(value, " \t\n/") {
+		return false
+	}
+	return shannonEntropy(value) >= highEntropyMinBits
+}
+
+// shannonEntropy returns the entropy of a string in bits per character
+func shannonEntropy(value string) float64 {
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range value {
+		counts[r]++
+		total++
+	}
+
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// committedCredentialFamilies are the credentialPatterns families that must never
+// be committed in a Secret manifest; random-looking values are normal there
+var committedCredentialFamilies = []string{"AWS access key ID", "GitHub token", "PEM private key"}
+
+// pemKeySecretTypes are Secret types whose purpose is to hold a private key
+var pemKeySecretTypes = []string{"kubernetes.io/tls", "kubernetes.io/ssh-auth"}
+
+// DataEntry is one key of a Secret's or ConfigMap's data
+type DataEntry struct {
+	Field string // data, stringData, or binaryData
+	Key   string
+	Value []byte // decoded; nil when Invalid
+	// Invalid marks a base64 field (data, binaryData) whose value does not decode
+	Invalid bool
+}
+
+// parseSecretEntries decodes the data and stringData of a Secret, sorted by key
+// Keys in both are reported once, from stringData, which the API server lets win
+func parseSecretEntries(resource K8sResource) []DataEntry {
+	entries := make(map[string]DataEntry)
+
+	for key, encoded := range getStringMap(resource.Object, "data") {
+		// The API accepts line-wrapped base64, as written by some tools
+		encoded = strings.NewReplacer("\n", "", "\r", "").Replace(encoded)
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			entries[key] = DataEntry{Field: "data", Key: key, Invalid: true}
+			continue
+		}
+		entries[key] = DataEntry{Field: "data", Key: key, Value: value}
+	}
+	for key, value := range getStringMap(resource.Object, "stringData") {
+		entries[key] = DataEntry{Field: "stringData", Key: key, Value: []byte(value)}
+	}
+
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := make([]DataEntry, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, entries[key])
+	}
+	return result
+}
+
+// secretDataCredential flags Secret values that match a committed credential
+// family; the details name the key and family but never the value
+func secretDataCredential(resource K8sResource) (bool, string) {
+	if resource.Kind != "Secret" {
+		return false, ""
+	}
+	secretType := getStringValue(resource.Object, "type")
+
+	var matches []string
+	for _, entry := range parseSecretEntries(resource) {
+		if entry.Invalid {
+			continue
+		}
+		family := detectCredential(string(entry.Value))
+		if !containsString(committedCredentialFamilies, family) {
+			continue
+		}
+		if family == "PEM private key" && containsString(pemKeySecretTypes, secretType) {
+			continue
+		}
+		matches = append(matches, fmt.Sprintf("%s key '%s' (%s)", entry.Field, entry.Key, family))
+	}
+	return len(matches) > 0, strings.Join(matches, ", ")
+}
+
+// secretDataInvalidBase64 flags Secret data values the API server would reject
+func secretDataInvalidBase64(resource K8sResource) (bool, string) {
+	if resource.Kind != "Secret" {
+		return false, ""
+	}
+
+	var keys []string
+	for _, entry := range parseSecretEntries(resource) {
+		if entry.Invalid {
+			keys = append(keys, "'"+entry.Key+"'")
+		}
+	}
+	return len(keys) > 0, strings.Join(keys, ", ")
+}
+
+// secretSizeExceeds flags Secrets whose decoded values total more than maximum bytes
+func secretSizeExceeds(resource K8sResource, maximum float64) (bool, string) {
+	if resource.Kind != "Secret" {
+		return false, ""
+	}
+
+	size := 0
+	for _, entry := range parseSecretEntries(resource) {
+		size += len(entry.Value)
+	}
+	if float64(size) <= maximum {
+		return false, ""
+	}
+	return true, formatBytes(size)
+}
+
+// formatBytes renders a byte count with a binary suffix, e.g. 1.2MiB
+func formatBytes(size int) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1fMiB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1fKiB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%dB", size)
+	}
+}
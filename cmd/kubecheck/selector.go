@@ -0,0 +1,141 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// LabelSelector represents a Kubernetes label selector
+// Rules use it in their config as selector
+type LabelSelector struct {
+	MatchLabels      map[string]string          `yaml:"matchLabels,omitempty"`
+	MatchExpressions []LabelSelectorRequirement `yaml:"matchExpressions,omitempty"`
+}
+
+// LabelSelectorRequirement represents one entry of matchExpressions
+type LabelSelectorRequirement struct {
+	Key      string   `yaml:"key"`
+	Operator string   `yaml:"operator"` // In, NotIn, Exists, or DoesNotExist
+	Values   []string `yaml:"values,omitempty"`
+}
+
+// parseLabelSelector parses a selector map with matchLabels and matchExpressions
+// It returns nil when the map is nil, i.e. the selector is absent
+func parseLabelSelector(selectorMap map[string]interface{}) *LabelSelector {
+	if selectorMap == nil {
+		return nil
+	}
+
+	selector := &LabelSelector{
+		MatchLabels: getStringMap(selectorMap, "matchLabels"),
+	}
+
+	if expressions, ok := selectorMap["matchExpressions"].([]interface{}); ok {
+		for _, e := range expressions {
+			exprMap, ok := e.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			selector.MatchExpressions = append(selector.MatchExpressions, LabelSelectorRequirement{
+				Key:      getStringValue(exprMap, "key"),
+				Operator: getStringValue(exprMap, "operator"),
+				Values:   getStringList(exprMap, "values"),
+			})
+		}
+	}
+
+	return selector
+}
+
+// Matches reports whether a label set satisfies the selector
+// An empty selector matches everything
+func (s *LabelSelector) Matches(labels map[string]string) bool {
+	for key, value := range s.MatchLabels {
+		if labels[key] != value {
+			return false
+		}
+	}
+
+	for _, requirement := range s.MatchExpressions {
+		value, exists := labels[requirement.Key]
+		switch requirement.Operator {
+		case "In":
+			if !exists || !containsString(requirement.Values, value) {
+				return false
+			}
+		case "NotIn":
+			if exists && containsString(requirement.Values, value) {
+				return false
+			}
+		case "Exists":
+			if !exists {
+				return false
+			}
+		case "DoesNotExist":
+			if exists {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// String formats the selector for messages, e.g. "app=web, tier In (api,web)"
+func (s *LabelSelector) String() string {
+	var parts []string
+	for key, value := range s.MatchLabels {
+		parts = append(parts, key+"="+value)
+	}
+	sort.Strings(parts)
+	for _, requirement := range s.MatchExpressions {
+		part := requirement.Key + " " + requirement.Operator
+		if len(requirement.Values) > 0 {
+			part += " (" + strings.Join(requirement.Values, ",") + ")"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// podTemplateLabels returns the labels of a workload's pod template, or of the Pod itself
+func podTemplateLabels(resource K8sResource, specPaths map[string]string) map[string]string {
+	path, ok := podSpecPath(resource, specPaths)
+	if !ok {
+		return nil
+	}
+	if path == "spec" {
+		return getStringMap(resource.Metadata, "labels")
+	}
+
+	metadata := lookupSpecPath(resource, strings.TrimSuffix(path, ".spec")+".metadata")
+	return getStringMap(metadata, "labels")
+}
+
+// getStringMap safely gets a map of strings, such as labels, from a map
+func getStringMap(m map[string]interface{}, key string) map[string]string {
+	raw, ok := m[key].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	result := make(map[string]string, len(raw))
+	for k, v := range raw {
+		if s, ok := v.(string); ok {
+			result[k] = s
+		}
+	}
+	return result
+}
+
+// containsString reports whether a list contains a value
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
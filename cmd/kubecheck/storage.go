@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// legacyStorageClassAnnotation predates spec.storageClassName and is still honoured
+const legacyStorageClassAnnotation = "volume.beta.kubernetes.io/storage-class"
+
+// byteSuffixPattern matches sizes written with a byte unit, such as "10GB" or "512MiB",
+// which Kubernetes quantities do not accept
+var byteSuffixPattern = regexp.MustCompile(`^([0-9.]+)([kKMGTPE]i?)[bB]$`)
+
+// PersistentVolumeClaim represents the fields of a PVC, or of a StatefulSet
+// volumeClaimTemplate, that the storage conditions read
+type PersistentVolumeClaim struct {
+	Name         string
+	AccessModes  []string
+	StorageClass string // "" when unset, so the cluster default applies
+	// StorageRequest is spec.resources.requests.storage as written, "" when unset
+	StorageRequest string
+}
+
+// parsePersistentVolumeClaims returns a PVC itself, or the volumeClaimTemplates
+// of a StatefulSet; other kinds have none
+func parsePersistentVolumeClaims(resource K8sResource) []PersistentVolumeClaim {
+	switch resource.Kind {
+	case "PersistentVolumeClaim":
+		return []PersistentVolumeClaim{parsePersistentVolumeClaim(resource.Metadata, resource.Spec)}
+	case "StatefulSet":
+		var claims []PersistentVolumeClaim
+		templates, _ := resource.Spec["volumeClaimTemplates"].([]interface{})
+		for _, t := range templates {
+			templateMap, ok := t.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			metadata, _ := templateMap["metadata"].(map[string]interface{})
+			spec, _ := templateMap["spec"].(map[string]interface{})
+			claims = append(claims, parsePersistentVolumeClaim(metadata, spec))
+		}
+		return claims
+	}
+	return nil
+}
+
+// parsePersistentVolumeClaim reads a claim from its metadata and spec
+func parsePersistentVolumeClaim(metadata, spec map[string]interface{}) PersistentVolumeClaim {
+	claim := PersistentVolumeClaim{
+		Name:         getStringValue(metadata, "name"),
+		AccessModes:  getStringList(spec, "accessModes"),
+		StorageClass: getStringValue(spec, "storageClassName"),
+	}
+	if claim.StorageClass == "" {
+		claim.StorageClass = getStringMap(metadata, "annotations")[legacyStorageClassAnnotation]
+	}
+	if resources, ok := spec["resources"].(map[string]interface{}); ok {
+		if requests, ok := resources["requests"].(map[string]interface{}); ok {
+			claim.StorageRequest = getQuantityValue(requests, "storage")
+		}
+	}
+	return claim
+}
+
+// claimLocation names the volumeClaimTemplate a claim comes from, "" for a PVC itself
+func claimLocation(resource K8sResource, claim PersistentVolumeClaim) string {
+	if resource.Kind == "PersistentVolumeClaim" {
+		return ""
+	}
+	return fmt.Sprintf(" in volumeClaimTemplate '%s'", claim.Name)
+}
+
+// pvcStorageRequestMissing flags claims without spec.resources.requests.storage,
+// which the API server rejects
+func pvcStorageRequestMissing(resource K8sResource) (bool, string) {
+	for _, claim := range parsePersistentVolumeClaims(resource) {
+		if claim.StorageRequest == "" {
+			return true, "spec.resources.requests.storage" + claimLocation(resource, claim)
+		}
+	}
+	return false, ""
+}
+
+// pvcStorageRequestInvalid flags claims whose storage request does not parse
+// Sizes written with a byte unit ("10GB") get a hint towards the Kubernetes spelling
+func pvcStorageRequestInvalid(resource K8sResource) (bool, string) {
+	for _, claim := range parsePersistentVolumeClaims(resource) {
+		if claim.StorageRequest == "" {
+			continue
+		}
+		if _, err := ParseQuantity(claim.StorageRequest); err == nil {
+			continue
+		}
+
+		details := fmt.Sprintf("'%s'%s", claim.StorageRequest, claimLocation(resource, claim))
+		if match := byteSuffixPattern.FindStringSubmatch(claim.StorageRequest); match != nil {
+			number, unit := match[1], strings.ToUpper(match[2][:1])
+			decimal := unit
+			if unit == "K" {
+				// The decimal kilo suffix is the only lower-case one
+				decimal = "k"
+			}
+			details += fmt.Sprintf(" (quantities take no B suffix: write %s%si for binary or %s%s for decimal units)", number, unit, number, decimal)
+		}
+		return true, details
+	}
+	return false, ""
+}
+
+// storageClassNotIn flags claims that name a storage class outside allowed
+// Claims without a class use the cluster default and are not flagged
+func storageClassNotIn(resource K8sResource, allowed []string) (bool, string) {
+	for _, claim := range parsePersistentVolumeClaims(resource) {
+		if claim.StorageClass == "" || containsString(allowed, claim.StorageClass) {
+			continue
+		}
+		return true, fmt.Sprintf("'%s'%s", claim.StorageClass, claimLocation(resource, claim))
+	}
+	return false, ""
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ignoreAnnotation lists the rules, comma-separated, a resource silences for itself
+	ignoreAnnotation = "kubecheck.io/ignore"
+	// ignoreContainersAnnotationPrefix, followed by a container name, lists the
+	// rules silenced for that container only
+	ignoreContainersAnnotationPrefix = "kubecheck.io/ignore-containers."
+	// unknownIgnoreRule is the rule name of warnings about ignores that name no rule
+	unknownIgnoreRule = "unknown-ignored-rule"
+	// ignoreCommentDirective starts a comment that lists rules to silence
+	ignoreCommentDirective = "kubecheck-ignore:"
+	// suppressedByAnnotations and suppressedByComments are the Suppression
+	// reasons of inline ignores
+	suppressedByAnnotations = "annotations"
+	suppressedByComments    = "comments"
+)
+
+// Suppression is a finding that was not reported, and why
+type Suppression struct {
+	Violation Violation
+	// Resource names the resource and container, e.g. "Deployment 'web' container 'istio-proxy'"
+	Resource string
+	// Reason names what suppressed the finding, e.g. "excludeImages"
+	Reason string
+}
+
+// suppress records a finding that is left out of the report
+func (re *RuleEngine) suppress(violation Violation, subject, reason string) {
+	re.suppressed = append(re.suppressed, Suppression{Violation: violation, Resource: subject, Reason: reason})
+}
+
+// Suppressed returns the findings left out of the report so far
+func (re *RuleEngine) Suppressed() []Suppression {
+	return re.suppressed
+}
+
+// resourceSubject names a resource in suppression listings, e.g. "Deployment 'web'"
+func resourceSubject(resource K8sResource) string {
+	return fmt.Sprintf("%s '%s'", resource.Kind, getResourceName(resource))
+}
+
+// containerSubject names a container of a resource in suppression listings
+func containerSubject(resource K8sResource, container Container) string {
+	return fmt.Sprintf("%s %s '%s'", resourceSubject(resource), container.Origin, container.Name)
+}
+
+// containerSuppression returns what keeps the rule's findings for a container
+// out of the report: the rule's exclusions, or an inline ignore. It returns ""
+// when the findings are reported
+func (re *RuleEngine) containerSuppression(rule Rule, resource K8sResource, container Container) string {
+	for _, pattern := range rule.ExcludeContainers {
+		if globMatch(pattern, container.Name) {
+			return "excludeContainers"
+		}
+	}
+	for _, pattern := range rule.ExcludeImages {
+		if globMatch(pattern, container.Image) {
+			return "excludeImages"
+		}
+	}
+	return re.ignoreReason(resource, container.Name, rule.Name)
+}
+
+// ignoreReason returns how the resource silences a rule inline, by
+// annotation or by comment, or "" when it does not. With a container name,
+// ignores of that container count too
+func (re *RuleEngine) ignoreReason(resource K8sResource, container, rule string) string {
+	if !re.inlineIgnores {
+		return ""
+	}
+	if containsString(annotationIgnores(resource, ""), rule) ||
+		(container != "" && containsString(annotationIgnores(resource, container), rule)) {
+		return suppressedByAnnotations
+	}
+	for _, directive := range resource.IgnoreComments {
+		if (directive.Container == "" || directive.Container == container) && containsString(directive.Rules, rule) {
+			return suppressedByComments
+		}
+	}
+	return ""
+}
+
+// annotationIgnores returns the rule names the resource's ignore annotation
+// lists, or with a container name, those of the container's annotation
+func annotationIgnores(resource K8sResource, container string) []string {
+	key := ignoreAnnotation
+	if container != "" {
+		key = ignoreContainersAnnotationPrefix + container
+	}
+	annotations, _ := resource.Metadata["annotations"].(map[string]interface{})
+	value, _ := annotations[key].(string)
+	return splitRuleNames(value)
+}
+
+// splitRuleNames splits a comma-separated list of rule names, dropping blanks
+func splitRuleNames(value string) []string {
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ApplyIgnores drops the resource-level findings that the resource silences
+// inline, recording them as suppressed
+func (re *RuleEngine) ApplyIgnores(resource K8sResource, violations []Violation) []Violation {
+	var kept []Violation
+	for _, v := range violations {
+		if reason := re.ignoreReason(resource, "", v.Rule); reason != "" {
+			re.suppress(v, resourceSubject(resource), reason)
+			continue
+		}
+		kept = append(kept, v)
+	}
+	return kept
+}
+
+// unknownIgnores warns about inline ignores that name a rule the config does
+// not define, so stale suppressions get cleaned up. Disabled rules count as
+// defined
+func (re *RuleEngine) unknownIgnores(resource K8sResource) []Violation {
+	if !re.inlineIgnores {
+		return nil
+	}
+
+	var violations []Violation
+	warn := func(where, name string) {
+		violations = append(violations, Violation{
+			Severity: SeverityWarn,
+			Rule:     unknownIgnoreRule,
+			Message:  fmt.Sprintf("%s '%s' %s ignores unknown rule '%s'", resource.Kind, getResourceName(resource), where, name),
+			Help:     "remove the rule from the ignore, or fix its name; kubecheck rules lists the defined rules",
+		})
+	}
+
+	annotations, _ := resource.Metadata["annotations"].(map[string]interface{})
+	for _, key := range sortedKeys(annotations) {
+		if key != ignoreAnnotation && !strings.HasPrefix(key, ignoreContainersAnnotationPrefix) {
+			continue
+		}
+		value, _ := annotations[key].(string)
+		for _, name := range splitRuleNames(value) {
+			if !re.ruleDefined(name) {
+				warn("annotation "+key, name)
+			}
+		}
+	}
+	for _, directive := range resource.IgnoreComments {
+		for _, name := range directive.Rules {
+			if !re.ruleDefined(name) {
+				warn(fmt.Sprintf("%s comment on line %d", ignoreCommentDirective, directive.Line), name)
+			}
+		}
+	}
+	return violations
+}
+
+// ruleDefined reports whether the config, or kubecheck itself, defines a rule name
+func (re *RuleEngine) ruleDefined(name string) bool {
+	if name == schemaRule || strings.HasPrefix(name, regoRulePrefix) || containsString(re.config.deselectedRules, name) {
+		return true
+	}
+	for _, rule := range re.config.Rules {
+		if rule.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// IgnoreDirective is a "# kubecheck-ignore: rule-a, rule-b" comment in a manifest
+type IgnoreDirective struct {
+	Rules []string
+	// Container is the container whose entry holds the comment, or "" when
+	// the comment silences the rules for the whole resource
+	Container string
+	// Line is the line of the node the comment is attached to
+	Line int
+}
+
+// containerListKeys are the pod spec fields whose entries are containers
+var containerListKeys = []string{"containers", "initContainers", "ephemeralContainers"}
+
+// ignoreComments collects the ignore directives of a document. A comment
+// inside a container entry, above or at the end of one of its lines, applies
+// to that container; any other comment applies to the whole resource
+func ignoreComments(doc *yaml.Node) []IgnoreDirective {
+	var directives []IgnoreDirective
+
+	var walk func(node *yaml.Node, container string)
+	walk = func(node *yaml.Node, container string) {
+		for _, comment := range []string{node.HeadComment, node.LineComment, node.FootComment} {
+			for _, line := range strings.Split(comment, "\n") {
+				line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "#"))
+				if rules, ok := strings.CutPrefix(line, ignoreCommentDirective); ok {
+					directives = append(directives, IgnoreDirective{Rules: splitRuleNames(rules), Container: container, Line: node.Line})
+				}
+			}
+		}
+
+		if node.Kind != yaml.MappingNode {
+			for _, child := range node.Content {
+				walk(child, container)
+			}
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			walk(key, container)
+			if container != "" || value.Kind != yaml.SequenceNode || !containsString(containerListKeys, key.Value) {
+				walk(value, container)
+				continue
+			}
+			// The sequence's own comments sit outside any one container
+			walk(&yaml.Node{HeadComment: value.HeadComment, LineComment: value.LineComment, FootComment: value.FootComment, Line: value.Line}, "")
+			for _, item := range value.Content {
+				walk(item, containerEntryName(item))
+			}
+		}
+	}
+
+	walk(doc, "")
+	return directives
+}
+
+// containerEntryName returns the name field of a container entry
+func containerEntryName(item *yaml.Node) string {
+	if item.Kind != yaml.MappingNode {
+		return ""
+	}
+	for i := 0; i+1 < len(item.Content); i += 2 {
+		if item.Content[i].Value == "name" {
+			return item.Content[i+1].Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SelectTags narrows the rules to those tagged with one of include, when it
+// is not empty, and drops those tagged with one of exclude. A tag that no
+// enabled rule carries is an error, since it is most likely a typo, and so
+// is a selection that leaves no enabled rule, which would pass every scan
+func (c *RuleConfig) SelectTags(include, exclude []string) error {
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil
+	}
+
+	known := make(map[string]bool)
+	for _, rule := range c.Rules {
+		if !c.RuleEnabled(rule) {
+			continue
+		}
+		for _, tag := range rule.Tags {
+			known[tag] = true
+		}
+	}
+	for _, tag := range append(append([]string{}, include...), exclude...) {
+		if !known[tag] {
+			return fmt.Errorf("tag %q matches no enabled rules (available: %s)", tag, joinTags(known))
+		}
+	}
+
+	var selected []Rule
+	enabled := 0
+	for _, rule := range c.Rules {
+		if (len(include) > 0 && !rule.HasTag(include...)) || rule.HasTag(exclude...) {
+			c.deselectedRules = append(c.deselectedRules, rule.Name)
+			continue
+		}
+		selected = append(selected, rule)
+		if c.RuleEnabled(rule) {
+			enabled++
+		}
+	}
+	if enabled == 0 {
+		return fmt.Errorf("--tags %s and --skip-tags %s leave no enabled rules", describeTags(include), describeTags(exclude))
+	}
+	c.Rules = selected
+	return nil
+}
+
+// HasTag reports whether the rule carries any of the given tags
+func (r Rule) HasTag(tags ...string) bool {
+	for _, tag := range tags {
+		if containsString(r.Tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// describeTags lists tags given on the command line, or "(none)"
+func describeTags(tags []string) string {
+	if len(tags) == 0 {
+		return "(none)"
+	}
+	return strings.Join(tags, ",")
+}
+
+// joinTags lists a set of tags, sorted and comma-separated
+func joinTags(set map[string]bool) string {
+	tags := make([]string, 0, len(set))
+	for tag := range set {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return strings.Join(tags, ", ")
+}
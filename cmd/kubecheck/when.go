@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConditionExpr is a node of a rule's when expression: a condition, or all,
+// any, or not over nested expressions
+//
+//	when:
+//	  all:
+//	    - image_tag_equals:latest
+//	    - not: image_pull_policy_equals:Always
+type ConditionExpr struct {
+	All []*ConditionExpr
+	Any []*ConditionExpr
+	Not *ConditionExpr
+	// Condition is set on leaves, in any of the forms of a conditions entry
+	Condition string
+
+	// op is "all", "any", "not", or "" for a condition
+	op string
+	// line is where the node starts in the config file
+	line int
+	// parsed is Condition as parsed by the rule engine
+	parsed Condition
+}
+
+// UnmarshalYAML reads a condition string, a condition mapping with a type,
+// or a mapping with exactly one of all, any, and not
+func (e *ConditionExpr) UnmarshalYAML(node *yaml.Node) error {
+	e.line = node.Line
+	switch node.Kind {
+	case yaml.ScalarNode:
+		e.Condition = node.Value
+		return nil
+	case yaml.MappingNode:
+	default:
+		return fmt.Errorf("line %d: a when expression must be a condition or a mapping with all, any, or not", node.Line)
+	}
+
+	for i := 0; i < len(node.Content); i += 2 {
+		if node.Content[i].Value == "type" {
+			condition, err := conditionFromMapping(node)
+			e.Condition = condition
+			return err
+		}
+	}
+	if len(node.Content) != 2 {
+		return fmt.Errorf("line %d: a when expression takes exactly one of all, any, or not", node.Line)
+	}
+
+	key, value := node.Content[0].Value, node.Content[1]
+	e.op = key
+	switch key {
+	case "all", "any":
+		if value.Kind != yaml.SequenceNode {
+			return fmt.Errorf("line %d: %s takes a list of expressions", value.Line, key)
+		}
+		list := &e.All
+		if key == "any" {
+			list = &e.Any
+		}
+		return value.Decode(list)
+	case "not":
+		e.Not = &ConditionExpr{}
+		return value.Decode(e.Not)
+	default:
+		return fmt.Errorf("line %d: unknown when operator %q (use all, any, or not)", node.Line, key)
+	}
+}
+
+// children returns the nested expressions of an all, any, or not node
+func (e *ConditionExpr) children() []*ConditionExpr {
+	switch e.op {
+	case "all":
+		return e.All
+	case "any":
+		return e.Any
+	case "not":
+		return []*ConditionExpr{e.Not}
+	default:
+		return nil
+	}
+}
+
+// parseWhen parses the conditions of a rule's when expression in place and
+// rejects empty all and any blocks, unknown condition types, and
+// expressions that mix container and cross-resource conditions
+func (c *RuleConfig) parseWhen(rule Rule) error {
+	var layers []conditionLayer
+
+	var parse func(e *ConditionExpr) error
+	parse = func(e *ConditionExpr) error {
+		if e.op == "" {
+			condition, err := c.parseRuleCondition(rule, e.Condition)
+			if err != nil {
+				return fmt.Errorf("%w on line %d", err, e.line)
+			}
+			layer, ok := conditionLayers[condition.Type]
+			if !ok {
+				return fmt.Errorf("rule %q: when: unknown condition type %q on line %d", rule.Name, condition.Type, e.line)
+			}
+			e.parsed = condition
+			layers = append(layers, layer)
+			return nil
+		}
+
+		if e.op != "not" && len(e.children()) == 0 {
+			return fmt.Errorf("rule %q: when: empty %s on line %d", rule.Name, e.op, e.line)
+		}
+		for _, child := range e.children() {
+			if err := parse(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := parse(rule.When); err != nil {
+		return err
+	}
+	if containsLayer(layers, containerLayer) && containsLayer(layers, bundleLayer) {
+		return fmt.Errorf("rule %q: when: container conditions cannot be combined with cross-resource conditions", rule.Name)
+	}
+	return nil
+}
+
+// containsLayer reports whether a list of layers holds a layer
+func containsLayer(layers []conditionLayer, layer conditionLayer) bool {
+	for _, l := range layers {
+		if l == layer {
+			return true
+		}
+	}
+	return false
+}
+
+// layer returns where a when expression is evaluated: per container when it
+// holds a container condition, across the scan when it holds a
+// cross-resource one, and otherwise once per resource
+func (e *ConditionExpr) layer() conditionLayer {
+	if e.op == "" {
+		return conditionLayers[e.parsed.Type]
+	}
+	result := resourceLayer
+	for _, child := range e.children() {
+		if layer := child.layer(); layer != resourceLayer {
+			result = layer
+		}
+	}
+	return result
+}
+
+// whenScope is what a when expression is evaluated against; container and
+// bundle are nil outside their layers
+type whenScope struct {
+	resource  K8sResource
+	podSpec   *PodSpec
+	container *Container
+	bundle    *Bundle
+}
+
+// evaluateWhen evaluates a when expression, stopping at the first failing
+// all entry and the first matching any entry. The details of an all are
+// those of its entries, joined with "; "; not has none
+func (re *RuleEngine) evaluateWhen(e *ConditionExpr, scope whenScope) (bool, string) {
+	switch e.op {
+	case "all":
+		var details []string
+		for _, child := range e.All {
+			matched, detail := re.evaluateWhen(child, scope)
+			if !matched {
+				return false, ""
+			}
+			if detail != "" {
+				details = append(details, detail)
+			}
+		}
+		return true, strings.Join(details, "; ")
+	case "any":
+		for _, child := range e.Any {
+			if matched, detail := re.evaluateWhen(child, scope); matched {
+				return true, detail
+			}
+		}
+		return false, ""
+	case "not":
+		matched, _ := re.evaluateWhen(e.Not, scope)
+		return !matched, ""
+	}
+
+	switch conditionLayers[e.parsed.Type] {
+	case containerLayer:
+		if scope.container == nil {
+			return false, ""
+		}
+		return re.checkCondition(e.parsed, *scope.container)
+	case bundleLayer:
+		if scope.bundle == nil {
+			return false, ""
+		}
+		return re.checkBundleCondition(e.parsed, scope.resource, scope.bundle)
+	default:
+		return re.checkResourceCondition(e.parsed, scope.resource, scope.podSpec)
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// statefulSetServiceNameMissing flags StatefulSets without spec.serviceName,
+// which the API server requires
+func statefulSetServiceNameMissing(resource K8sResource) (bool, string) {
+	if resource.Kind != "StatefulSet" {
+		return false, ""
+	}
+	return getStringValue(resource.Spec, "serviceName") == "", ""
+}
+
+// volumeClaimTemplateStorageClassMissing flags StatefulSet volumeClaimTemplates
+// that request storage without a storageClassName; on clusters without a
+// default StorageClass such claims stay Pending
+func volumeClaimTemplateStorageClassMissing(resource K8sResource) (bool, string) {
+	if resource.Kind != "StatefulSet" {
+		return false, ""
+	}
+	for _, claim := range parsePersistentVolumeClaims(resource) {
+		if claim.StorageRequest != "" && claim.StorageClass == "" {
+			return true, fmt.Sprintf("volumeClaimTemplate '%s'", claim.Name)
+		}
+	}
+	return false, ""
+}
+
+// defaultRevisionHistoryLimit is the revisionHistoryLimit Kubernetes applies when it is unset
+const defaultRevisionHistoryLimit = 10
+
+// revisionHistoryExceeds flags Deployments, StatefulSets, and DaemonSets that
+// keep more than maximum old revisions; an unset limit counts as the default
+func revisionHistoryExceeds(resource K8sResource, maximum int) (bool, string) {
+	switch resource.Kind {
+	case "Deployment", "StatefulSet", "DaemonSet":
+	default:
+		return false, ""
+	}
+	limit, ok := getIntValue(resource.Spec, "revisionHistoryLimit")
+	if !ok {
+		return defaultRevisionHistoryLimit > maximum, fmt.Sprintf("unset (defaults to %d)", defaultRevisionHistoryLimit)
+	}
+	return limit > maximum, strconv.Itoa(limit)
+}
+
+// daemonSetUpdateStrategy returns a DaemonSet's spec.updateStrategy, and false for other kinds
+func daemonSetUpdateStrategy(resource K8sResource) (map[string]interface{}, bool) {
+	if resource.Kind != "DaemonSet" {
+		return nil, false
+	}
+	strategy, _ := resource.Spec["updateStrategy"].(map[string]interface{})
+	return strategy, true
+}
+
+// daemonSetOnDeleteStrategy flags DaemonSets with updateStrategy OnDelete, which
+// only replaces pods when they are deleted by hand, so updates never roll out
+func daemonSetOnDeleteStrategy(resource K8sResource) (bool, string) {
+	strategy, ok := daemonSetUpdateStrategy(resource)
+	if !ok {
+		return false, ""
+	}
+	return getStringValue(strategy, "type") == "OnDelete", ""
+}
+
+// daemonSetMaxUnavailableExceeds flags rolling DaemonSet updates whose
+// maxUnavailable percentage is above maximum. Absolute counts are skipped, since
+// the number of nodes they are a share of is unknown until the pods schedule
+func daemonSetMaxUnavailableExceeds(resource K8sResource, maximum int) (bool, string) {
+	strategy, ok := daemonSetUpdateStrategy(resource)
+	if !ok || getStringValue(strategy, "type") == "OnDelete" {
+		return false, ""
+	}
+	rollingUpdate, _ := strategy["rollingUpdate"].(map[string]interface{})
+	value := getStringValue(rollingUpdate, "maxUnavailable")
+	percent, isPercent := strings.CutSuffix(value, "%")
+	if !isPercent {
+		return false, ""
+	}
+	n, err := strconv.Atoi(percent)
+	if err != nil || n <= maximum {
+		return false, ""
+	}
+	return true, value
+}
+
+// workloadSelector returns the spec.selector of a workload whose pod template
+// labels it must match, and nil for other kinds or when the selector is absent
+func workloadSelector(resource K8sResource) *LabelSelector {
+	switch resource.Kind {
+	case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Job":
+	default:
+		return nil
+	}
+	selectorMap, _ := resource.Spec["selector"].(map[string]interface{})
+	return parseLabelSelector(selectorMap)
+}
+
+// selectorNotMatchingTemplate flags workloads whose selector matchLabels are not
+// all present in the pod template labels, which the API server rejects
+// The details list each missing key=value and what the template has instead
+func selectorNotMatchingTemplate(resource K8sResource, specPaths map[string]string) (bool, string) {
+	selector := workloadSelector(resource)
+	if selector == nil {
+		return false, ""
+	}
+
+	labels := podTemplateLabels(resource, specPaths)
+	var missing []string
+	for key, value := range selector.MatchLabels {
+		actual, ok := labels[key]
+		switch {
+		case !ok:
+			missing = append(missing, fmt.Sprintf("%s=%s (template has no %s label)", key, value, key))
+		case actual != value:
+			missing = append(missing, fmt.Sprintf("%s=%s (template has %s=%s)", key, value, key, actual))
+		}
+	}
+	sort.Strings(missing)
+	return len(missing) > 0, strings.Join(missing, ", ")
+}
+
+// selectorMatchExpressionsSet flags workload selectors that use matchExpressions,
+// which selector_not_matching_template does not verify against the template
+// The details list the expression keys
+func selectorMatchExpressionsSet(resource K8sResource) (bool, string) {
+	selector := workloadSelector(resource)
+	if selector == nil || len(selector.MatchExpressions) == 0 {
+		return false, ""
+	}
+	var keys []string
+	for _, requirement := range selector.MatchExpressions {
+		keys = append(keys, fmt.Sprintf("%s %s", requirement.Key, requirement.Operator))
+	}
+	return true, strings.Join(keys, ", ")
+}
+
+// imageChangeTriggers maps each container an OpenShift DeploymentConfig's
+// ImageChange triggers update to the ImageStreamTag it follows
+func imageChangeTriggers(resource K8sResource) map[string]string {
+	if resource.Kind != "DeploymentConfig" {
+		return nil
+	}
+
+	tags := make(map[string]string)
+	triggers, _ := resource.Spec["triggers"].([]interface{})
+	for _, t := range triggers {
+		trigger, _ := t.(map[string]interface{})
+		params, ok := trigger["imageChangeParams"].(map[string]interface{})
+		if getStringValue(trigger, "type") != "ImageChange" || !ok {
+			continue
+		}
+		from, _ := params["from"].(map[string]interface{})
+		if getStringValue(from, "kind") != "ImageStreamTag" || getStringValue(from, "name") == "" {
+			continue
+		}
+		for _, name := range getStringList(params, "containerNames") {
+			tags[name] = getStringValue(from, "name")
+		}
+	}
+	return tags
+}
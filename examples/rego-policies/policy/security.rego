@@ -0,0 +1,16 @@
+package kubernetes.security
+
+import rego.v1
+
+deny contains msg if {
+	input.kind == "Deployment"
+	some container in input.spec.template.spec.containers
+	container.securityContext.privileged
+	msg := sprintf("container '%s' must not run privileged", [container.name])
+}
+
+warn contains {"msg": msg} if {
+	input.kind == "Deployment"
+	not input.metadata.labels.team
+	msg := sprintf("%s '%s' has no team label", [input.kind, input.metadata.name])
+}
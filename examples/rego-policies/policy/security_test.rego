@@ -0,0 +1,13 @@
+package kubernetes.security_test
+
+import rego.v1
+
+import data.kubernetes.security
+
+test_privileged_denied if {
+	security.deny with input as {
+		"kind": "Deployment",
+		"metadata": {"name": "api"},
+		"spec": {"template": {"spec": {"containers": [{"name": "api", "securityContext": {"privileged": true}}]}}},
+	}
+}
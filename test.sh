@@ -22,6 +22,39 @@ required_files=(
     "cmd/kubecheck/main.go"
     "cmd/kubecheck/parser.go"
     "cmd/kubecheck/helm.go"
+    "cmd/kubecheck/image.go"
+    "cmd/kubecheck/apiversions.go"
+    "cmd/kubecheck/bundle.go"
+    "cmd/kubecheck/selector.go"
+    "cmd/kubecheck/quantity.go"
+    "cmd/kubecheck/rbac.go"
+    "cmd/kubecheck/network.go"
+    "cmd/kubecheck/certificates.go"
+    "cmd/kubecheck/configmap.go"
+    "cmd/kubecheck/autoscaling.go"
+    "cmd/kubecheck/storage.go"
+    "cmd/kubecheck/cron.go"
+    "cmd/kubecheck/profiles.go"
+    "cmd/kubecheck/workloads.go"
+    "cmd/kubecheck/podspec.go"
+    "cmd/kubecheck/references.go"
+    "cmd/kubecheck/schema.go"
+    "cmd/kubecheck/crd.go"
+    "cmd/kubecheck/kubeschema.go"
+    "cmd/kubecheck/metadata.go"
+    "cmd/kubecheck/gateway.go"
+    "cmd/kubecheck/rulelist.go"
+    "cmd/kubecheck/suppressions.go"
+    "cmd/kubecheck/conditions.go"
+    "cmd/kubecheck/when.go"
+    "cmd/kubecheck/cel.go"
+    "cmd/kubecheck/rego.go"
+    "cmd/kubecheck/fieldpath.go"
+    "cmd/kubecheck/tags.go"
+    "cmd/kubecheck/configcheck.go"
+    "cmd/kubecheck/remoteconfig.go"
+    "cmd/kubecheck/exitcodes.go"
+    "cmd/kubecheck/configvalidate.go"
     "cmd/kubecheck/reporter.go"
     "cmd/kubecheck/config.go"
     "cmd/kubecheck/rule-engine.go"
@@ -78,6 +111,65 @@ if [ "$docs_exist" = true ]; then
     echo -e "${GREEN}✓${NC}"
 fi
 
+# Test 4: Pod Security Standards conformance fixtures
+echo -n "Test 4: PSS profile conformance... "
+tmpdir=$(mktemp -d)
+(cd cmd/kubecheck && go build -o "$tmpdir/kubecheck" .)
+printf 'rules: []\n' > "$tmpdir/empty.yaml"
+
+conformant=true
+for profile in baseline restricted; do
+    for expected in pass fail; do
+        for fixture in examples/pss/$profile/$expected/*.yaml; do
+            set +e
+            "$tmpdir/kubecheck" --config "$tmpdir/empty.yaml" --profile "pss-$profile" "$fixture" > /dev/null 2>&1
+            code=$?
+            set -e
+            if { [ "$expected" = pass ] && [ $code -ne 0 ]; } || { [ "$expected" = fail ] && [ $code -ne 2 ]; }; then
+                [ "$conformant" = true ] && echo -e "${RED}✗${NC}"
+                echo "  pss-$profile should $expected $fixture (exit $code)"
+                conformant=false
+            fi
+        done
+    done
+done
+
+if [ "$conformant" = true ]; then
+    echo -e "${GREEN}✓${NC}"
+else
+    rm -rf "$tmpdir"
+    exit 1
+fi
+
+# Test 5: example configs pass validation and their rule tests, and the
+# invalid one is rejected
+echo -n "Test 5: Config validation... "
+configs_valid=true
+for cfg in kubecheck.yaml examples/*/kubecheck.yaml examples/extends/app.yaml examples/config-fragments/kubecheck.d examples/config-fragments/late-extends.d; do
+    # Rego policies are only compiled when opa is installed
+    if [ "$cfg" = examples/rego-policies/kubecheck.yaml ] && ! command -v opa > /dev/null; then
+        continue
+    fi
+    expected=0
+    [ "$cfg" = examples/invalid-config/kubecheck.yaml ] && expected=2
+    set +e
+    "$tmpdir/kubecheck" config validate "$cfg" > /dev/null 2>&1
+    code=$?
+    set -e
+    if [ $code -ne $expected ]; then
+        [ "$configs_valid" = true ] && echo -e "${RED}✗${NC}"
+        echo "  $cfg exited $code, expected $expected"
+        configs_valid=false
+    fi
+done
+rm -rf "$tmpdir"
+
+if [ "$configs_valid" = true ]; then
+    echo -e "${GREEN}✓${NC}"
+else
+    exit 1
+fi
+
 echo ""
 echo -e "${GREEN}All tests passed!${NC}"
 echo ""